@@ -0,0 +1,249 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+const (
+	errUnableToGetActionsPublicKey = Error("unable to get actions secrets public key")
+	errUnableToEncryptActionsValue = Error("unable to encrypt actions secret value")
+	errUnableToGetActionsSecret    = Error("unable to get actions secret")
+	errUnableToPutActionsSecret    = Error("unable to create or update actions secret")
+	errUnableToDeleteActionsSecret = Error("unable to delete actions secret")
+)
+
+// Visibilities of organization Actions secrets.
+const (
+	actionsSecretVisibilityAll      = "all"
+	actionsSecretVisibilityPrivate  = "private"
+	actionsSecretVisibilitySelected = "selected"
+)
+
+// actionsSecretTarget identifies an Actions secret of an organization, or of
+// a repository or one of its environments.
+type actionsSecretTarget struct {
+	OrgName     string
+	Repository  string `json:",omitempty"`
+	Environment string `json:",omitempty"`
+	SecretName  string
+}
+
+// Model the public key that Actions secret values are encrypted with.
+type actionsPublicKey struct {
+	KeyID string `json:"key_id"`
+	Key   string `json:"key"`
+}
+
+// Model the parts of an Actions secret that we care about. GitHub never
+// returns secret values.
+type actionsSecret struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"name"`
+}
+
+// Model an Actions secret as created or updated.
+type actionsSecretValue struct {
+	EncryptedValue        string `json:"encrypted_value"`
+	KeyID                 string `json:"key_id"`
+	Visibility            string `json:"visibility,omitempty"`
+	SelectedRepositoryIDs []int  `json:"selected_repository_ids,omitempty"`
+}
+
+// secretsPath returns the API path of the Actions secrets of the target.
+func (t *actionsSecretTarget) secretsPath() string {
+	switch {
+	case t.Environment != "":
+		return fmt.Sprintf("%s/environments/%s/secrets",
+			repositoryPath(t.OrgName, t.Repository), url.PathEscape(t.Environment))
+	case t.Repository != "":
+		return repositoryPath(t.OrgName, t.Repository) + "/actions/secrets"
+	default:
+		return fmt.Sprintf("orgs/%s/actions/secrets", url.PathEscape(t.OrgName))
+	}
+}
+
+// secretPath returns the API path of the Actions secret of the target.
+func (t *actionsSecretTarget) secretPath() string {
+	return fmt.Sprintf("%s/%s", t.secretsPath(), url.PathEscape(t.SecretName))
+}
+
+// tokenRequest returns the token request of the internal tokens used to
+// manage the Actions secrets of the target as the installation.
+func (t *actionsSecretTarget) tokenRequest(installationID int) *tokenRequest {
+	tokReq := &tokenRequest{InstallationID: installationID}
+
+	switch {
+	case t.Environment != "":
+		tokReq.Repositories = []string{t.Repository}
+		tokReq.Permissions = map[string]string{"environments": "write"}
+	case t.Repository != "":
+		tokReq.Repositories = []string{t.Repository}
+		tokReq.Permissions = map[string]string{"secrets": "write"}
+	default:
+		tokReq.Permissions = map[string]string{"organization_secrets": "write"}
+	}
+
+	return tokReq
+}
+
+// String returns a human readable form of the target.
+func (t *actionsSecretTarget) String() string {
+	switch {
+	case t.Environment != "":
+		return fmt.Sprintf("%s/%s (%s):%s", t.OrgName, t.Repository, t.Environment, t.SecretName)
+	case t.Repository != "":
+		return fmt.Sprintf("%s/%s:%s", t.OrgName, t.Repository, t.SecretName)
+	default:
+		return fmt.Sprintf("%s:%s", t.OrgName, t.SecretName)
+	}
+}
+
+// encryptActionsValue encrypts the value with the public key as a libsodium
+// sealed box, as expected by GitHub, returning it base64 encoded.
+func encryptActionsValue(publicKey *actionsPublicKey, value string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(publicKey.Key)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", errUnableToEncryptActionsValue, err)
+	}
+
+	if len(key) != 32 {
+		return "", fmt.Errorf("%s: public key is %d bytes", errUnableToEncryptActionsValue, len(key))
+	}
+
+	var recipient [32]byte
+	copy(recipient[:], key)
+
+	sealed, err := box.SealAnonymous(nil, []byte(value), &recipient, rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", errUnableToEncryptActionsValue, err)
+	}
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// PushActionsSecret creates or updates the Actions secret of the target with
+// the value, as the installation, and returns the secret as then recorded by
+// GitHub. Unless forced, the secret is only pushed should GitHub have recorded
+// an update of it since updatedAt, i.e. should it have drifted. All is done
+// with a single internal token. The visibility, and selected repositories,
+// only apply to organization secrets.
+func (c *Client) PushActionsSecret(
+	ctx context.Context,
+	installationID int,
+	target *actionsSecretTarget,
+	value, visibility string,
+	selectedRepositoryIDs []int,
+	force bool,
+	updatedAt time.Time,
+) (secret *actionsSecret, pushed bool, err error) {
+	err = c.withInstallation(ctx, target.tokenRequest(installationID),
+		func(s *installationSession) error {
+			if !force {
+				current, err := s.actionsSecret(ctx, target)
+				if err != nil {
+					return err
+				}
+
+				if current != nil && current.UpdatedAt.Equal(updatedAt) {
+					return nil
+				}
+			}
+
+			pushed = true
+
+			if err := s.putActionsSecret(ctx, target, value, visibility, selectedRepositoryIDs); err != nil {
+				return err
+			}
+
+			secret, err = s.actionsSecret(ctx, target)
+
+			return err
+		},
+	)
+
+	return secret, pushed, err
+}
+
+// actionsSecret returns the Actions secret of the target, or nil if it does
+// not exist.
+func (s *installationSession) actionsSecret(ctx context.Context, target *actionsSecretTarget) (*actionsSecret, error) {
+	var secret actionsSecret
+
+	err := s.request(ctx, http.MethodGet, target.secretPath(), nil, &secret)
+
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToGetActionsSecret, err)
+	}
+
+	return &secret, nil
+}
+
+// putActionsSecret creates or updates the Actions secret of the target with
+// the value, encrypted with the public key of the target.
+func (s *installationSession) putActionsSecret(
+	ctx context.Context,
+	target *actionsSecretTarget,
+	value, visibility string,
+	selectedRepositoryIDs []int,
+) error {
+	var publicKey actionsPublicKey
+
+	if err := s.request(ctx, http.MethodGet, target.secretsPath()+"/public-key", nil, &publicKey); err != nil {
+		return fmt.Errorf("%s: %w", errUnableToGetActionsPublicKey, err)
+	}
+
+	encrypted, err := encryptActionsValue(&publicKey, value)
+	if err != nil {
+		return err
+	}
+
+	secret := &actionsSecretValue{EncryptedValue: encrypted, KeyID: publicKey.KeyID}
+
+	if target.Repository == "" {
+		secret.Visibility = visibility
+		secret.SelectedRepositoryIDs = selectedRepositoryIDs
+	}
+
+	if err = s.request(ctx, http.MethodPut, target.secretPath(), secret, nil); err != nil {
+		return fmt.Errorf("%s: %w", errUnableToPutActionsSecret, err)
+	}
+
+	return nil
+}
+
+// DeleteActionsSecret deletes the Actions secret of the target, as the
+// installation. Secrets that are not found are considered deleted.
+func (c *Client) DeleteActionsSecret(
+	ctx context.Context,
+	installationID int,
+	target *actionsSecretTarget,
+) error {
+	err := c.installationRequest(ctx, target.tokenRequest(installationID),
+		http.MethodDelete, target.secretPath(), nil, nil,
+	)
+
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", errUnableToDeleteActionsSecret, err)
+	}
+
+	return nil
+}
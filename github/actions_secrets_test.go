@@ -0,0 +1,104 @@
+package github
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"golang.org/x/crypto/nacl/box"
+	"gotest.tools/assert"
+)
+
+func TestActionsSecretTarget(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		target      *actionsSecretTarget
+		name        string
+		path        string
+		str         string
+		constraints tokenConstraints
+	}{
+		{
+			name:   "Organization",
+			target: &actionsSecretTarget{OrgName: "acme", SecretName: "NPM_TOKEN"},
+			path:   "orgs/acme/actions/secrets",
+			str:    "acme:NPM_TOKEN",
+			constraints: tokenConstraints{
+				Permissions: map[string]string{"organization_secrets": "write"},
+			},
+		},
+		{
+			name:   "Repository",
+			target: &actionsSecretTarget{OrgName: "acme", Repository: "website", SecretName: "NPM_TOKEN"},
+			path:   "repos/acme/website/actions/secrets",
+			str:    "acme/website:NPM_TOKEN",
+			constraints: tokenConstraints{
+				Repositories: []string{"website"},
+				Permissions:  map[string]string{"secrets": "write"},
+			},
+		},
+		{
+			name:   "Escaped",
+			target: &actionsSecretTarget{OrgName: "ac/me", Repository: "../web", SecretName: "NPM_TOKEN"},
+			path:   "repos/ac%2Fme/..%2Fweb/actions/secrets",
+			str:    "ac/me/../web:NPM_TOKEN",
+			constraints: tokenConstraints{
+				Repositories: []string{"../web"},
+				Permissions:  map[string]string{"secrets": "write"},
+			},
+		},
+		{
+			name: "Environment",
+			target: &actionsSecretTarget{
+				OrgName: "acme", Repository: "website", Environment: "prod eu", SecretName: "NPM_TOKEN",
+			},
+			path: "repos/acme/website/environments/prod%20eu/secrets",
+			str:  "acme/website (prod eu):NPM_TOKEN",
+			constraints: tokenConstraints{
+				Repositories: []string{"website"},
+				Permissions:  map[string]string{"environments": "write"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.target.secretsPath(), tc.path)
+			assert.Equal(t, tc.target.secretPath(), tc.path+"/"+tc.target.SecretName)
+			assert.Equal(t, tc.target.String(), tc.str)
+
+			tokReq := tc.target.tokenRequest(testInsID1)
+			assert.Equal(t, tokReq.InstallationID, testInsID1)
+			assert.DeepEqual(t, tokReq.tokenConstraints, tc.constraints)
+		})
+	}
+}
+
+func TestEncryptActionsValue(t *testing.T) {
+	t.Parallel()
+
+	pub, prv, err := box.GenerateKey(rand.Reader)
+	assert.NilError(t, err)
+
+	encrypted, err := encryptActionsValue(&actionsPublicKey{
+		KeyID: "1",
+		Key:   base64.StdEncoding.EncodeToString(pub[:]),
+	}, "hunter2")
+	assert.NilError(t, err)
+
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	assert.NilError(t, err)
+
+	value, ok := box.OpenAnonymous(nil, sealed, pub, prv)
+	assert.Assert(t, ok)
+	assert.Equal(t, string(value), "hunter2")
+
+	_, err = encryptActionsValue(&actionsPublicKey{Key: "not base64!"}, "hunter2")
+	assert.ErrorContains(t, err, errUnableToEncryptActionsValue.Error())
+
+	_, err = encryptActionsValue(&actionsPublicKey{Key: base64.StdEncoding.EncodeToString([]byte("short"))}, "hunter2")
+	assert.ErrorContains(t, err, "public key is 5 bytes")
+}
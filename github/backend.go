@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
 	"sync"
@@ -25,22 +26,30 @@ const (
 	errConfRetrieval    = Error("failed to get configuration from storage")
 	errConfUnmarshal    = Error("failed to unmarshal configuration from JSON")
 	errClientCreate     = Error("failed to create an authenticated GitHub client")
+	errAppNotConfigured = Error("app not configured")
 )
 
 type backend struct {
 	*framework.Backend
 
-	// The actual GitHub client and a lock used for controlling access allowing
-	// for safe rotation if the mounted configuration changes.
-	client     *Client
+	// The actual GitHub clients, keyed by app name, and a lock used for
+	// controlling access allowing for safe rotation if the mounted
+	// configuration changes. The default app is keyed by the empty string.
+	clients    map[string]*Client
 	clientLock sync.RWMutex
 
 	permissionsetLock sync.Mutex
+	policyLock        sync.Mutex
+	deployKeyLock     sync.Mutex
+	runnerLock        sync.Mutex
+	jitLock           sync.Mutex
+	collaboratorLock  sync.Mutex
+	syncLock          sync.Mutex
 }
 
 // Factory creates a configured logical.Backend for the GitHub plugin.
 func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
-	b := new(backend)
+	b := &backend{clients: make(map[string]*Client)}
 
 	b.Backend = &framework.Backend{
 		Help:        strings.TrimSpace(backendHelp),
@@ -48,16 +57,25 @@ func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend,
 		PathsSpecial: &logical.Paths{
 			Unauthenticated: []string{pathPatternInfo, pathPatternMetrics},
 		},
-		Paths: []*framework.Path{
+		Paths: framework.PathAppend([]*framework.Path{
 			b.pathInfo(),
+			b.pathInstallationsRefresh(),
 			b.pathInstallations(),
+			b.pathInstallation(),
+			b.pathInstallationRepositories(),
 			b.pathMetrics(),
+			b.pathRateLimit(),
 			b.pathConfig(),
+			b.pathConfigVerify(),
+			b.pathConfigApps(),
+			b.pathConfigAppsList(),
 			b.pathToken(),
 			b.pathTokenPermissionSet(),
 			b.pathPermissionSet(),
 			b.pathPermissionSetList(),
-		},
+			b.pathPermissionsCatalog(),
+		}, b.pathConfigKeys(), b.pathConfigPolicy(), b.pathDeployKey(), b.pathRunner(), b.pathJIT(),
+			b.pathCollaborator(), b.pathSync()),
 		Secrets: []*framework.Secret{{
 			Type: backendSecretType,
 			Fields: map[string]*framework.FieldSchema{
@@ -70,8 +88,9 @@ func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend,
 			Revoke: b.Revoke,
 			// NOTE: Unfortunately GitHub has no mechanism for renewing tokens.
 			// Renew:
-		}},
+		}, b.deployKeySecret(), b.teamMembershipSecret(), b.collaboratorSecret()},
 		Invalidate:     b.Invalidate,
+		PeriodicFunc:   b.periodicFunc,
 		RunningVersion: projectVersion,
 	}
 
@@ -92,43 +111,57 @@ func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend,
 // Invalidate resets the plugin. It is called when a key is updated via
 // replication.
 func (b *backend) Invalidate(_ context.Context, key string) {
-	if key == pathPatternConfig {
-		// Configuration has changed so reset the client.
-		b.clientLock.Lock()
-		b.client = nil
-		b.clientLock.Unlock()
+	app, ok := appFromConfigKey(key)
+	if !ok {
+		return
 	}
+
+	// Configuration has changed so reset the client.
+	b.clientLock.Lock()
+	delete(b.clients, app)
+	b.clientLock.Unlock()
 }
 
 // Config parses and returns the configuration data from the storage backend. An
 // empty config is returned in the case where there is no existing in storage.
 func (b *backend) Config(ctx context.Context, s logical.Storage) (*Config, error) {
+	c, _, err := b.AppConfig(ctx, s, "")
+
+	return c, err
+}
+
+// AppConfig parses and returns the configuration data of the named app from
+// the storage backend. An empty app name refers to the default app. The
+// returned boolean reports whether the configuration existed in storage; an
+// empty config is returned when it does not.
+func (b *backend) AppConfig(ctx context.Context, s logical.Storage, app string) (*Config, bool, error) {
 	c := NewConfig()
 
-	entry, err := s.Get(ctx, pathPatternConfig)
+	entry, err := s.Get(ctx, configKey(app))
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", errConfRetrieval, err)
+		return nil, false, fmt.Errorf("%s: %w", errConfRetrieval, err)
 	}
 
 	if entry == nil || len(entry.Value) == 0 {
-		return c, nil
+		return c, false, nil
 	}
 
 	if err = entry.DecodeJSON(&c); err != nil {
-		return nil, fmt.Errorf("%s: %w", errConfUnmarshal, err)
+		return nil, false, fmt.Errorf("%s: %w", errConfUnmarshal, err)
 	}
 
-	return c, nil
+	return c, true, nil
 }
 
-// Client returns a client for interfacing the configured GitHub App. Resets due
-// to configuration updates are safely handled. Users are expected to use the
+// Client returns a client for interfacing the configured GitHub App of the
+// given name, where an empty name refers to the default app. Resets due to
+// configuration updates are safely handled. Users are expected to use the
 // returned closer when finished.
-func (b *backend) Client(ctx context.Context, s logical.Storage) (*Client, func(), error) {
+func (b *backend) Client(ctx context.Context, s logical.Storage, app string) (*Client, func(), error) {
 	b.clientLock.RLock()
 
-	if b.client != nil {
-		return b.client, func() { b.clientLock.RUnlock() }, nil
+	if client, ok := b.clients[app]; ok {
+		return client, b.clientDone(ctx, s, app, client), nil
 	}
 
 	b.clientLock.RUnlock()
@@ -141,30 +174,61 @@ func (b *backend) Client(ctx context.Context, s logical.Storage) (*Client, func(
 	b.clientLock.Lock()
 
 	// Clear the client once more in case of earlier concurrent creation.
-	b.client = nil
+	delete(b.clients, app)
 
-	config, err := b.Config(ctx, s)
+	config, exists, err := b.AppConfig(ctx, s, app)
 	if err != nil {
 		b.clientLock.Unlock()
 
 		return nil, nil, err
 	}
 
-	client, err := NewClient(config)
+	if app != "" && !exists {
+		b.clientLock.Unlock()
+
+		return nil, nil, logical.CodedError(http.StatusBadRequest,
+			fmt.Sprintf("%s: %s", errAppNotConfigured, app))
+	}
+
+	client, err := NewClient(config, WithLogger(b.Logger()))
 	if err != nil {
 		b.clientLock.Unlock()
 
 		return nil, nil, fmt.Errorf("%s: %w", errClientCreate, err)
 	}
 
-	b.client = client
+	// Start from the persisted installations cache, e.g. after a restart.
+	if config.InstallationCacheStorage {
+		snapshot, err := loadInstallations(ctx, s, app)
+		if err != nil {
+			b.Logger().Warn("unable to load cached installations", "app", app, "err", err)
+		}
+
+		if snapshot != nil {
+			client.restoreInstallations(snapshot)
+		}
+	}
+
+	b.clients[app] = client
 
 	b.clientLock.Unlock()
 	b.Logger().Debug("created GitHub App installation client",
+		"app", app,
 		"base_url", config.BaseURL,
 		"app_id", strconv.Itoa(config.AppID),
 	)
 	b.clientLock.RLock()
 
-	return b.client, func() { b.clientLock.RUnlock() }, nil
+	return client, b.clientDone(ctx, s, app, client), nil
+}
+
+// clientDone returns the closer of a client returned by Client, which saves
+// the installations cache of the client to the storage of the request that
+// used it.
+func (b *backend) clientDone(ctx context.Context, s logical.Storage, app string, client *Client) func() {
+	return func() {
+		defer b.clientLock.RUnlock()
+
+		b.saveInstallations(ctx, s, app, client)
+	}
 }
@@ -2,7 +2,12 @@ package github
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
@@ -86,6 +91,86 @@ func testBackend(t *testing.T, fvs ...failVerb) (*backend, logical.Storage) {
 	return b.(*backend), config.StorageView
 }
 
+// testGitHubServer is a fake GitHub API server of the App installed on the
+// test-1 organization. It creates installation tokens, recording their
+// constraints, and serves the routes that tests register with handle. Requests
+// are served one at a time under mu, which tests hold to share state with their
+// handlers.
+type testGitHubServer struct {
+	*httptest.Server
+	mux    *http.ServeMux
+	tokens []tokenConstraints
+	mu     sync.Mutex
+}
+
+func newTestGitHubServer(t *testing.T) *testGitHubServer {
+	t.Helper()
+
+	ts := &testGitHubServer{mux: http.NewServeMux()}
+
+	ts.handle("GET /app/installations", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `[{"id":%d,"account":{"login":%q}}]`, testInsID1, testOrgName1)
+	})
+	ts.handle(fmt.Sprintf("POST /app/installations/%d/access_tokens", testInsID1),
+		func(w http.ResponseWriter, r *http.Request) {
+			var constraints tokenConstraints
+			assert.NilError(t, json.NewDecoder(r.Body).Decode(&constraints))
+			ts.tokens = append(ts.tokens, constraints)
+
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"token":"` + testToken + `"}`))
+		})
+	ts.handle("DELETE /installation/token", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ts.Server = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			ts.mu.Lock()
+			defer ts.mu.Unlock()
+
+			ts.mux.ServeHTTP(w, r)
+		}),
+	)
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+// handle serves requests matching the pattern with the handler.
+func (ts *testGitHubServer) handle(pattern string, handler http.HandlerFunc) {
+	ts.mux.HandleFunc(pattern, handler)
+}
+
+// tokenRequests returns the constraints of the installation tokens created.
+func (ts *testGitHubServer) tokenRequests() []tokenConstraints {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	return slices.Clone(ts.tokens)
+}
+
+// testGitHubBackend returns a backend configured against the server.
+func testGitHubBackend(t *testing.T, ts *testGitHubServer) (*backend, logical.Storage) {
+	t.Helper()
+
+	b, storage := testBackend(t)
+
+	_, err := b.HandleRequest(context.Background(), &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			keyAppID:   testAppID1,
+			keyPrvKey:  testPrvKeyValid,
+			keyBaseURL: ts.URL,
+		},
+	})
+	assert.NilError(t, err)
+
+	return b, storage
+}
+
 func TestBackend_Config(t *testing.T) {
 	t.Parallel()
 
@@ -175,13 +260,13 @@ func TestBackend_Client(t *testing.T) {
 		assert.Assert(t, entry != nil)
 		assert.NilError(t, storage.Put(ctx, entry))
 
-		_, closer1, err := b.Client(ctx, storage)
+		_, closer1, err := b.Client(ctx, storage, "")
 		assert.NilError(t, err)
 		defer closer1()
 
 		doneCh := make(chan struct{})
 		go func() {
-			_, closer2, err := b.Client(context.Background(), storage)
+			_, closer2, err := b.Client(context.Background(), storage, "")
 			assert.NilError(t, err)
 			defer closer2()
 			close(doneCh)
@@ -210,11 +295,11 @@ func TestBackend_Client(t *testing.T) {
 		assert.Assert(t, entry != nil)
 		assert.NilError(t, storage.Put(ctx, entry))
 
-		client1, closer1, err := b.Client(ctx, storage)
+		client1, closer1, err := b.Client(ctx, storage, "")
 		assert.NilError(t, err)
 		defer closer1()
 
-		client2, closer2, err := b.Client(ctx, storage)
+		client2, closer2, err := b.Client(ctx, storage, "")
 		assert.NilError(t, err)
 		defer closer2()
 
@@ -229,7 +314,7 @@ func TestBackend_Client(t *testing.T) {
 
 		b, storage := testBackend(t, failVerbRead)
 
-		client, _, err := b.Client(ctx, storage)
+		client, _, err := b.Client(ctx, storage, "")
 		assert.ErrorContains(t, err, errConfRetrieval.Error())
 		assert.Assert(t, is.Nil(client))
 	})
@@ -247,7 +332,7 @@ func TestBackend_Client(t *testing.T) {
 		assert.Assert(t, entry != nil)
 		assert.NilError(t, storage.Put(ctx, entry))
 
-		client, _, err := b.Client(ctx, storage)
+		client, _, err := b.Client(ctx, storage, "")
 		assert.ErrorContains(t, err, errClientCreate.Error())
 		assert.Assert(t, is.Nil(client))
 	})
@@ -4,15 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/bradleyfalzon/ghinstallation/v2"
+	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
@@ -28,8 +29,14 @@ const (
 	errUnableToDecodeAccessTokenRes   = Error("unable to decode access token response")
 	errUnableToDecodeInstallationsRes = Error("unable to decode installations list response")
 	errUnableToGetInstallations       = Error("unable to get installations")
+	errUnableToGetInstallation        = Error("unable to get installation")
+	errUnableToDecodeInstallationRes  = Error("unable to decode installation response")
+	errInstallationNotFound           = Error("installation not found")
 	errUnableToRevokeAccessToken      = Error("unable to revoke access token")
 	errAppNotInstalled                = Error("app not installed in GitHub organization")
+	errUnableToGetApp                 = Error("unable to get app")
+	errUnableToDecodeAppRes           = Error("unable to decode app response")
+	errTTLExceedsMaxTTL               = Error("ttl cannot exceed max_ttl")
 )
 
 // Client encapsulates an HTTP client for talking to the configured GitHub App.
@@ -39,65 +46,156 @@ type Client struct {
 	// RevocationURL is the access token revocation URL for this client.
 	revocationURL *url.URL
 
-	// revocationClient is an HTTP client used for unauthenticated GitHub App
-	// installation token revocation requests.
+	// repositoriesURL is the URL listing the repositories accessible to an
+	// installation for this client.
+	repositoriesURL *url.URL
+
+	// revocationClient is an HTTP client used for requests that carry their
+	// own credentials, such as GitHub App installation token revocations.
 	revocationClient *http.Client
 
 	// installationsClient is an HTTP client used for authenticated GitHub App
 	// installation token requests.
 	installationsClient *http.Client
 
+	// fallbackClient is an HTTP client authenticated with the fallback private
+	// key of a key rotation. It is nil unless a fallback key is configured.
+	fallbackClient *http.Client
+
+	// baseURL is the GitHub API base URL for this client.
+	baseURL *url.URL
+
+	// appURL is the URL of the authenticated GitHub App for this client.
+	appURL *url.URL
+
+	// rateLimitURL is the rate limit URL for this client.
+	rateLimitURL *url.URL
+
+	// rateLimits holds the latest rate limit state seen by this client.
+	rateLimits *rateLimits
+
+	// installations caches the installations of the App for this client.
+	installations *installationsCache
+
+	// tokens caches the tokens of cacheable token requests for this client.
+	tokens *tokenCache
+
 	// InstallationsURL is the installations operations URL for this client.
 	installationsURL *url.URL
 
 	// URL is the access token URL template for this client.
 	accessTokenURLTemplate string
+
+	// logger logs retries of failed requests.
+	logger hclog.Logger
+}
+
+// ClientOption customises a Client at construction.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	signer Signer
+	logger hclog.Logger
+}
+
+// WithSigner configures the client to sign GitHub App JWTs with the given
+// Signer instead of the one derived from its configuration.
+func WithSigner(signer Signer) ClientOption {
+	return func(o *clientOptions) {
+		o.signer = signer
+	}
+}
+
+// WithLogger configures the client to log to the given logger.
+func WithLogger(logger hclog.Logger) ClientOption {
+	return func(o *clientOptions) {
+		o.logger = logger
+	}
 }
 
 // NewClient returns a newly constructed client from the provided config and
 // with sensible default transport settings. It will error if it fails to
-// validate necessary configuration formats like URIs and PEM encoded private
-// keys.
-func NewClient(config *Config) (*Client, error) {
+// validate necessary configuration formats like URIs, PEM encoded private keys
+// and TLS settings.
+func NewClient(config *Config, opts ...ClientOption) (*Client, error) {
 	if config == nil {
 		return nil, errClientConfigNil
 	}
 
-	// A sensible request timeout.
-	reqTimeout := time.Millisecond * 10000
-
-	// Initialise a new transport instead of using Go's default. This transport
-	// has explicit timeouts and sensible defaults for max connections per host
-	// (i.e. their zero values—unlimited—since the plugin typically only deals
-	// with one host).
-	transport := &http.Transport{
-		DialContext: (&net.Dialer{
-			Timeout: reqTimeout / 4,
-		}).DialContext,
-		TLSHandshakeTimeout: reqTimeout / 4,
-		Proxy:               http.ProxyFromEnvironment,
+	options := clientOptions{logger: hclog.NewNullLogger()}
+	for _, opt := range opts {
+		opt(&options)
 	}
 
-	// Create an GitHub App installation authenticated clone of transport.
-	authenticatedTransport, err := ghinstallation.NewAppsTransport(
-		transport.Clone(),
-		int64(config.AppID),
-		[]byte(config.PrvKey),
-	)
+	reqTimeout := config.requestTimeout()
+
+	transport, err := config.transport()
 	if err != nil {
 		return nil, err
 	}
 
+	// Sign JWTs with a Vault Transit key if configured, otherwise with the
+	// configured private key.
+	signer := options.signer
+	if signer == nil {
+		if signer, err = newSigner(config, reqTimeout); err != nil {
+			return nil, err
+		}
+	}
+
+	// Create an GitHub App installation authenticated clone of transport.
+	authenticatedTransport := &appsTransport{
+		tr:     transport.Clone(),
+		signer: signer,
+		appID:  int64(config.AppID),
+	}
+
 	baseURL, err := url.ParseRequestURI(config.BaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", errParsingBaseURL, err)
 	}
 
+	// During a key rotation, authenticate with the previous private key should
+	// the active one be rejected.
+	var fallbackClient *http.Client
+
+	if config.FallbackPrvKey != "" && config.TransitKey == "" && options.signer == nil {
+		fallbackSigner, err := newRSASigner(config.FallbackPrvKey)
+		if err != nil {
+			return nil, err
+		}
+
+		fallbackClient = &http.Client{
+			Timeout: reqTimeout,
+			Transport: &appsTransport{
+				tr:     transport.Clone(),
+				signer: fallbackSigner,
+				appID:  int64(config.AppID),
+			},
+		}
+	}
+
 	installationsURL := baseURL.ResolveReference(&url.URL{Path: "app/installations"})
 
+	tokens, err := newTokenCache()
+	if err != nil {
+		return nil, err
+	}
+
 	return &Client{
-		Config:        config,
-		revocationURL: baseURL.ResolveReference(&url.URL{Path: "installation/token"}),
+		Config:         config,
+		baseURL:        baseURL,
+		fallbackClient: fallbackClient,
+		appURL:         baseURL.ResolveReference(&url.URL{Path: "app"}),
+		rateLimitURL:   baseURL.ResolveReference(&url.URL{Path: "rate_limit"}),
+		rateLimits:     &rateLimits{limits: make(map[rateLimitKey]rateLimit)},
+		installations:  &installationsCache{},
+		tokens:         tokens,
+		revocationURL:  baseURL.ResolveReference(&url.URL{Path: "installation/token"}),
+		repositoriesURL: baseURL.ResolveReference(&url.URL{
+			Path:     "installation/repositories",
+			RawQuery: url.Values{"per_page": {repositoriesPageSize}}.Encode(),
+		}),
 		revocationClient: &http.Client{
 			Timeout:   reqTimeout,
 			Transport: transport,
@@ -111,6 +209,7 @@ func NewClient(config *Config) (*Client, error) {
 			"%s/%%v/access_tokens",
 			strings.TrimSuffix(installationsURL.String(), "/"),
 		),
+		logger: options.logger,
 	}, nil
 }
 
@@ -123,11 +222,54 @@ type tokenRequest struct {
 	// [1]: https://git.io/JsQ7n
 	OrgName string `json:"org_name"`
 
+	// App is the name of the configured GitHub App the token is requested
+	// from. An empty name refers to the default app.
+	//
+	// NOTE: App is not part of the GitHub access tokens API payload either.
+	App string `json:"app,omitempty"`
+
 	// tokenRequest embeds tokenConstraints.
 	tokenConstraints
 
 	// InstallationID is the installation identifier of the GitHub App.
 	InstallationID int `json:"installation_id"`
+
+	// TTL shortens the Vault lease of the token, which is otherwise aligned
+	// to the expiry of the token. The token is revoked when its lease expires.
+	//
+	// NOTE: TTL and MaxTTL are not part of the GitHub access tokens API
+	// payload either.
+	TTL time.Duration `json:"ttl,omitempty"`
+
+	// MaxTTL caps TTL and the Vault lease of the token.
+	MaxTTL time.Duration `json:"max_ttl,omitempty"`
+}
+
+// validateTTL checks that the TTL of the token request does not exceed its
+// maximum TTL.
+func (tokReq *tokenRequest) validateTTL() error {
+	if tokReq.MaxTTL > 0 && tokReq.TTL > tokReq.MaxTTL {
+		return fmt.Errorf("%w: %s > %s", errTTLExceedsMaxTTL, tokReq.TTL, tokReq.MaxTTL)
+	}
+
+	return nil
+}
+
+// leaseOptions returns the options of the Vault lease of a token that expires
+// after the given duration.
+func (tokReq *tokenRequest) leaseOptions(expiresIn time.Duration) logical.LeaseOptions {
+	opts := logical.LeaseOptions{TTL: expiresIn}
+
+	if tokReq.TTL > 0 {
+		opts.TTL = min(opts.TTL, tokReq.TTL)
+	}
+
+	if tokReq.MaxTTL > 0 {
+		opts.TTL = min(opts.TTL, tokReq.MaxTTL)
+		opts.MaxTTL = min(expiresIn, tokReq.MaxTTL)
+	}
+
+	return opts
 }
 
 // tokenConstraints allows for constraining the access scope of a token to
@@ -171,6 +313,17 @@ func (c *Client) Token(ctx context.Context, tokReq *tokenRequest) (*logical.Resp
 		}
 	}
 
+	// Refuse permissions that GitHub would, explaining why.
+	if c.CheckGrantedPermissions && len(tokReq.Permissions) > 0 {
+		if err := c.checkGrantedPermissions(ctx, tokReq); err != nil {
+			if errors.Is(err, errPermissionsNotGranted) {
+				return nil, logical.CodedError(http.StatusBadRequest, err.Error())
+			}
+
+			return nil, err
+		}
+	}
+
 	return c.token(ctx, tokReq)
 }
 
@@ -199,7 +352,7 @@ func (c *Client) token(ctx context.Context, tokReq *tokenRequest) (*logical.Resp
 	}
 
 	// Perform the request, re-using the shared transport.
-	res, err := c.installationsClient.Do(req)
+	res, err := c.do(req, c.doApp)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", errUnableToCreateAccessToken, err)
 	}
@@ -240,6 +393,12 @@ func (c *Client) token(ctx context.Context, tokReq *tokenRequest) (*logical.Resp
 		}
 	}
 
+	return newTokenResponse(tokReq, resData), nil
+}
+
+// newTokenResponse returns the response to the token request of the given
+// GitHub access token response data.
+func newTokenResponse(tokReq *tokenRequest, resData map[string]any) *logical.Response {
 	// Enrich the response with what we know about the installation.
 	tokRes := &logical.Response{Data: resData}
 	tokRes.Data["installation_id"] = tokReq.InstallationID
@@ -248,26 +407,103 @@ func (c *Client) token(ctx context.Context, tokReq *tokenRequest) (*logical.Resp
 		tokRes.Data["org_name"] = tokReq.OrgName
 	}
 
+	if tokReq.App != "" {
+		tokRes.Data["app"] = tokReq.App
+	}
+
 	// As per the issue request in https://git.io/JUhRk, return a Vault "lease"
-	// aligned to the GitHub token's `expires_at` field.
+	// aligned to the GitHub token's `expires_at` field, unless shortened by
+	// the token request. The token is revoked when the lease expires.
 	if expiresAt, ok := resData["expires_at"]; ok {
 		var expiresAtStr string
 
 		if expiresAtStr, ok = expiresAt.(string); ok {
-			var expiresAtTime time.Time
-
-			if expiresAtTime, err = time.Parse(time.RFC3339, expiresAtStr); err == nil {
+			if expiresAtTime, err := time.Parse(time.RFC3339, expiresAtStr); err == nil {
 				tokRes.Secret = &logical.Secret{
-					InternalData: map[string]any{"secret_type": backendSecretType},
-					LeaseOptions: logical.LeaseOptions{
-						TTL: time.Until(expiresAtTime),
+					InternalData: map[string]any{
+						"secret_type":     backendSecretType,
+						"app":             tokReq.App,
+						keyInstallationID: strconv.Itoa(tokReq.InstallationID),
 					},
+					LeaseOptions: tokReq.leaseOptions(time.Until(expiresAtTime)),
 				}
 			}
 		}
 	}
 
-	return tokRes, nil
+	return tokRes
+}
+
+// doApp performs a request authenticated as the GitHub App, recording the rate
+// limit of the App. Should GitHub reject the JWT while a key rotation is
+// underway, the request is retried once with the fallback private key.
+func (c *Client) doApp(req *http.Request) (*http.Response, error) {
+	res, err := c.doAppOnce(req)
+	if err == nil {
+		c.recordRateLimit(0, res.Header)
+	}
+
+	return res, err
+}
+
+// doAppOnce performs a request authenticated as the GitHub App, falling back
+// to the fallback private key once as described by doApp.
+func (c *Client) doAppOnce(req *http.Request) (*http.Response, error) {
+	res, err := c.installationsClient.Do(req)
+	if err != nil || c.fallbackClient == nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+
+	// Rewind the request body, if any, for the second attempt.
+	retry := req.Clone(req.Context())
+
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return res, nil //nolint:nilerr // Fall back to the original response.
+		}
+	}
+
+	res.Body.Close() //nolint:errcheck,gosec
+
+	return c.fallbackClient.Do(retry)
+}
+
+// App retrieves the GitHub App that the client is authenticated as. This is a
+// cheap way of verifying that the App ID and private key are accepted by the
+// configured GitHub.
+func (c *Client) App(ctx context.Context) (*app, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.appURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToGetApp, err)
+	}
+
+	req.Header.Set("User-Agent", projectName)
+
+	res, err := c.do(req, c.doApp)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToGetApp, err)
+	}
+
+	defer res.Body.Close() //nolint:errcheck
+
+	if statusCode(res.StatusCode).Unsuccessful() {
+		var bodyBytes []byte
+
+		if bodyBytes, err = io.ReadAll(res.Body); err != nil {
+			return nil, fmt.Errorf("%s: %w", errUnableToGetApp, err)
+		}
+
+		bodyErr := fmt.Errorf("%s: %s", res.Status, string(bodyBytes))
+
+		return nil, fmt.Errorf("%s: %w", errUnableToGetApp, bodyErr)
+	}
+
+	var appResult app
+	if err = json.NewDecoder(res.Body).Decode(&appResult); err != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToDecodeAppRes, err)
+	}
+
+	return &appResult, nil
 }
 
 func (c *Client) accessTokenURLForInstallationID(installationID int) (*url.URL, error) {
@@ -284,6 +520,10 @@ func (c *Client) ListInstallations(ctx context.Context) (*logical.Response, erro
 		return nil, err
 	}
 
+	if c.installationCacheTTL() > 0 {
+		c.setInstallations(newInstallationsSnapshot(instResult))
+	}
+
 	installations := make(map[string]any, len(instResult))
 	for _, v := range instResult {
 		installations[v.Account.Login] = v.ID
@@ -293,8 +533,13 @@ func (c *Client) ListInstallations(ctx context.Context) (*logical.Response, erro
 }
 
 // installationID makes a round trip to the configured GitHub API in an attempt
-// to get the installation ID of the App.
+// to get the installation ID of the App, unless answered by the installations
+// cache.
 func (c *Client) installationID(ctx context.Context, orgName string) (int, error) {
+	if c.installationCacheTTL() > 0 {
+		return c.cachedInstallationID(ctx, orgName)
+	}
+
 	instResult, err := c.fetchInstallations(ctx)
 	if err != nil {
 		return 0, err
@@ -323,7 +568,7 @@ func (c *Client) fetchInstallations(ctx context.Context) ([]installation, error)
 		req.Header.Set("User-Agent", projectName)
 
 		// Perform the request, re-using the client's shared transport.
-		res, err := c.installationsClient.Do(req)
+		res, err := c.do(req, c.doApp)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", errUnableToGetInstallations, err)
 		}
@@ -356,6 +601,49 @@ func (c *Client) fetchInstallations(ctx context.Context) ([]installation, error)
 	return allInstallations, nil
 }
 
+// Installation makes a request to the GitHub API to fetch the installation of
+// the App with the given ID.
+func (c *Client) Installation(ctx context.Context, installationID int) (*installation, error) {
+	url := c.installationsURL.JoinPath(strconv.Itoa(installationID)).String()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToGetInstallation, err)
+	}
+
+	req.Header.Set("User-Agent", projectName)
+
+	res, err := c.do(req, c.doApp)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToGetInstallation, err)
+	}
+
+	defer res.Body.Close() //nolint:errcheck
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %d", errInstallationNotFound, installationID)
+	}
+
+	if statusCode(res.StatusCode).Unsuccessful() {
+		var bodyBytes []byte
+
+		if bodyBytes, err = io.ReadAll(res.Body); err != nil {
+			return nil, fmt.Errorf("%s: %w", errUnableToGetInstallation, err)
+		}
+
+		bodyErr := fmt.Errorf("%s: %s", res.Status, string(bodyBytes))
+
+		return nil, fmt.Errorf("%s: %w", errUnableToGetInstallation, bodyErr)
+	}
+
+	var instResult installation
+	if err = json.NewDecoder(res.Body).Decode(&instResult); err != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToDecodeInstallationRes, err)
+	}
+
+	return &instResult, nil
+}
+
 // getNextPageURL parses the Link header to find the URL for the next page.
 func getNextPageURL(linkHeader string) string {
 	if linkHeader == "" {
@@ -383,18 +671,46 @@ func getNextPageURL(linkHeader string) string {
 // Model the parts of a installations list response that we care about.
 type (
 	account struct {
+		// Login is the name of organization and user accounts.
 		Login string `json:"login"`
+		// Slug is the name of enterprise accounts.
+		Slug string `json:"slug"`
+		Type string `json:"type"`
 	}
 	installation struct {
-		Account account `json:"account"`
-		ID      int     `json:"id"`
+		CreatedAt           time.Time         `json:"created_at"`
+		SuspendedAt         *time.Time        `json:"suspended_at"`
+		Permissions         map[string]string `json:"permissions"`
+		Account             account           `json:"account"`
+		TargetType          string            `json:"target_type"`
+		RepositorySelection string            `json:"repository_selection"`
+		Events              []string          `json:"events"`
+		ID                  int               `json:"id"`
+		TargetID            int               `json:"target_id"`
 	}
 )
 
+// Model the parts of an app response that we care about.
+type app struct {
+	Owner              account           `json:"owner"`
+	Permissions        map[string]string `json:"permissions"`
+	Slug               string            `json:"slug"`
+	Name               string            `json:"name"`
+	Events             []string          `json:"events"`
+	ID                 int               `json:"id"`
+	InstallationsCount int               `json:"installations_count"`
+}
+
 // RevokeToken takes a valid access token and performs a revocation against
 // GitHub's APIs. If there are any failures on the wire or parsing request
 // and response object, an error is returned.
 func (c *Client) RevokeToken(ctx context.Context, token string) (*logical.Response, error) {
+	return c.revokeToken(ctx, token, 0)
+}
+
+// revokeToken revokes an access token of the given installation, recording
+// the rate limit of the installation if known (i.e. non-zero).
+func (c *Client) revokeToken(ctx context.Context, token string, installationID int) (*logical.Response, error) {
 	// Build the revocation request.
 	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.revocationURL.String(), nil)
 	if err != nil {
@@ -405,11 +721,15 @@ func (c *Client) RevokeToken(ctx context.Context, token string) (*logical.Respon
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 
 	// Perform the request, re-using the shared transport.
-	res, err := c.revocationClient.Do(req)
+	res, err := c.do(req, c.revocationClient.Do)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", errUnableToRevokeAccessToken, err)
 	}
 
+	if installationID != 0 {
+		c.recordRateLimit(installationID, res.Header)
+	}
+
 	defer res.Body.Close() //nolint:errcheck
 
 	if !statusCode(res.StatusCode).Revoked() {
@@ -11,7 +11,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/hashicorp/vault/sdk/logical"
 	"gotest.tools/assert"
 
@@ -599,20 +598,15 @@ func TestNewClient_WithProxy(t *testing.T) {
 	client, err := NewClient(conf)
 	assert.NilError(t, err)
 
-	appTransport, ok := client.installationsClient.Transport.(*ghinstallation.AppsTransport)
+	appTransport, ok := client.installationsClient.Transport.(*appsTransport)
 	if !ok {
-		t.Fatalf("Expected *ghinstallation.AppsTransport, got %T", client.installationsClient.Transport)
+		t.Fatalf("Expected *appsTransport, got %T", client.installationsClient.Transport)
 	}
 	assert.Assert(t, appTransport != nil)
 
-	httpClient, ok := appTransport.Client.(*http.Client)
+	transport, ok := appTransport.tr.(*http.Transport)
 	if !ok {
-		t.Fatalf("Expected *http.Client, got %T", appTransport.Client)
-	}
-
-	transport, ok := httpClient.Transport.(*http.Transport)
-	if !ok {
-		t.Fatalf("Expected *http.Transport, got %T", httpClient.Transport)
+		t.Fatalf("Expected *http.Transport, got %T", appTransport.tr)
 	}
 
 	req, err := http.NewRequest("GET", "http://example.com", nil)
@@ -622,3 +616,55 @@ func TestNewClient_WithProxy(t *testing.T) {
 	assert.NilError(t, err)
 	assert.Equal(t, proxy.String(), proxyURL)
 }
+
+func TestTokenRequest_LeaseOptions(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		tokReq    tokenRequest
+		expTTL    time.Duration
+		expMaxTTL time.Duration
+	}{
+		{
+			name:   "AlignedToExpiry",
+			expTTL: time.Hour,
+		},
+		{
+			name:   "Shortened",
+			tokReq: tokenRequest{TTL: 5 * time.Minute},
+			expTTL: 5 * time.Minute,
+		},
+		{
+			name:   "NotLengthened",
+			tokReq: tokenRequest{TTL: 2 * time.Hour},
+			expTTL: time.Hour,
+		},
+		{
+			name:      "Capped",
+			tokReq:    tokenRequest{MaxTTL: 10 * time.Minute},
+			expTTL:    10 * time.Minute,
+			expMaxTTL: 10 * time.Minute,
+		},
+		{
+			name:      "CappedByExpiry",
+			tokReq:    tokenRequest{TTL: 5 * time.Minute, MaxTTL: 2 * time.Hour},
+			expTTL:    5 * time.Minute,
+			expMaxTTL: time.Hour,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := tc.tokReq.leaseOptions(time.Hour)
+			assert.Equal(t, opts.TTL, tc.expTTL)
+			assert.Equal(t, opts.MaxTTL, tc.expMaxTTL)
+		})
+	}
+
+	assert.NilError(t, (&tokenRequest{TTL: time.Minute, MaxTTL: time.Minute}).validateTTL())
+	assert.ErrorContains(t, (&tokenRequest{TTL: 2 * time.Minute, MaxTTL: time.Minute}).validateTTL(),
+		errTTLExceedsMaxTTL.Error()+": 2m0s > 1m0s")
+}
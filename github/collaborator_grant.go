@@ -0,0 +1,19 @@
+package github
+
+import (
+	"context"
+)
+
+// collaboratorGrantsStoragePrefix is the storage path of the records of
+// temporary repository collaborators.
+const collaboratorGrantsStoragePrefix = pathPatternCollaborator + "/grants/"
+
+// collaboratorGrants is the kind of grants of temporary repository
+// collaborators, whose pending invitations are deleted along with them.
+var collaboratorGrants = &grantKind{
+	prefix: collaboratorGrantsStoragePrefix,
+	desc:   "temporary repository collaborator",
+	remove: func(ctx context.Context, client *Client, g *grant) error {
+		return client.RemoveCollaborator(ctx, g.InstallationID, g.OrgName, g.Repository, g.Username, g.InvitationID)
+	},
+}
@@ -0,0 +1,48 @@
+package github
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/assert"
+)
+
+func TestBackend_SweepCollaboratorGrants(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := newTestGitHubServer(t)
+	b, storage := testGitHubBackend(t, ts)
+
+	repo := newTestRepository(t, ts, &testRepository{
+		collaborators: map[string]string{
+			"expired": collaboratorPermissionPush,
+			"current": collaboratorPermissionPush,
+		},
+		invitations: map[int]string{1: "invited"},
+	})
+
+	// Grants whose lease revocations were lost, e.g. across a restart.
+	for _, g := range []*grant{
+		{ExpiresAt: time.Now().Add(-time.Minute), ID: "expired", Username: "expired"},
+		{ExpiresAt: time.Now().Add(-time.Minute), ID: "invited", Username: "invited", InvitationID: 1},
+		{ExpiresAt: time.Now().Add(time.Hour), ID: "current", Username: "current"},
+	} {
+		g.OrgName = testOrgName1
+		g.Repository = "website"
+		g.InstallationID = testInsID1
+		assert.NilError(t, g.save(ctx, storage, collaboratorGrants))
+	}
+
+	assert.NilError(t, b.periodicFunc(ctx, &logical.Request{Storage: storage}))
+
+	assert.Equal(t, repo.permission("expired"), "")
+	assert.Equal(t, repo.permission("current"), collaboratorPermissionPush)
+	assert.Equal(t, len(repo.invited()), 0)
+
+	grants, err := storage.List(ctx, collaboratorGrantsStoragePrefix)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, grants, []string{"current"})
+}
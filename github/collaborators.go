@@ -0,0 +1,207 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	errUnableToGetCollaborators   = Error("unable to get repository collaborators")
+	errUnableToGetInvitations     = Error("unable to get repository invitations")
+	errUnableToAddCollaborator    = Error("unable to add repository collaborator")
+	errUnableToRemoveCollaborator = Error("unable to remove repository collaborator")
+)
+
+// Repository collaborator permissions that can be granted.
+const (
+	collaboratorPermissionPull     = "pull"
+	collaboratorPermissionPush     = "push"
+	collaboratorPermissionMaintain = "maintain"
+)
+
+// collaboratorsPageSize is the number of collaborators, or invitations, listed
+// per page.
+const collaboratorsPageSize = 100
+
+// collaboratorsTokenPermissions are the permissions of the internal tokens
+// used to manage repository collaborators.
+var collaboratorsTokenPermissions = map[string]string{"administration": "write"}
+
+// Model the parts of a collaborator that we care about.
+type collaborator struct {
+	Login string `json:"login"`
+}
+
+// Model the parts of a repository invitation that we care about.
+type repositoryInvitation struct {
+	Invitee     collaborator `json:"invitee"`
+	Permissions string       `json:"permissions"`
+	ID          int          `json:"id"`
+}
+
+// collaboratorsTokenRequest returns the token request of the internal tokens
+// used to manage the collaborators of a repository of an installation.
+func collaboratorsTokenRequest(installationID int, repo string) *tokenRequest {
+	return &tokenRequest{
+		InstallationID: installationID,
+		tokenConstraints: tokenConstraints{
+			Repositories: []string{repo},
+			Permissions:  collaboratorsTokenPermissions,
+		},
+	}
+}
+
+// repositoryPath returns the API path of the repository of the owner.
+func repositoryPath(owner, repo string) string {
+	return fmt.Sprintf("repos/%s/%s", url.PathEscape(owner), url.PathEscape(repo))
+}
+
+// collaboratorPath returns the API path of the user as a collaborator on the
+// repository of the owner.
+func collaboratorPath(owner, repo, username string) string {
+	return fmt.Sprintf("%s/collaborators/%s", repositoryPath(owner, repo), url.PathEscape(username))
+}
+
+// ExistingCollaborator reports whether the user is a direct collaborator on,
+// or has a pending invitation to, the repository of the owner, as the
+// installation. Organization members with access through teams or base
+// permissions are not direct collaborators.
+func (c *Client) ExistingCollaborator(
+	ctx context.Context,
+	installationID int,
+	owner, repo, username string,
+) (direct, invited bool, err error) {
+	err = c.withInstallation(ctx, collaboratorsTokenRequest(installationID, repo),
+		func(s *installationSession) error {
+			if direct, err = s.directCollaborator(ctx, owner, repo, username); err != nil || direct {
+				return err
+			}
+
+			invited, err = s.invited(ctx, owner, repo, username)
+
+			return err
+		},
+	)
+
+	return direct, invited, err
+}
+
+// directCollaborator reports whether the user is a direct collaborator on the
+// repository of the owner.
+func (s *installationSession) directCollaborator(ctx context.Context, owner, repo, username string) (bool, error) {
+	for page := 1; ; page++ {
+		var collaborators []collaborator
+
+		path := fmt.Sprintf("%s/collaborators?%s", repositoryPath(owner, repo), url.Values{
+			"affiliation": {"direct"},
+			"per_page":    {strconv.Itoa(collaboratorsPageSize)},
+			"page":        {strconv.Itoa(page)},
+		}.Encode())
+
+		if err := s.request(ctx, http.MethodGet, path, nil, &collaborators); err != nil {
+			return false, fmt.Errorf("%s: %w", errUnableToGetCollaborators, err)
+		}
+
+		for _, collaborator := range collaborators {
+			if strings.EqualFold(collaborator.Login, username) {
+				return true, nil
+			}
+		}
+
+		if len(collaborators) < collaboratorsPageSize {
+			return false, nil
+		}
+	}
+}
+
+// invited reports whether the user has a pending invitation to the repository
+// of the owner.
+func (s *installationSession) invited(ctx context.Context, owner, repo, username string) (bool, error) {
+	for page := 1; ; page++ {
+		var invitations []repositoryInvitation
+
+		path := fmt.Sprintf("%s/invitations?%s", repositoryPath(owner, repo), url.Values{
+			"per_page": {strconv.Itoa(collaboratorsPageSize)},
+			"page":     {strconv.Itoa(page)},
+		}.Encode())
+
+		if err := s.request(ctx, http.MethodGet, path, nil, &invitations); err != nil {
+			return false, fmt.Errorf("%s: %w", errUnableToGetInvitations, err)
+		}
+
+		for _, invitation := range invitations {
+			if strings.EqualFold(invitation.Invitee.Login, username) {
+				return true, nil
+			}
+		}
+
+		if len(invitations) < collaboratorsPageSize {
+			return false, nil
+		}
+	}
+}
+
+// AddCollaborator adds the user as a collaborator on the repository of the
+// owner with the given permission, as the installation. Users that are not
+// members of the organization are invited, in which case the invitation is
+// returned; otherwise it is nil.
+func (c *Client) AddCollaborator(
+	ctx context.Context,
+	installationID int,
+	owner, repo, username, permission string,
+) (*repositoryInvitation, error) {
+	var invitation repositoryInvitation
+
+	if err := c.installationRequest(ctx, collaboratorsTokenRequest(installationID, repo),
+		http.MethodPut, collaboratorPath(owner, repo, username),
+		map[string]string{"permission": permission}, &invitation,
+	); err != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToAddCollaborator, err)
+	}
+
+	if invitation.ID == 0 {
+		return nil, nil
+	}
+
+	return &invitation, nil
+}
+
+// RemoveCollaborator removes the user as a collaborator from the repository of
+// the owner, as the installation, first deleting the invitation of the user if
+// one was given. Invitations and collaborators that are not found are
+// considered removed.
+func (c *Client) RemoveCollaborator(
+	ctx context.Context,
+	installationID int,
+	owner, repo, username string,
+	invitationID int,
+) error {
+	paths := []string{collaboratorPath(owner, repo, username)}
+	if invitationID != 0 {
+		paths = append([]string{fmt.Sprintf("%s/invitations/%d", repositoryPath(owner, repo), invitationID)}, paths...)
+	}
+
+	return c.withInstallation(ctx, collaboratorsTokenRequest(installationID, repo),
+		func(s *installationSession) error {
+			for _, path := range paths {
+				err := s.request(ctx, http.MethodDelete, path, nil, nil)
+
+				var apiErr *apiError
+				if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+					continue
+				}
+
+				if err != nil {
+					return fmt.Errorf("%s: %w", errUnableToRemoveCollaborator, err)
+				}
+			}
+
+			return nil
+		},
+	)
+}
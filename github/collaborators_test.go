@@ -0,0 +1,73 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestCollaboratorsTokenRequest(t *testing.T) {
+	t.Parallel()
+
+	tokReq := collaboratorsTokenRequest(testInsID1, "website")
+	assert.Equal(t, tokReq.InstallationID, testInsID1)
+	assert.DeepEqual(t, tokReq.tokenConstraints, tokenConstraints{
+		Repositories: []string{"website"},
+		Permissions:  map[string]string{"administration": "write"},
+	})
+}
+
+func TestClient_ExistingCollaborator(t *testing.T) {
+	t.Parallel()
+
+	// Span several pages of collaborators and invitations.
+	collaborators := map[string]string{}
+	invitations := map[int]string{}
+	for i := range collaboratorsPageSize + 10 {
+		collaborators[fmt.Sprintf("user-%03d", i)] = collaboratorPermissionPull
+		invitations[i+1] = fmt.Sprintf("invitee-%03d", i)
+	}
+
+	ts := newTestGitHubServer(t)
+	newTestRepository(t, ts, &testRepository{collaborators: collaborators, invitations: invitations})
+
+	config := NewConfig()
+	config.AppID = testAppID1
+	config.PrvKey = testPrvKeyValid
+	config.BaseURL = ts.URL
+
+	client, err := NewClient(config)
+	assert.NilError(t, err)
+
+	ctx := context.Background()
+
+	cases := map[string]struct{ direct, invited bool }{
+		"user-000":    {direct: true},
+		"USER-105":    {direct: true},
+		"user-110":    {},
+		"outsider":    {},
+		"user-0000":   {},
+		"invitee-000": {invited: true},
+		"INVITEE-109": {invited: true},
+		"invitee-110": {},
+	}
+
+	for username, exp := range cases {
+		direct, invited, err := client.ExistingCollaborator(ctx, testInsID1, testOrgName1, "website", username)
+		assert.NilError(t, err)
+		assert.Equal(t, direct, exp.direct, username)
+		assert.Equal(t, invited, exp.invited, username)
+	}
+
+	// One token is minted for each check, however many pages it spans.
+	assert.Equal(t, len(ts.tokenRequests()), len(cases))
+}
+
+func TestCollaboratorPath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, collaboratorPath("acme", "web site", "octo/../cat"),
+		"repos/acme/web%20site/collaborators/octo%2F..%2Fcat")
+}
@@ -1,30 +1,52 @@
 package github
 
 import (
+	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
 	"fmt"
+	"maps"
 	"net/url"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
 )
 
 const githubPublicAPI = "https://api.github.com"
 
+// minPrvKeyBits is the smallest RSA modulus size accepted for private keys.
+const minPrvKeyBits = 2048
+
 const (
 	errUnableToParsePrvKey  = Error("unable to parse private key")
 	errUnableToParseBaseURL = Error("unable to parse base URL")
+	errUnableToParseVault   = Error("unable to parse Vault address")
 	errFieldDataNil         = Error("field data passed for updating was nil")
 	errKeyNotPEMFormat      = Error("key is not a PEM formatted RSA private key")
+	errKeyEncrypted         = Error("key is an encrypted PEM block and must be decrypted first")
+	errKeyNotRSA            = Error("key is not an RSA private key")
+	errKeyTooSmall          = Error("key is smaller than the minimum RSA modulus size")
 )
 
 // Config holds all configuration for the backend.
 type Config struct {
 	// PrvKey is the private for signing GitHub access token requests (JWTs).
-	// NOTE: Should be in a PEM PKCS#1 RSAPrivateKey format.
+	// NOTE: Always stored in a PEM PKCS#1 RSAPrivateKey format, though PEM
+	// PKCS#8 PrivateKeyInfo wrapped RSA keys are accepted and normalised.
 	PrvKey string `json:"prv_key"`
 
+	// PendingPrvKey is a private key staged for rotation. It is promoted to
+	// PrvKey once GitHub has been shown to accept it.
+	PendingPrvKey string `json:"pending_prv_key,omitempty"`
+
+	// FallbackPrvKey is the private key that was active before the last
+	// promotion. It is used when GitHub rejects PrvKey until it is retired.
+	FallbackPrvKey string `json:"fallback_prv_key,omitempty"`
+
 	// BaseURL is the base URL for API requests.
 	// Defaults to GitHub's public API.
 	BaseURL string `json:"base_url"`
@@ -32,6 +54,126 @@ type Config struct {
 	// AppID is the application identifier of the GitHub App.
 	AppID int `json:"app_id"`
 
+	// TransitKey is the name of a Vault Transit secrets engine key that signs
+	// GitHub access token requests (JWTs) in place of PrvKey. It must be an
+	// RSA key holding the GitHub App private key, which then never needs to be
+	// stored by the plugin.
+	TransitKey string `json:"transit_key,omitempty"`
+
+	// TransitMount is the mount path of the Transit secrets engine holding
+	// TransitKey. Defaults to "transit".
+	TransitMount string `json:"transit_mount,omitempty"`
+
+	// VaultAddr is the address of the Vault server hosting TransitKey.
+	// Defaults to the VAULT_ADDR environment variable of the plugin.
+	VaultAddr string `json:"vault_addr,omitempty"`
+
+	// VaultToken is the token used to sign with TransitKey. It needs only the
+	// "update" capability on the Transit sign path of TransitKey.
+	VaultToken string `json:"vault_token,omitempty"`
+
+	// VaultNamespace is the Vault Enterprise namespace of TransitMount.
+	VaultNamespace string `json:"vault_namespace,omitempty"`
+
+	// CACertificate is a PEM encoded bundle of CA certificates trusted when
+	// connecting to GitHub, in place of the system trust store. It is useful
+	// for GitHub Enterprise Server behind an internal CA.
+	CACertificate string `json:"ca_certificate,omitempty"`
+
+	// ClientCertificate and ClientKey are a PEM encoded certificate and key
+	// presented to GitHub for mutual TLS.
+	ClientCertificate string `json:"client_certificate,omitempty"`
+	ClientKey         string `json:"client_key,omitempty"`
+
+	// TLSMinVersion is the minimum TLS version used to connect to GitHub.
+	// Defaults to "tls12".
+	TLSMinVersion string `json:"tls_min_version,omitempty"`
+
+	// TLSServerName overrides the server name used to verify the certificate
+	// of GitHub.
+	TLSServerName string `json:"tls_server_name,omitempty"`
+
+	// ProxyURL is the URL of the proxy that requests to GitHub are sent
+	// through. Defaults to the proxy of the plugin's environment.
+	ProxyURL string `json:"proxy_url,omitempty"`
+
+	// NoProxy are the hosts that are connected to directly, bypassing the
+	// proxy, in the format of the NO_PROXY environment variable.
+	NoProxy []string `json:"no_proxy,omitempty"`
+
+	// RequestTimeout is the timeout of requests to GitHub. Defaults to 10s.
+	RequestTimeout time.Duration `json:"request_timeout,omitempty"`
+
+	// DialTimeout is the timeout of connecting to GitHub. Defaults to a
+	// quarter of RequestTimeout.
+	DialTimeout time.Duration `json:"dial_timeout,omitempty"`
+
+	// TLSHandshakeTimeout is the timeout of TLS handshakes with GitHub.
+	// Defaults to a quarter of RequestTimeout.
+	TLSHandshakeTimeout time.Duration `json:"tls_handshake_timeout,omitempty"`
+
+	// MaxIdleConns limits the idle connections kept open in total. Defaults to
+	// no limit.
+	MaxIdleConns int `json:"max_idle_conns,omitempty"`
+
+	// MaxIdleConnsPerHost limits the idle connections kept open to GitHub.
+	// Defaults to Go's default of 2.
+	MaxIdleConnsPerHost int `json:"max_idle_conns_per_host,omitempty"`
+
+	// IdleConnTimeout is how long idle connections are kept open. Defaults to
+	// no limit.
+	IdleConnTimeout time.Duration `json:"idle_conn_timeout,omitempty"`
+
+	// MaxRetries is how many times a request to GitHub that failed
+	// transiently is retried. Defaults to 3.
+	MaxRetries *int `json:"max_retries,omitempty"`
+
+	// InstallationCacheTTL is how long the installations of the App are cached
+	// to look up installation IDs by organization name. Zero disables the
+	// cache. Defaults to 5m.
+	InstallationCacheTTL *time.Duration `json:"installation_cache_ttl,omitempty"`
+
+	// InstallationCacheNegativeTTL is how long an organization is cached as
+	// not having the App installed. Defaults to 1m.
+	InstallationCacheNegativeTTL *time.Duration `json:"installation_cache_negative_ttl,omitempty"`
+
+	// InstallationCacheStorage persists the installations cache to storage so
+	// that it survives plugin restarts.
+	InstallationCacheStorage bool `json:"installation_cache_storage,omitempty"`
+
+	// PermissionCatalog overrides the built-in catalog of permissions and their
+	// allowed access levels (comma separated), e.g. for GHES versions that
+	// differ. An empty value removes a permission.
+	PermissionCatalog map[string]string `json:"permission_catalog,omitempty"`
+
+	// CheckGrantedPermissions checks requested permissions against those
+	// granted to the installation before requesting tokens.
+	CheckGrantedPermissions bool `json:"check_granted_permissions,omitempty"`
+
+	// AllowedOrgNames and AllowedInstallationIDs are glob patterns that the
+	// organization name and installation ID of token requests must match, if
+	// any are configured.
+	AllowedOrgNames        []string `json:"allowed_org_names,omitempty"`
+	AllowedInstallationIDs []string `json:"allowed_installation_ids,omitempty"`
+
+	// DeniedOrgNames and DeniedInstallationIDs are glob patterns that the
+	// organization name and installation ID of token requests must not match.
+	// They take precedence over the allowed patterns.
+	DeniedOrgNames        []string `json:"denied_org_names,omitempty"`
+	DeniedInstallationIDs []string `json:"denied_installation_ids,omitempty"`
+
+	// DisableAdhocTokens refuses token requests other than those of
+	// permission sets.
+	DisableAdhocTokens bool `json:"disable_adhoc_tokens,omitempty"`
+
+	// CacheAdhocTokens returns cached tokens for identical ad-hoc token
+	// requests, as permission sets can opt in to.
+	CacheAdhocTokens bool `json:"cache_adhoc_tokens,omitempty"`
+
+	// TokenCacheMinRemaining is the lifetime a cached token must have left to
+	// be returned, unless overridden by a permission set. Defaults to 30m.
+	TokenCacheMinRemaining *time.Duration `json:"token_cache_min_remaining,omitempty"`
+
 	// ExcludeRepositoryMetadata controls filtering of the 'repositories' key
 	// returned on repository-filtered tokens. It defaults to returning full
 	// repository metadata but will return a minimised list of repository names
@@ -68,11 +210,12 @@ func (c *Config) Update(d *framework.FieldData) (bool, error) { //nolint:cyclop
 	}
 
 	if prvKey, ok := d.GetOk(keyPrvKey); ok {
-		if nv := strings.TrimSpace(prvKey.(string)); c.PrvKey != nv {
-			if err := validatePrvKeyStr(nv); err != nil {
-				return false, err
-			}
+		nv, err := normalisePrvKey(prvKey.(string))
+		if err != nil {
+			return false, err
+		}
 
+		if c.PrvKey != nv {
 			c.PrvKey = nv
 			changed = true
 		}
@@ -85,6 +228,193 @@ func (c *Config) Update(d *framework.FieldData) (bool, error) { //nolint:cyclop
 		}
 	}
 
+	if vaultAddr, ok := d.GetOk(keyVaultAddr); ok {
+		nv := vaultAddr.(string)
+		if nv != "" {
+			u, err := url.ParseRequestURI(nv)
+			if err != nil {
+				return false, fmt.Errorf("%s: %w", errUnableToParseVault, err)
+			}
+
+			nv = u.String()
+		}
+
+		if c.VaultAddr != nv {
+			c.VaultAddr = nv
+			changed = true
+		}
+	}
+
+	for key, field := range map[string]*string{
+		keyTransitKey:        &c.TransitKey,
+		keyTransitMount:      &c.TransitMount,
+		keyVaultToken:        &c.VaultToken,
+		keyVaultNamespace:    &c.VaultNamespace,
+		keyCACertificate:     &c.CACertificate,
+		keyClientCertificate: &c.ClientCertificate,
+		keyClientKey:         &c.ClientKey,
+		keyTLSMinVersion:     &c.TLSMinVersion,
+		keyTLSServerName:     &c.TLSServerName,
+	} {
+		if v, ok := d.GetOk(key); ok {
+			if nv := v.(string); *field != nv {
+				*field = nv
+				changed = true
+			}
+		}
+	}
+
+	if proxyURL, ok := d.GetOk(keyProxyURL); ok {
+		nv, err := validateProxyURL(proxyURL.(string))
+		if err != nil {
+			return false, err
+		}
+
+		if c.ProxyURL != nv {
+			c.ProxyURL = nv
+			changed = true
+		}
+	}
+
+	if noProxy, ok := d.GetOk(keyNoProxy); ok {
+		if nv := noProxy.([]string); !slices.Equal(c.NoProxy, nv) {
+			c.NoProxy = nv
+			changed = true
+		}
+	}
+
+	for key, field := range map[string]*time.Duration{
+		keyRequestTimeout:      &c.RequestTimeout,
+		keyDialTimeout:         &c.DialTimeout,
+		keyTLSHandshakeTimeout: &c.TLSHandshakeTimeout,
+		keyIdleConnTimeout:     &c.IdleConnTimeout,
+	} {
+		if v, ok := d.GetOk(key); ok {
+			if v.(int) < 0 {
+				return false, fmt.Errorf("%s: %w", key, errNegativeValue)
+			}
+
+			if nv := time.Duration(v.(int)) * time.Second; *field != nv {
+				*field = nv
+				changed = true
+			}
+		}
+	}
+
+	for key, field := range map[string]*int{
+		keyMaxIdleConns:        &c.MaxIdleConns,
+		keyMaxIdleConnsPerHost: &c.MaxIdleConnsPerHost,
+	} {
+		if v, ok := d.GetOk(key); ok {
+			if v.(int) < 0 {
+				return false, fmt.Errorf("%s: %w", key, errNegativeValue)
+			}
+
+			if nv := v.(int); *field != nv {
+				*field = nv
+				changed = true
+			}
+		}
+	}
+
+	if maxRetries, ok := d.GetOk(keyMaxRetries); ok {
+		nv := maxRetries.(int)
+		if nv < 0 {
+			return false, fmt.Errorf("%s: %w", keyMaxRetries, errNegativeValue)
+		}
+
+		if c.MaxRetries == nil || *c.MaxRetries != nv {
+			c.MaxRetries = &nv
+			changed = true
+		}
+	}
+
+	for key, field := range map[string]**time.Duration{
+		keyInstallationCacheTTL:         &c.InstallationCacheTTL,
+		keyInstallationCacheNegativeTTL: &c.InstallationCacheNegativeTTL,
+	} {
+		if v, ok := d.GetOk(key); ok {
+			if v.(int) < 0 {
+				return false, fmt.Errorf("%s: %w", key, errNegativeValue)
+			}
+
+			if nv := time.Duration(v.(int)) * time.Second; *field == nil || **field != nv {
+				*field = &nv
+				changed = true
+			}
+		}
+	}
+
+	if ics, ok := d.GetOk(keyInstallationCacheStorage); ok {
+		if nv := ics.(bool); c.InstallationCacheStorage != nv {
+			c.InstallationCacheStorage = nv
+			changed = true
+		}
+	}
+
+	if pc, ok := d.GetOk(keyPermissionCatalog); ok {
+		nv := pc.(map[string]string)
+		if err := validatePermissionCatalog(nv); err != nil {
+			return false, err
+		}
+
+		if !maps.Equal(c.PermissionCatalog, nv) {
+			c.PermissionCatalog = nv
+			changed = true
+		}
+	}
+
+	if cgp, ok := d.GetOk(keyCheckGrantedPermissions); ok {
+		if nv := cgp.(bool); c.CheckGrantedPermissions != nv {
+			c.CheckGrantedPermissions = nv
+			changed = true
+		}
+	}
+
+	for key, field := range map[string]*[]string{
+		keyAllowedOrgNames:        &c.AllowedOrgNames,
+		keyAllowedInstallationIDs: &c.AllowedInstallationIDs,
+		keyDeniedOrgNames:         &c.DeniedOrgNames,
+		keyDeniedInstallationIDs:  &c.DeniedInstallationIDs,
+	} {
+		if v, ok := d.GetOk(key); ok {
+			nv := v.([]string)
+			if err := validatePatterns(nv); err != nil {
+				return false, fmt.Errorf("%s: %w", key, err)
+			}
+
+			if !slices.Equal(*field, nv) {
+				*field = nv
+				changed = true
+			}
+		}
+	}
+
+	if dat, ok := d.GetOk(keyDisableAdhocTokens); ok {
+		if nv := dat.(bool); c.DisableAdhocTokens != nv {
+			c.DisableAdhocTokens = nv
+			changed = true
+		}
+	}
+
+	if cat, ok := d.GetOk(keyCacheAdhocTokens); ok {
+		if nv := cat.(bool); c.CacheAdhocTokens != nv {
+			c.CacheAdhocTokens = nv
+			changed = true
+		}
+	}
+
+	if tcmr, ok := d.GetOk(keyTokenCacheMinRemaining); ok {
+		if tcmr.(int) < 0 {
+			return false, fmt.Errorf("%s: %w", keyTokenCacheMinRemaining, errNegativeValue)
+		}
+
+		if nv := time.Duration(tcmr.(int)) * time.Second; c.TokenCacheMinRemaining == nil || *c.TokenCacheMinRemaining != nv {
+			c.TokenCacheMinRemaining = &nv
+			changed = true
+		}
+	}
+
 	if irm, ok := d.GetOk(keyExcludeRepositoryMetadata); ok {
 		if nv := irm.(bool); c.ExcludeRepositoryMetadata != nv {
 			c.ExcludeRepositoryMetadata = nv
@@ -92,18 +422,88 @@ func (c *Config) Update(d *framework.FieldData) (bool, error) { //nolint:cyclop
 		}
 	}
 
+	// Validate the TLS settings as a whole since they depend on each other.
+	if _, err := c.tlsConfig(); err != nil {
+		return false, err
+	}
+
 	return changed, nil
 }
 
-func validatePrvKeyStr(k string) error {
+// normalisePrvKey validates the given PEM encoded RSA private key and returns
+// it in PEM PKCS#1 RSAPrivateKey format. PKCS#1 keys are returned as given
+// (less surrounding whitespace) whereas PKCS#8 keys are re-encoded.
+func normalisePrvKey(k string) (string, error) {
+	k = strings.TrimSpace(k)
+
 	pemKey, _ := pem.Decode([]byte(k))
-	if pemKey == nil || pemKey.Type != "RSA PRIVATE KEY" {
-		return errKeyNotPEMFormat
+	if pemKey == nil {
+		return "", errKeyNotPEMFormat
 	}
 
-	if _, err := x509.ParsePKCS1PrivateKey(pemKey.Bytes); err != nil {
-		return fmt.Errorf("%s: %w", errUnableToParsePrvKey, err)
+	// Legacy OpenSSL encryption is signalled by headers on the PEM block,
+	// whereas PKCS#8 encryption has a dedicated PEM block type.
+	if strings.Contains(pemKey.Headers["Proc-Type"], "ENCRYPTED") ||
+		pemKey.Type == "ENCRYPTED PRIVATE KEY" {
+		return "", errKeyEncrypted
 	}
 
-	return nil
+	var rsaKey *rsa.PrivateKey
+
+	switch pemKey.Type {
+	case "RSA PRIVATE KEY":
+		var err error
+		if rsaKey, err = x509.ParsePKCS1PrivateKey(pemKey.Bytes); err != nil {
+			return "", fmt.Errorf("%s: %w", errUnableToParsePrvKey, err)
+		}
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(pemKey.Bytes)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", errUnableToParsePrvKey, err)
+		}
+
+		var ok bool
+		if rsaKey, ok = key.(*rsa.PrivateKey); !ok {
+			return "", fmt.Errorf("%w: found %T", errKeyNotRSA, key)
+		}
+	default:
+		return "", errKeyNotPEMFormat
+	}
+
+	if bits := rsaKey.N.BitLen(); bits < minPrvKeyBits {
+		return "", fmt.Errorf("%w: %d < %d bits", errKeyTooSmall, bits, minPrvKeyBits)
+	}
+
+	if pemKey.Type == "RSA PRIVATE KEY" {
+		return k, nil
+	}
+
+	return strings.TrimSpace(string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(rsaKey),
+	}))), nil
+}
+
+// prvKeyFingerprint returns the SHA256 fingerprint of the public half of the
+// given private key, formatted as GitHub displays it. An empty string is
+// returned for an empty or unparseable key.
+func prvKeyFingerprint(k string) string {
+	pemKey, _ := pem.Decode([]byte(k))
+	if pemKey == nil {
+		return ""
+	}
+
+	prvKey, err := x509.ParsePKCS1PrivateKey(pemKey.Bytes)
+	if err != nil {
+		return ""
+	}
+
+	pubKey, err := x509.MarshalPKIXPublicKey(&prvKey.PublicKey)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(pubKey)
+
+	return "SHA256:" + base64.StdEncoding.EncodeToString(sum[:])
 }
@@ -1,6 +1,11 @@
 package github
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"strings"
 	"testing"
 
@@ -50,6 +55,8 @@ VLzTE2sMc4ivGY2f9WcCvOIVXwYnKbDOTDJ0GicdWPljnRsZSNBxKQ==
 func TestConfig_Update(t *testing.T) {
 	t.Parallel()
 
+	testPrvKeyPKCS8, testPrvKeyEncrypted, testPrvKeyEC, testPrvKeySmall := testPrvKeyVariants(t)
+
 	cases := []struct {
 		err     error
 		new     *Config
@@ -121,6 +128,47 @@ func TestConfig_Update(t *testing.T) {
 			changed: false,
 			err:     errUnableToParseBaseURL,
 		},
+		{
+			name: "Transit",
+			new:  &Config{},
+			exp: &Config{
+				TransitKey: "github",
+				VaultAddr:  "https://vault.example.com:8200",
+				VaultToken: testToken,
+			},
+			data: &framework.FieldData{
+				Raw: map[string]any{
+					keyTransitKey: "github",
+					keyVaultAddr:  "https://vault.example.com:8200",
+					keyVaultToken: testToken,
+				},
+			},
+			changed: true,
+		},
+		{
+			name: "VaultAddrInvalid",
+			new:  &Config{},
+			exp:  &Config{},
+			data: &framework.FieldData{
+				Raw: map[string]any{
+					keyVaultAddr: testBaseURLInvalid,
+				},
+			},
+			changed: false,
+			err:     errUnableToParseVault,
+		},
+		{
+			name: "TLSMinVersionInvalid",
+			new:  &Config{},
+			exp:  &Config{TLSMinVersion: "ssl3"},
+			data: &framework.FieldData{
+				Raw: map[string]any{
+					keyTLSMinVersion: "ssl3",
+				},
+			},
+			changed: false,
+			err:     errInvalidTLSMinVersion,
+		},
 		{
 			name: "PrivateKeyNotPEMEncoded",
 			new:  &Config{},
@@ -145,6 +193,76 @@ func TestConfig_Update(t *testing.T) {
 			changed: false,
 			err:     errUnableToParsePrvKey,
 		},
+		{
+			name: "PrivateKeyPKCS8Normalised",
+			new:  &Config{},
+			exp:  &Config{PrvKey: testPrvKeyValid},
+			data: &framework.FieldData{
+				Raw: map[string]any{
+					keyPrvKey: testPrvKeyPKCS8,
+				},
+			},
+			changed: true,
+		},
+		{
+			name: "PrivateKeyPKCS8Unchanged",
+			new:  &Config{PrvKey: testPrvKeyValid},
+			exp:  &Config{PrvKey: testPrvKeyValid},
+			data: &framework.FieldData{
+				Raw: map[string]any{
+					keyPrvKey: testPrvKeyPKCS8,
+				},
+			},
+			changed: false,
+		},
+		{
+			name: "PrivateKeyEncrypted",
+			new:  &Config{},
+			exp:  &Config{},
+			data: &framework.FieldData{
+				Raw: map[string]any{
+					keyPrvKey: testPrvKeyEncrypted,
+				},
+			},
+			changed: false,
+			err:     errKeyEncrypted,
+		},
+		{
+			name: "PrivateKeyNotRSA",
+			new:  &Config{},
+			exp:  &Config{},
+			data: &framework.FieldData{
+				Raw: map[string]any{
+					keyPrvKey: testPrvKeyEC,
+				},
+			},
+			changed: false,
+			err:     errKeyNotRSA,
+		},
+		{
+			name: "PrivateKeyTooSmall",
+			new:  &Config{},
+			exp:  &Config{},
+			data: &framework.FieldData{
+				Raw: map[string]any{
+					keyPrvKey: testPrvKeySmall,
+				},
+			},
+			changed: false,
+			err:     errKeyTooSmall,
+		},
+		{
+			name: "PrivateKeyWrongType",
+			new:  &Config{},
+			exp:  &Config{},
+			data: &framework.FieldData{
+				Raw: map[string]any{
+					keyPrvKey: strings.ReplaceAll(testPrvKeyValid, "RSA PRIVATE KEY", "PUBLIC KEY"),
+				},
+			},
+			changed: false,
+			err:     errKeyNotPEMFormat,
+		},
 	}
 
 	for _, tc := range cases {
@@ -167,3 +285,39 @@ func TestConfig_Update(t *testing.T) {
 		})
 	}
 }
+
+// testPrvKeyVariants returns the valid test key in PKCS#8 format alongside
+// encrypted, non-RSA and undersized private keys.
+func testPrvKeyVariants(t *testing.T) (pkcs8, encrypted, ec, small string) {
+	t.Helper()
+
+	block, _ := pem.Decode([]byte(testPrvKeyValid))
+	rsaKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	assert.NilError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	assert.NilError(t, err)
+
+	pkcs8 = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+
+	encrypted = string(pem.EncodeToMemory(&pem.Block{
+		Type: "RSA PRIVATE KEY",
+		Headers: map[string]string{
+			"Proc-Type": "4,ENCRYPTED",
+			"DEK-Info":  "AES-256-CBC,00000000000000000000000000000000",
+		},
+		Bytes: block.Bytes,
+	}))
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+
+	der, err = x509.MarshalPKCS8PrivateKey(ecKey)
+	assert.NilError(t, err)
+
+	ec = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+
+	small, _ = testGeneratePrvKey(t, 1024)
+
+	return pkcs8, encrypted, ec, small
+}
@@ -0,0 +1,109 @@
+package github
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	errUnableToGenerateDeployKey = Error("unable to generate deploy key")
+	errUnableToCreateDeployKey   = Error("unable to create deploy key")
+	errUnableToDeleteDeployKey   = Error("unable to delete deploy key")
+)
+
+// deployKeysTokenPermissions are the permissions of the internal tokens used
+// to manage deploy keys.
+var deployKeysTokenPermissions = map[string]string{"administration": "write"}
+
+// Model the parts of a deploy key that we care about.
+type deployKey struct {
+	Key      string `json:"key"`
+	Title    string `json:"title"`
+	ID       int    `json:"id,omitempty"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+// generateDeployKey generates an ed25519 key pair, returning the public key in
+// the authorized keys format and the private key in the OpenSSH PEM format.
+func generateDeployKey(comment string) (string, string, error) {
+	pub, prv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", errUnableToGenerateDeployKey, err)
+	}
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", errUnableToGenerateDeployKey, err)
+	}
+
+	block, err := ssh.MarshalPrivateKey(prv, comment)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", errUnableToGenerateDeployKey, err)
+	}
+
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub))), string(pem.EncodeToMemory(block)), nil
+}
+
+// deployKeysTokenRequest returns the token request of the internal tokens used
+// to manage the deploy keys of a repository of an installation.
+func deployKeysTokenRequest(installationID int, repo string) *tokenRequest {
+	return &tokenRequest{
+		InstallationID: installationID,
+		tokenConstraints: tokenConstraints{
+			Repositories: []string{repo},
+			Permissions:  deployKeysTokenPermissions,
+		},
+	}
+}
+
+// CreateDeployKey registers the deploy key on the repository of the owner,
+// as the installation, returning the created deploy key.
+func (c *Client) CreateDeployKey(
+	ctx context.Context,
+	installationID int,
+	owner, repo string,
+	key *deployKey,
+) (*deployKey, error) {
+	var created deployKey
+
+	if err := c.installationRequest(ctx, deployKeysTokenRequest(installationID, repo),
+		http.MethodPost, fmt.Sprintf("repos/%s/%s/keys", owner, repo), key, &created,
+	); err != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToCreateDeployKey, err)
+	}
+
+	return &created, nil
+}
+
+// DeleteDeployKey deletes the deploy key with the given ID from the
+// repository of the owner, as the installation. Keys that no longer exist are
+// considered deleted.
+func (c *Client) DeleteDeployKey(
+	ctx context.Context,
+	installationID int,
+	owner, repo string,
+	id int,
+) error {
+	err := c.installationRequest(ctx, deployKeysTokenRequest(installationID, repo),
+		http.MethodDelete, fmt.Sprintf("repos/%s/%s/keys/%d", owner, repo, id), nil, nil,
+	)
+
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", errUnableToDeleteDeployKey, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const keyGrantID = "grant_id"
+
+// grantKind describes a kind of temporary access granted on GitHub, such as
+// team memberships or repository collaborators, whose grants are recorded
+// under its storage prefix.
+type grantKind struct {
+	prefix string
+	desc   string
+	remove func(ctx context.Context, client *Client, g *grant) error
+}
+
+// grant is the durable record of temporary access granted on GitHub, kept
+// until the access is removed. Should the revocation of its lease fail, or be
+// lost, the access is removed by the periodic sweep once expired.
+type grant struct {
+	ExpiresAt      time.Time
+	ID             string
+	Role           string
+	App            string `json:",omitempty"`
+	OrgName        string
+	Team           string `json:",omitempty"`
+	Repository     string `json:",omitempty"`
+	Username       string
+	InstallationID int
+	InvitationID   int `json:",omitempty"`
+}
+
+// newGrantID returns a random ID for the record of a grant.
+func newGrantID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func (g *grant) save(ctx context.Context, s logical.Storage, kind *grantKind) error {
+	entry, err := logical.StorageEntryJSON(kind.prefix+g.ID, g)
+	if err != nil {
+		return err
+	}
+
+	return s.Put(ctx, entry)
+}
+
+// internalData returns the lease internal data of the grant, which holds all
+// that is needed to remove the access should the record be gone.
+func (g *grant) internalData() map[string]any {
+	return map[string]any{
+		keyGrantID:        g.ID,
+		keyApp:            g.App,
+		keyOrgName:        g.OrgName,
+		keyTeam:           g.Team,
+		keyRepository:     g.Repository,
+		keyUsername:       g.Username,
+		keyInstallationID: strconv.Itoa(g.InstallationID),
+		keyInvitationID:   strconv.Itoa(g.InvitationID),
+	}
+}
+
+// grantFromInternalData returns the grant of the lease internal data.
+func grantFromInternalData(data map[string]any) *grant {
+	g := &grant{}
+	g.ID, _ = data[keyGrantID].(string)
+	g.App, _ = data[keyApp].(string)
+	g.OrgName, _ = data[keyOrgName].(string)
+	g.Team, _ = data[keyTeam].(string)
+	g.Repository, _ = data[keyRepository].(string)
+	g.Username, _ = data[keyUsername].(string)
+	g.InstallationID, _ = strconv.Atoi(fmt.Sprint(data[keyInstallationID]))
+	g.InvitationID, _ = strconv.Atoi(fmt.Sprint(data[keyInvitationID]))
+
+	return g
+}
+
+// logArgs returns the log key-value pairs identifying the grant.
+func (g *grant) logArgs() []any {
+	args := []any{keyOrgName, g.OrgName}
+
+	if g.Team != "" {
+		args = append(args, keyTeam, g.Team)
+	}
+
+	if g.Repository != "" {
+		args = append(args, keyRepository, g.Repository)
+	}
+
+	return append(args, keyUsername, g.Username)
+}
+
+// removeGrant removes the access of the grant and its record.
+func (b *backend) removeGrant(ctx context.Context, s logical.Storage, kind *grantKind, g *grant) error {
+	client, done, err := b.Client(ctx, s, g.App)
+	if err != nil {
+		return err
+	}
+
+	defer done()
+
+	if err = kind.remove(ctx, client, g); err != nil {
+		return err
+	}
+
+	b.Logger().Info("removed "+kind.desc, g.logArgs()...)
+
+	if g.ID == "" {
+		return nil
+	}
+
+	return s.Delete(ctx, kind.prefix+g.ID)
+}
+
+// sweepGrants removes the access of expired grants of the kind, which are left
+// over should their lease revocation have failed or been lost. Failures are
+// logged and retried on the next sweep.
+func (b *backend) sweepGrants(ctx context.Context, s logical.Storage, kind *grantKind) error {
+	ids, err := s.List(ctx, kind.prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		entry, err := s.Get(ctx, kind.prefix+id)
+		if err != nil {
+			return err
+		}
+
+		if entry == nil {
+			continue
+		}
+
+		var g grant
+		if err = entry.DecodeJSON(&g); err != nil {
+			return err
+		}
+
+		if time.Now().Before(g.ExpiresAt) {
+			continue
+		}
+
+		if err = b.removeGrant(ctx, s, kind, &g); err != nil {
+			b.Logger().Warn("unable to remove expired "+kind.desc, append(g.logArgs(), "err", err)...)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package github
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestGrant_InternalData(t *testing.T) {
+	t.Parallel()
+
+	for _, g := range []*grant{
+		{
+			ID:             "abc",
+			App:            "admin",
+			OrgName:        testOrgName1,
+			Team:           "admins",
+			Username:       "octocat",
+			InstallationID: testInsID1,
+		},
+		{
+			ID:             "def",
+			App:            "admin",
+			OrgName:        testOrgName1,
+			Repository:     "website",
+			Username:       "octocat",
+			InstallationID: testInsID1,
+			InvitationID:   7,
+		},
+	} {
+		assert.DeepEqual(t, grantFromInternalData(g.internalData()), g)
+	}
+}
@@ -0,0 +1,144 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const (
+	errUnableToBuildInstallationReq  = Error("unable to build installation request")
+	errUnableToDecodeInstallationAPI = Error("unable to decode installation API response")
+)
+
+// apiError is an unsuccessful response of the GitHub API.
+type apiError struct {
+	Status     string
+	Body       string
+	StatusCode int
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Status, e.Body)
+}
+
+// installationSession performs requests to the GitHub API as an installation
+// with a single internal token.
+type installationSession struct {
+	client         *Client
+	token          string
+	installationID int
+}
+
+// withInstallation calls fn with a session performing requests to the GitHub
+// API as the installation of the token request. It mints a short-lived
+// internal token constrained by the token request to do so, which is revoked
+// once fn returns.
+func (c *Client) withInstallation(
+	ctx context.Context,
+	tokReq *tokenRequest,
+	fn func(s *installationSession) error,
+) error {
+	tokRes, err := c.token(ctx, tokReq)
+	if err != nil {
+		return err
+	}
+
+	token, _ := tokRes.Data["token"].(string)
+
+	defer func() {
+		if _, err := c.revokeToken(ctx, token, tokReq.InstallationID); err != nil {
+			// NOTE: The token expires within the hour regardless.
+			c.logger.Warn("unable to revoke internal token",
+				keyInstallationID, tokReq.InstallationID,
+				"err", err,
+			)
+		}
+	}()
+
+	return fn(&installationSession{client: c, token: token, installationID: tokReq.InstallationID})
+}
+
+// installationRequest performs a single request to the GitHub API as the
+// installation of the token request, as a session of withInstallation does.
+func (c *Client) installationRequest(
+	ctx context.Context,
+	tokReq *tokenRequest,
+	method, path string,
+	in, out any,
+) error {
+	return c.withInstallation(ctx, tokReq, func(s *installationSession) error {
+		return s.request(ctx, method, path, in, out)
+	})
+}
+
+// request performs a request to the GitHub API at the given path, which may
+// carry a query, with the token of the session. The request body, if any, is
+// encoded from in and the response body, if any, decoded into out.
+func (s *installationSession) request(ctx context.Context, method, path string, in, out any) error {
+	c := s.client
+
+	var body io.Reader
+
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("%s: %w", errUnableToBuildInstallationReq, err)
+		}
+
+		body = bytes.NewReader(b)
+	}
+
+	ref, err := url.Parse(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errUnableToBuildInstallationReq, err)
+	}
+
+	u := c.baseURL.ResolveReference(ref)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errUnableToBuildInstallationReq, err)
+	}
+
+	req.Header.Set("User-Agent", projectName)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.token))
+
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Perform the request as the installation, re-using the shared transport.
+	res, err := c.do(req, c.revocationClient.Do)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close() //nolint:errcheck
+
+	c.recordRateLimit(s.installationID, res.Header)
+
+	if statusCode(res.StatusCode).Unsuccessful() {
+		var bodyBytes []byte
+
+		if bodyBytes, err = io.ReadAll(res.Body); err != nil {
+			return err
+		}
+
+		return &apiError{Status: res.Status, Body: string(bodyBytes), StatusCode: res.StatusCode}
+	}
+
+	if out == nil || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err = json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s: %w", errUnableToDecodeInstallationAPI, err)
+	}
+
+	return nil
+}
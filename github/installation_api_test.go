@@ -0,0 +1,91 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestClient_InstallationRequest(t *testing.T) {
+	t.Parallel()
+
+	var revoked atomic.Int32
+
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			t.Helper()
+
+			switch r.URL.Path {
+			case fmt.Sprintf("/app/installations/%d/access_tokens", testInsID1):
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"token":"` + testToken + `"}`))
+			case "/installation/token":
+				revoked.Add(1)
+				w.WriteHeader(http.StatusNoContent)
+			case "/echo":
+				assert.Equal(t, r.Header.Get("Authorization"), "Bearer "+testToken)
+				assert.Equal(t, r.Header.Get("Content-Type"), "application/json")
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"echoed":true}`))
+			case "/query":
+				fmt.Fprintf(w, `{"echoed":%t}`, r.URL.Query().Get("hello") == "world")
+			case "/missing":
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"message":"Not Found"}`))
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}),
+	)
+	defer ts.Close()
+
+	config := NewConfig()
+	config.AppID = testAppID1
+	config.PrvKey = testPrvKeyValid
+	config.BaseURL = ts.URL
+
+	client, err := NewClient(config)
+	assert.NilError(t, err)
+
+	ctx := context.Background()
+	tokReq := &tokenRequest{InstallationID: testInsID1}
+
+	var out struct {
+		Echoed bool `json:"echoed"`
+	}
+
+	assert.NilError(t, client.installationRequest(ctx, tokReq, http.MethodPost, "echo",
+		map[string]string{"hello": "world"}, &out))
+	assert.Assert(t, out.Echoed)
+	assert.Equal(t, revoked.Load(), int32(1))
+
+	out.Echoed = false
+	assert.NilError(t, client.installationRequest(ctx, tokReq, http.MethodGet, "query?hello=world", nil, &out))
+	assert.Assert(t, out.Echoed)
+
+	err = client.installationRequest(ctx, tokReq, http.MethodGet, "missing", nil, nil)
+	assert.ErrorContains(t, err, "404 Not Found")
+
+	var apiErr *apiError
+	assert.Assert(t, errors.As(err, &apiErr))
+	assert.Equal(t, apiErr.StatusCode, http.StatusNotFound)
+	assert.Equal(t, revoked.Load(), int32(3))
+
+	// Sessions perform several requests with a single internal token.
+	assert.NilError(t, client.withInstallation(ctx, tokReq, func(s *installationSession) error {
+		for range 2 {
+			if err := s.request(ctx, http.MethodGet, "query?hello=world", nil, &out); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}))
+	assert.Equal(t, revoked.Load(), int32(4))
+}
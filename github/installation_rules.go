@@ -0,0 +1,95 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	errInvalidPattern         = Error("invalid pattern")
+	errInstallationNotAllowed = Error("installation not allowed")
+	errAdhocTokensDisabled    = Error("ad-hoc tokens are disabled, request tokens from a permission set")
+)
+
+// validatePatterns checks that the given patterns are well formed globs.
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%w %q: %w", errInvalidPattern, pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// matchPattern returns the first of the glob patterns that matches the name,
+// case-insensitively.
+func matchPattern(patterns []string, name string) (string, bool) {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(name)); ok {
+			return pattern, true
+		}
+	}
+
+	return "", false
+}
+
+// hasInstallationRules reports whether the installations that tokens can be
+// requested for are restricted.
+func (c *Config) hasInstallationRules() bool {
+	return len(c.AllowedOrgNames) > 0 || len(c.DeniedOrgNames) > 0 ||
+		len(c.AllowedInstallationIDs) > 0 || len(c.DeniedInstallationIDs) > 0
+}
+
+// checkInstallationRules checks the installation with the given organization
+// name and ID against the configured allowed and denied patterns. Denied
+// patterns take precedence, and an installation must match allowed patterns
+// when there are any.
+func (c *Config) checkInstallationRules(orgName string, installationID int) error {
+	for _, rule := range []struct {
+		allowed, denied []string
+		key, name       string
+	}{
+		{c.AllowedOrgNames, c.DeniedOrgNames, keyOrgName, orgName},
+		{c.AllowedInstallationIDs, c.DeniedInstallationIDs, keyInstallationID, strconv.Itoa(installationID)},
+	} {
+		if pattern, ok := matchPattern(rule.denied, rule.name); ok {
+			return fmt.Errorf("%w: %s %q matches denied pattern %q",
+				errInstallationNotAllowed, rule.key, rule.name, pattern)
+		}
+
+		if _, ok := matchPattern(rule.allowed, rule.name); !ok && len(rule.allowed) > 0 {
+			return fmt.Errorf("%w: %s %q matches no allowed pattern",
+				errInstallationNotAllowed, rule.key, rule.name)
+		}
+	}
+
+	return nil
+}
+
+// CheckInstallationAllowed checks that tokens can be requested for the
+// installation of the token request. Its organization name is looked up from
+// its installation ID, or vice versa, so that neither can be used to bypass a
+// rule on the other.
+func (c *Client) CheckInstallationAllowed(ctx context.Context, tokReq *tokenRequest) error {
+	if !c.hasInstallationRules() {
+		return nil
+	}
+
+	installationID, orgName, err := c.installationIdentity(ctx, tokReq)
+	if err != nil {
+		return err
+	}
+
+	if err = c.checkInstallationRules(orgName, installationID); err != nil {
+		return logical.CodedError(http.StatusForbidden, err.Error())
+	}
+
+	return nil
+}
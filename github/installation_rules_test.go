@@ -0,0 +1,293 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/assert"
+)
+
+func TestValidatePatterns(t *testing.T) {
+	t.Parallel()
+
+	assert.NilError(t, validatePatterns([]string{"partner-*", "12?4", "[a-c]*"}))
+	assert.ErrorContains(t, validatePatterns([]string{"ok", "[a-"}), errInvalidPattern.Error()+` "[a-"`)
+}
+
+func TestConfig_CheckInstallationRules(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		config *Config
+		name   string
+		err    string
+	}{
+		{
+			name:   "NoRules",
+			config: &Config{},
+		},
+		{
+			name:   "Allowed",
+			config: &Config{AllowedOrgNames: []string{"other", "TEST-*"}},
+		},
+		{
+			name:   "NotAllowed",
+			config: &Config{AllowedOrgNames: []string{"other"}},
+			err:    `org_name "test-1" matches no allowed pattern`,
+		},
+		{
+			name: "DeniedWins",
+			config: &Config{
+				AllowedOrgNames: []string{"*"},
+				DeniedOrgNames:  []string{"test-?"},
+			},
+			err: `org_name "test-1" matches denied pattern "test-?"`,
+		},
+		{
+			name:   "InstallationIDDenied",
+			config: &Config{DeniedInstallationIDs: []string{strconv.Itoa(testInsID1)[:2] + "*"}},
+			err:    `installation_id "` + strconv.Itoa(testInsID1) + `" matches denied pattern`,
+		},
+		{
+			name:   "InstallationIDNotAllowed",
+			config: &Config{AllowedInstallationIDs: []string{"0"}},
+			err:    `installation_id "` + strconv.Itoa(testInsID1) + `" matches no allowed pattern`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.config.checkInstallationRules(testOrgName1, testInsID1)
+			if tc.err == "" {
+				assert.NilError(t, err)
+
+				return
+			}
+
+			assert.ErrorContains(t, err, errInstallationNotAllowed.Error()+": "+tc.err)
+		})
+	}
+}
+
+func TestBackend_InstallationRules(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		config map[string]any
+		data   map[string]any
+		name   string
+		path   string
+		err    string
+		code   int
+	}{
+		{
+			name:   "AllowedByOrgName",
+			path:   pathPatternToken,
+			config: map[string]any{keyAllowedOrgNames: "test-*"},
+			data:   map[string]any{keyOrgName: testOrgName1},
+		},
+		{
+			// The organization name is looked up from the installation ID.
+			name:   "DeniedByInstallationID",
+			path:   pathPatternToken,
+			config: map[string]any{keyDeniedOrgNames: testOrgName1},
+			data:   map[string]any{keyInstallationID: testInsID1},
+			err:    errInstallationNotAllowed.Error(),
+			code:   http.StatusForbidden,
+		},
+		{
+			// The installation ID takes precedence over the organization name.
+			name:   "DeniedByMismatchedOrgName",
+			path:   pathPatternToken,
+			config: map[string]any{keyAllowedOrgNames: "other"},
+			data:   map[string]any{keyInstallationID: testInsID1, keyOrgName: "other"},
+			err:    errInstallationNotAllowed.Error(),
+			code:   http.StatusForbidden,
+		},
+		{
+			name:   "DeniedByInstallationIDPattern",
+			path:   pathPatternToken,
+			config: map[string]any{keyDeniedInstallationIDs: strconv.Itoa(testInsID1)},
+			data:   map[string]any{keyOrgName: testOrgName1},
+			err:    errInstallationNotAllowed.Error(),
+			code:   http.StatusForbidden,
+		},
+		{
+			name:   "PermissionSetDenied",
+			path:   pathPatternPermissionSet + "/foo",
+			config: map[string]any{keyDeniedOrgNames: "test-*"},
+			data:   map[string]any{keyInstallationID: testInsID1},
+			err:    errInstallationNotAllowed.Error(),
+			code:   http.StatusForbidden,
+		},
+		{
+			name:   "AdhocTokensDisabled",
+			path:   pathPatternToken,
+			config: map[string]any{keyDisableAdhocTokens: true},
+			data:   map[string]any{keyInstallationID: testInsID1},
+			err:    errAdhocTokensDisabled.Error(),
+			code:   http.StatusForbidden,
+		},
+		{
+			name:   "InvalidPattern",
+			path:   pathPatternConfig,
+			config: map[string]any{},
+			data:   map[string]any{keyDeniedOrgNames: "[a-"},
+			err:    errInvalidPattern.Error(),
+			code:   http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b, storage := testBackend(t)
+
+			ts := testRepositoriesServer(t, &testRepositoriesRecorder{}, http.StatusOK)
+			defer ts.Close()
+
+			config := map[string]any{
+				keyAppID:   testAppID1,
+				keyPrvKey:  testPrvKeyValid,
+				keyBaseURL: ts.URL,
+			}
+			maps.Copy(config, tc.config)
+
+			_, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      pathPatternConfig,
+				Data:      config,
+			})
+			assert.NilError(t, err)
+
+			_, err = b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      tc.path,
+				Data:      tc.data,
+			})
+
+			if tc.err == "" {
+				assert.NilError(t, err)
+
+				return
+			}
+
+			assert.ErrorContains(t, err, tc.err)
+
+			var coded logical.HTTPCodedError
+			assert.Assert(t, errors.As(err, &coded))
+			assert.Equal(t, coded.Code(), tc.code)
+		})
+	}
+}
+
+func TestBackend_InstallationRulesPermissionSetToken(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := testBackend(t)
+
+	ts := testRepositoriesServer(t, &testRepositoriesRecorder{}, http.StatusOK)
+	defer ts.Close()
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			keyAppID:              testAppID1,
+			keyPrvKey:             testPrvKeyValid,
+			keyBaseURL:            ts.URL,
+			keyDisableAdhocTokens: true,
+		},
+	})
+	assert.NilError(t, err)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternPermissionSet + "/foo",
+		Data:      map[string]any{keyOrgName: testOrgName1},
+	})
+	assert.NilError(t, err)
+
+	// Permission sets still work with ad-hoc tokens disabled.
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternToken + "/foo",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, r.Data["token"], testToken)
+
+	// Rules configured afterwards still apply to the permission set.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternConfig,
+		Data:      map[string]any{keyDeniedOrgNames: testOrgName1},
+	})
+	assert.NilError(t, err)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternToken + "/foo",
+	})
+	assert.ErrorContains(t, err, errInstallationNotAllowed.Error())
+}
+
+func TestBackend_InstallationRulesResolveRepositories(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := testBackend(t)
+
+	var rec testRepositoriesRecorder
+
+	ts := testRepositoriesServer(t, &rec, http.StatusOK)
+	defer ts.Close()
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			keyAppID:          testAppID1,
+			keyPrvKey:         testPrvKeyValid,
+			keyBaseURL:        ts.URL,
+			keyDeniedOrgNames: testOrgName1,
+		},
+	})
+	assert.NilError(t, err)
+
+	// Denied installations are refused before their repositories are
+	// resolved, which would mint a token.
+	for _, req := range []*logical.Request{
+		{Operation: logical.UpdateOperation, Path: pathPatternToken},
+		{Operation: logical.CreateOperation, Path: pathPatternPermissionSet + "/foo"},
+	} {
+		req.Storage = storage
+		req.Data = map[string]any{
+			keyOrgName:      testOrgName1,
+			keyRepos:        []string{"public"},
+			keyResolveRepos: true,
+		}
+
+		_, err = b.HandleRequest(ctx, req)
+		assert.ErrorContains(t, err, errInstallationNotAllowed.Error())
+	}
+
+	assert.Equal(t, len(rec.minted()), 0)
+}
@@ -0,0 +1,309 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// defaultInstallationCacheTTL is how long a snapshot of the installations
+	// of the App is used to look up installation IDs when not configured.
+	defaultInstallationCacheTTL = 5 * time.Minute
+
+	// defaultInstallationCacheNegativeTTL is how long an organization is
+	// considered not to have the App installed when not configured.
+	defaultInstallationCacheNegativeTTL = time.Minute
+)
+
+// installationCacheLookups counts installation ID lookups by whether they were
+// answered by the cache.
+var installationCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: fmt.Sprintf("%s_installation_cache_lookups_total", prefixMetrics),
+	Help: "Total installation ID lookups by organization name, by cache result.",
+}, []string{"result"})
+
+// installationCacheTTL returns the effective installation cache TTL. A zero
+// TTL disables the cache.
+func (c *Config) installationCacheTTL() time.Duration {
+	if c.InstallationCacheTTL != nil {
+		return *c.InstallationCacheTTL
+	}
+
+	return defaultInstallationCacheTTL
+}
+
+// installationCacheNegativeTTL returns the effective installation cache TTL
+// of organizations that do not have the App installed. It never exceeds the
+// installation cache TTL.
+func (c *Config) installationCacheNegativeTTL() time.Duration {
+	ttl := defaultInstallationCacheNegativeTTL
+	if c.InstallationCacheNegativeTTL != nil {
+		ttl = *c.InstallationCacheNegativeTTL
+	}
+
+	return min(ttl, c.installationCacheTTL())
+}
+
+// installationsSnapshot is a point in time mapping of the organizations (and
+// users) the App is installed on to their installation IDs.
+type installationsSnapshot struct {
+	// Installations maps lower-cased account logins to installation IDs.
+	Installations map[string]int `json:"installations"`
+	FetchedAt     time.Time      `json:"fetched_at"`
+}
+
+// newInstallationsSnapshot returns a snapshot of the given installations.
+func newInstallationsSnapshot(installations []installation) *installationsSnapshot {
+	snapshot := &installationsSnapshot{
+		Installations: make(map[string]int, len(installations)),
+		FetchedAt:     time.Now(),
+	}
+
+	for _, v := range installations {
+		snapshot.Installations[strings.ToLower(v.Account.Login)] = v.ID
+	}
+
+	return snapshot
+}
+
+// installationsCache caches the latest snapshot of the installations of the
+// App, coalescing concurrent reloads into one.
+type installationsCache struct {
+	snapshot *installationsSnapshot
+	inflight *installationsReload
+	// granted caches the permissions granted to installations, by ID.
+	granted map[int]grantedPermissions
+	mu      sync.Mutex
+	// unsaved is set while the snapshot was reloaded but not yet persisted.
+	unsaved bool
+}
+
+// grantedPermissions are the permissions granted to an installation as
+// fetched at a point in time.
+type grantedPermissions struct {
+	fetchedAt   time.Time
+	permissions map[string]string
+}
+
+// installationsReload is a reload of the installations snapshot in flight.
+type installationsReload struct {
+	done     chan struct{}
+	snapshot *installationsSnapshot
+	err      error
+}
+
+// currentInstallations returns the cached snapshot, if any.
+func (c *Client) currentInstallations() *installationsSnapshot {
+	c.installations.mu.Lock()
+	defer c.installations.mu.Unlock()
+
+	return c.installations.snapshot
+}
+
+// setInstallations caches the given snapshot, which is then unsaved. The
+// permissions granted to installations are refetched on demand.
+func (c *Client) setInstallations(snapshot *installationsSnapshot) {
+	c.installations.mu.Lock()
+	defer c.installations.mu.Unlock()
+
+	c.installations.snapshot = snapshot
+	c.installations.granted = nil
+	c.installations.unsaved = true
+}
+
+// restoreInstallations caches the given persisted snapshot unless a snapshot
+// was cached already.
+func (c *Client) restoreInstallations(snapshot *installationsSnapshot) {
+	c.installations.mu.Lock()
+	defer c.installations.mu.Unlock()
+
+	if c.installations.snapshot == nil {
+		c.installations.snapshot = snapshot
+	}
+}
+
+// unsavedInstallations returns the cached snapshot if it is unsaved, marking
+// it saved, or nil otherwise.
+func (c *Client) unsavedInstallations() *installationsSnapshot {
+	c.installations.mu.Lock()
+	defer c.installations.mu.Unlock()
+
+	if !c.installations.unsaved {
+		return nil
+	}
+
+	c.installations.unsaved = false
+
+	return c.installations.snapshot
+}
+
+// RefreshInstallations reloads the installations of the App from GitHub into
+// the cache. Concurrent reloads are coalesced into a single request.
+//
+// NOTE: Coalesced callers share the outcome of the first caller, including a
+// cancellation of its context.
+func (c *Client) RefreshInstallations(ctx context.Context) (*installationsSnapshot, error) {
+	cache := c.installations
+
+	cache.mu.Lock()
+
+	if reload := cache.inflight; reload != nil {
+		cache.mu.Unlock()
+
+		select {
+		case <-reload.done:
+			return reload.snapshot, reload.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	reload := &installationsReload{done: make(chan struct{})}
+	cache.inflight = reload
+	cache.mu.Unlock()
+
+	defer func() {
+		cache.mu.Lock()
+		cache.inflight = nil
+		cache.mu.Unlock()
+		close(reload.done)
+	}()
+
+	installations, err := c.fetchInstallations(ctx)
+	if err != nil {
+		reload.err = err
+
+		return nil, err
+	}
+
+	reload.snapshot = newInstallationsSnapshot(installations)
+	c.setInstallations(reload.snapshot)
+
+	return reload.snapshot, nil
+}
+
+// cachedInstallationID looks up the installation ID of the organization in the
+// installations cache, reloading it when stale.
+func (c *Client) cachedInstallationID(ctx context.Context, orgName string) (int, error) {
+	orgName = strings.ToLower(orgName)
+
+	if snapshot := c.currentInstallations(); snapshot != nil {
+		age := time.Since(snapshot.FetchedAt)
+
+		id, ok := snapshot.Installations[orgName]
+		if ok && age < c.installationCacheTTL() {
+			installationCacheLookups.With(prometheus.Labels{"result": "hit"}).Inc()
+
+			return id, nil
+		}
+
+		if !ok && age < c.installationCacheNegativeTTL() {
+			installationCacheLookups.With(prometheus.Labels{"result": "negative_hit"}).Inc()
+
+			return 0, errAppNotInstalled
+		}
+	}
+
+	installationCacheLookups.With(prometheus.Labels{"result": "miss"}).Inc()
+
+	snapshot, err := c.RefreshInstallations(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if id, ok := snapshot.Installations[orgName]; ok {
+		return id, nil
+	}
+
+	return 0, errAppNotInstalled
+}
+
+// installationsCacheKey returns the storage key of the persisted installations
+// cache of the named app.
+func installationsCacheKey(app string) string {
+	return fmt.Sprintf("%s/cache/%s", pathPatternInstallations, configKey(app))
+}
+
+// loadInstallations returns the installations snapshot of the named app
+// persisted to storage, if any.
+func loadInstallations(ctx context.Context, s logical.Storage, app string) (*installationsSnapshot, error) {
+	entry, err := s.Get(ctx, installationsCacheKey(app))
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	var snapshot installationsSnapshot
+	if err = entry.DecodeJSON(&snapshot); err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// saveInstallations persists the installations cache of the client of the
+// named app to the given storage, i.e. that of the request using the client,
+// should it have been reloaded since last saved. Performance standbys cannot
+// write to storage and leave persisting to the active node.
+func (b *backend) saveInstallations(ctx context.Context, s logical.Storage, app string, client *Client) {
+	if !client.InstallationCacheStorage ||
+		b.System().ReplicationState().HasState(consts.ReplicationPerformanceStandby) {
+		return
+	}
+
+	snapshot := client.unsavedInstallations()
+	if snapshot == nil {
+		return
+	}
+
+	entry, err := logical.StorageEntryJSON(installationsCacheKey(app), snapshot)
+	if err == nil {
+		err = s.Put(ctx, entry)
+	}
+
+	if err != nil {
+		b.Logger().Warn("unable to persist cached installations", "app", app, "err", err)
+	}
+}
+
+// installationOrgName returns the (lower-cased) name of the organization the
+// installation is on, from the installations cache if fresh enough.
+func (c *Client) installationOrgName(ctx context.Context, installationID int) (string, error) {
+	snapshot := c.currentInstallations()
+	if snapshot == nil || time.Since(snapshot.FetchedAt) >= c.installationCacheTTL() {
+		var err error
+		if snapshot, err = c.RefreshInstallations(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	for orgName, id := range snapshot.Installations {
+		if id == installationID {
+			return orgName, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %d", errInstallationNotFound, installationID)
+}
+
+// installationIdentity returns the installation ID and (lower-cased)
+// organization name of the token request. The ID takes precedence, as it does
+// when requesting tokens, so the organization name is always looked up from it
+// when requested, and the ID looked up from the name otherwise.
+func (c *Client) installationIdentity(ctx context.Context, tokReq *tokenRequest) (int, string, error) {
+	if tokReq.InstallationID == 0 {
+		installationID, err := c.installationID(ctx, tokReq.OrgName)
+
+		return installationID, strings.ToLower(tokReq.OrgName), err
+	}
+
+	orgName, err := c.installationOrgName(ctx, tokReq.InstallationID)
+
+	return tokReq.InstallationID, orgName, err
+}
@@ -0,0 +1,329 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/assert"
+)
+
+// testInstallationsServer stubs the GitHub installations API, responding with
+// an installation of testOrgName1 and counting the requests it receives. If
+// release is non-nil, responses are held until it is closed.
+func testInstallationsServer(t *testing.T, calls *atomic.Int32, release chan struct{}) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			t.Helper()
+
+			assert.Equal(t, r.URL.Path, "/app/installations")
+			calls.Add(1)
+
+			if release != nil {
+				<-release
+			}
+
+			body, _ := json.Marshal([]map[string]any{{
+				"id":      testInsID1,
+				"account": map[string]any{"login": testOrgName1},
+			}})
+			w.Write(body)
+		}),
+	)
+}
+
+func TestClient_InstallationIDCache(t *testing.T) {
+	t.Parallel()
+
+	zero, ns := time.Duration(0), time.Nanosecond
+
+	cases := []struct {
+		ttl         *time.Duration
+		negativeTTL *time.Duration
+		name        string
+		orgs        []string
+		expCalls    int32
+		err         bool
+	}{
+		{
+			name:     "Hit",
+			orgs:     []string{testOrgName1, strings.ToUpper(testOrgName1)},
+			expCalls: 1,
+		},
+		{
+			name:     "Expired",
+			ttl:      &ns,
+			orgs:     []string{testOrgName1, testOrgName1},
+			expCalls: 2,
+		},
+		{
+			name:     "Disabled",
+			ttl:      &zero,
+			orgs:     []string{testOrgName1, testOrgName1},
+			expCalls: 2,
+		},
+		{
+			name:     "NegativeHit",
+			orgs:     []string{testOrgName2, testOrgName2},
+			expCalls: 1,
+			err:      true,
+		},
+		{
+			name:        "NegativeDisabled",
+			negativeTTL: &zero,
+			orgs:        []string{testOrgName2, testOrgName2},
+			expCalls:    2,
+			err:         true,
+		},
+		{
+			name:     "NegativeFromPositiveSnapshot",
+			orgs:     []string{testOrgName1, testOrgName2},
+			expCalls: 1,
+			err:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+
+			ts := testInstallationsServer(t, &calls, nil)
+			defer ts.Close()
+
+			client, err := NewClient(&Config{
+				AppID:                        testAppID1,
+				PrvKey:                       testPrvKeyValid,
+				BaseURL:                      ts.URL,
+				InstallationCacheTTL:         tc.ttl,
+				InstallationCacheNegativeTTL: tc.negativeTTL,
+			})
+			assert.NilError(t, err)
+
+			for _, org := range tc.orgs {
+				id, err := client.installationID(context.Background(), org)
+				if strings.EqualFold(org, testOrgName1) {
+					assert.NilError(t, err)
+					assert.Equal(t, id, testInsID1)
+				} else if tc.err {
+					assert.ErrorContains(t, err, errAppNotInstalled.Error())
+				}
+			}
+
+			assert.Equal(t, calls.Load(), tc.expCalls)
+		})
+	}
+}
+
+func TestClient_InstallationIDCacheMetrics(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	ts := testInstallationsServer(t, &calls, nil)
+	defer ts.Close()
+
+	client, err := NewClient(&Config{
+		AppID:   testAppID1,
+		PrvKey:  testPrvKeyValid,
+		BaseURL: ts.URL,
+	})
+	assert.NilError(t, err)
+
+	hits := testutil.ToFloat64(installationCacheLookups.WithLabelValues("hit"))
+	negativeHits := testutil.ToFloat64(installationCacheLookups.WithLabelValues("negative_hit"))
+	misses := testutil.ToFloat64(installationCacheLookups.WithLabelValues("miss"))
+
+	ctx := context.Background()
+
+	_, err = client.installationID(ctx, testOrgName1)
+	assert.NilError(t, err)
+	_, err = client.installationID(ctx, testOrgName1)
+	assert.NilError(t, err)
+	_, err = client.installationID(ctx, testOrgName2)
+	assert.ErrorContains(t, err, errAppNotInstalled.Error())
+
+	// Other tests may look up installations concurrently.
+	assert.Assert(t, testutil.ToFloat64(installationCacheLookups.WithLabelValues("hit")) >= hits+1)
+	assert.Assert(t, testutil.ToFloat64(installationCacheLookups.WithLabelValues("negative_hit")) >= negativeHits+1)
+	assert.Assert(t, testutil.ToFloat64(installationCacheLookups.WithLabelValues("miss")) >= misses+1)
+}
+
+func TestClient_InstallationIDCacheSingleFlight(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	release := make(chan struct{})
+
+	ts := testInstallationsServer(t, &calls, release)
+	defer ts.Close()
+
+	client, err := NewClient(&Config{
+		AppID:   testAppID1,
+		PrvKey:  testPrvKeyValid,
+		BaseURL: ts.URL,
+	})
+	assert.NilError(t, err)
+
+	const lookups = 20
+
+	var wg sync.WaitGroup
+
+	ids := make([]int, lookups)
+	errs := make([]error, lookups)
+
+	for i := range lookups {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			ids[i], errs[i] = client.installationID(context.Background(), testOrgName1)
+		}()
+	}
+
+	// Hold the first reload until the remaining lookups have joined it.
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i := range lookups {
+		assert.NilError(t, errs[i])
+		assert.Equal(t, ids[i], testInsID1)
+	}
+
+	assert.Equal(t, calls.Load(), int32(1))
+}
+
+func TestBackend_InstallationIDCacheStorage(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := testBackend(t)
+
+	var calls atomic.Int32
+
+	ts := testInstallationsServer(t, &calls, nil)
+	defer ts.Close()
+
+	writeConfig := func(data map[string]any) {
+		t.Helper()
+
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfig,
+			Data:      data,
+		})
+		assert.NilError(t, err)
+	}
+
+	lookup := func() {
+		t.Helper()
+
+		client, done, err := b.Client(ctx, storage, "")
+		assert.NilError(t, err)
+
+		defer done()
+
+		id, err := client.installationID(ctx, testOrgName1)
+		assert.NilError(t, err)
+		assert.Equal(t, id, testInsID1)
+	}
+
+	writeConfig(map[string]any{
+		keyAppID:                    testAppID1,
+		keyPrvKey:                   testPrvKeyValid,
+		keyBaseURL:                  ts.URL,
+		keyInstallationCacheStorage: true,
+	})
+
+	lookup()
+	assert.Equal(t, calls.Load(), int32(1))
+
+	entry, err := storage.Get(ctx, installationsCacheKey(""))
+	assert.NilError(t, err)
+	assert.Assert(t, entry != nil)
+
+	// A new client, e.g. after a plugin restart, starts from storage.
+	b.Invalidate(ctx, configKey(""))
+	lookup()
+	assert.Equal(t, calls.Load(), int32(1))
+
+	// A configuration change discards the cache.
+	writeConfig(map[string]any{keyInstallationCacheTTL: "10m"})
+
+	entry, err = storage.Get(ctx, installationsCacheKey(""))
+	assert.NilError(t, err)
+	assert.Assert(t, entry == nil)
+
+	lookup()
+	assert.Equal(t, calls.Load(), int32(2))
+
+	// Negative values are refused.
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternConfig,
+		Data:      map[string]any{keyInstallationCacheNegativeTTL: -1},
+	})
+	assert.Assert(t, err != nil || r.IsError())
+}
+
+func TestBackend_InstallationIDCacheStoragePerformanceStandby(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := testBackend(t)
+
+	var calls atomic.Int32
+
+	ts := testInstallationsServer(t, &calls, nil)
+	defer ts.Close()
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			keyAppID:                    testAppID1,
+			keyPrvKey:                   testPrvKeyValid,
+			keyBaseURL:                  ts.URL,
+			keyInstallationCacheStorage: true,
+		},
+	})
+	assert.NilError(t, err)
+
+	system := b.System().(*logical.StaticSystemView)
+	system.ReplicationStateVal = consts.ReplicationPerformanceStandby
+
+	client, done, err := b.Client(ctx, storage, "")
+	assert.NilError(t, err)
+
+	id, err := client.installationID(ctx, testOrgName1)
+	assert.NilError(t, err)
+	assert.Equal(t, id, testInsID1)
+
+	done()
+
+	// Performance standbys leave persisting the cache to the active node.
+	entry, err := storage.Get(ctx, installationsCacheKey(""))
+	assert.NilError(t, err)
+	assert.Assert(t, entry == nil)
+}
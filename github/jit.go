@@ -0,0 +1,18 @@
+package github
+
+import (
+	"context"
+)
+
+// jitGrantsStoragePrefix is the storage path of the records of just-in-time
+// team memberships.
+const jitGrantsStoragePrefix = pathPatternJIT + "/grants/"
+
+// jitGrants is the kind of grants of just-in-time team memberships.
+var jitGrants = &grantKind{
+	prefix: jitGrantsStoragePrefix,
+	desc:   "just-in-time team membership",
+	remove: func(ctx context.Context, client *Client, g *grant) error {
+		return client.RemoveTeamMembership(ctx, g.InstallationID, g.OrgName, g.Team, g.Username)
+	},
+}
@@ -0,0 +1,47 @@
+package github
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/assert"
+)
+
+func TestBackend_SweepJITGrants(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := newTestGitHubServer(t)
+	b, storage := testGitHubBackend(t, ts)
+
+	team := newTestTeam(t, ts, &testTeam{members: map[string]string{
+		"expired": teamRoleMember,
+		"current": teamRoleMember,
+	}})
+
+	// Grants whose lease revocations were lost, e.g. across a restart.
+	for username, expiresAt := range map[string]time.Time{
+		"expired": time.Now().Add(-time.Minute),
+		"current": time.Now().Add(time.Hour),
+	} {
+		assert.NilError(t, (&grant{
+			ExpiresAt:      expiresAt,
+			ID:             username,
+			OrgName:        testOrgName1,
+			Team:           "admins",
+			Username:       username,
+			InstallationID: testInsID1,
+		}).save(ctx, storage, jitGrants))
+	}
+
+	assert.NilError(t, b.periodicFunc(ctx, &logical.Request{Storage: storage}))
+
+	assert.Equal(t, team.role("expired"), "")
+	assert.Equal(t, team.role("current"), teamRoleMember)
+
+	grants, err := storage.List(ctx, jitGrantsStoragePrefix)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, grants, []string{"current"})
+}
@@ -0,0 +1,446 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// pathPatternCollaborator is the string used to define the base path of the
+// temporary repository collaborator endpoints.
+const pathPatternCollaborator = "collaborator"
+
+// pathPatternCollaboratorRoles is the string used to define the base path of
+// the collaborator role endpoints as well as the storage path of the roles.
+const pathPatternCollaboratorRoles = pathPatternCollaborator + "/roles"
+
+// collaboratorSecretType is the type of the Vault leases of temporary
+// repository collaborators.
+const collaboratorSecretType = "github_repository_collaborator"
+
+const (
+	keyPermission   = "permission"
+	descPermission  = "The permission of users on the repository, pull, push or maintain."
+	keyInvitationID = "invitation_id"
+)
+
+const (
+	errCollaboratorRoleNameEmpty   = Error("collaborator role name empty")
+	errUnableToGetCollaboratorRole = Error("unable to get collaborator role")
+	errAlreadyCollaborator         = Error("user is already a collaborator on the repository")
+	errAlreadyInvited              = Error("user already has a pending invitation to the repository")
+)
+
+const (
+	pathCollaboratorRoleHelpSyn  = `Read/write roles for temporary GitHub repository collaborators.`
+	pathCollaboratorRoleHelpDesc = `
+This path allows you to create roles that grant temporary repository access at
+'collaborator/<role>'. A role pins the repository that users are added to as
+collaborators and their permission on it, and how the requesting Vault entity
+maps to a GitHub user. The following is a sample payload:
+
+{
+	"org_name": "acme",
+	"repository": "website",
+	"permission": "push",
+	"username_metadata_key": "github_username",
+	"ttl": 14400
+}
+`
+	pathListCollaboratorRolesHelpSyn  = `List existing collaborator roles.`
+	pathListCollaboratorRolesHelpDesc = `List created collaborator roles.`
+	pathCollaboratorHelpSyn           = `Grant temporary repository access from a role.`
+	pathCollaboratorHelpDesc          = `
+This path adds the GitHub user of the requesting Vault entity as a collaborator
+on the repository of the role and returns a Vault lease. The collaborator is
+removed when the lease is revoked or expires. A durable record of the
+collaborator is kept so that it is removed by a periodic sweep should the
+revocation of its lease fail.
+
+Outside collaborators are invited to the repository and get access once they
+accept the invitation, which is deleted when the lease ends. Users that are
+already direct collaborators on the repository, or have a pending invitation to
+it, are refused, so that their access or invitation is not removed when the
+lease ends.
+
+The GitHub user name is mapped as for 'jit/<role>'. The App needs the
+'administration' write permission on the repository.
+`
+)
+
+// collaboratorRole models the stored role that temporary repository
+// collaborators are granted from.
+type collaboratorRole struct {
+	Name       string
+	OrgName    string
+	Repository string
+	Permission string
+	App        string `json:",omitempty"`
+	usernameMapping
+	InstallationID int `json:",omitempty"`
+	roleTTL
+}
+
+func (r *collaboratorRole) save(ctx context.Context, s logical.Storage) error {
+	if r.Name == "" {
+		return errCollaboratorRoleNameEmpty
+	}
+
+	return putRole(ctx, s, pathPatternCollaboratorRoles, r.Name, r)
+}
+
+func getCollaboratorRole(ctx context.Context, name string, s logical.Storage) (*collaboratorRole, error) {
+	return getRole[collaboratorRole](ctx, s, pathPatternCollaboratorRoles, name)
+}
+
+// pathCollaborator defines the /github/collaborator paths on the backend.
+func (b *backend) pathCollaborator() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: fmt.Sprintf("%s/%s", pathPatternCollaboratorRoles, framework.GenericNameRegex("name")),
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Required. Name of the collaborator role.",
+				},
+				keyOrgName: {
+					Type:        framework.TypeString,
+					Description: "Required. The organization (or user) that owns the repository.",
+				},
+				keyRepository: {
+					Type:        framework.TypeString,
+					Description: "Required. The name of the repository that users are added to as collaborators.",
+				},
+				keyPermission: {
+					Type:        framework.TypeString,
+					Description: descPermission,
+					Default:     collaboratorPermissionPull,
+					AllowedValues: []any{
+						collaboratorPermissionPull,
+						collaboratorPermissionPush,
+						collaboratorPermissionMaintain,
+					},
+				},
+				keyInstallationID: {
+					Type:        framework.TypeInt,
+					Description: "The ID of the App installation on the organization, saving a lookup.",
+				},
+				keyUsernameMetadataKey: {
+					Type:        framework.TypeString,
+					Description: descUsernameMetadataKey,
+					Default:     defaultUsernameMetadataKey,
+				},
+				keyUsernameAliasAccessor: {
+					Type:        framework.TypeString,
+					Description: descUsernameAliasAccess,
+				},
+				keyApp: {
+					Type:        framework.TypeString,
+					Description: descApp,
+				},
+				keyTTL: {
+					Type:        framework.TypeDurationSecond,
+					Description: "The TTL of the leases of collaborators. The collaborator is removed when its lease expires.",
+				},
+				keyMaxTTL: {
+					Type:        framework.TypeDurationSecond,
+					Description: "The maximum TTL of the leases of collaborators.",
+				},
+			},
+			ExistenceCheck: pathRoleExistenceCheck[collaboratorRole](pathPatternCollaboratorRoles),
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathCollaboratorRoleRead),
+				},
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathCollaboratorRoleWrite),
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathCollaboratorRoleWrite),
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: withFieldValidator(pathRoleDelete(pathPatternCollaboratorRoles, &b.collaboratorLock)),
+				},
+			},
+			HelpSynopsis:    pathCollaboratorRoleHelpSyn,
+			HelpDescription: pathCollaboratorRoleHelpDesc,
+		},
+		{
+			Pattern: fmt.Sprintf("%s/?", pathPatternCollaboratorRoles),
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: pathRoleList(pathPatternCollaboratorRoles),
+				},
+			},
+			HelpSynopsis:    pathListCollaboratorRolesHelpSyn,
+			HelpDescription: pathListCollaboratorRolesHelpDesc,
+		},
+		{
+			Pattern: fmt.Sprintf("%s/%s", pathPatternCollaborator, framework.GenericNameRegex("role")),
+			Fields: map[string]*framework.FieldSchema{
+				"role": {
+					Type:        framework.TypeString,
+					Description: "Required. Name of the collaborator role.",
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathCollaboratorWrite),
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathCollaboratorWrite),
+				},
+			},
+			HelpSynopsis:    pathCollaboratorHelpSyn,
+			HelpDescription: pathCollaboratorHelpDesc,
+		},
+	}
+}
+
+// collaboratorSecret defines the secret type of temporary repository
+// collaborators, which are removed when revoked.
+func (b *backend) collaboratorSecret() *framework.Secret {
+	return &framework.Secret{
+		Type: collaboratorSecretType,
+		Fields: map[string]*framework.FieldSchema{
+			keyUsername: {
+				Type:        framework.TypeString,
+				Description: "The GitHub user name added as a collaborator.",
+			},
+		},
+		Revoke: b.revokeCollaborator,
+	}
+}
+
+// pathCollaboratorRoleRead corresponds to READ on
+// /github/collaborator/roles/:name.
+func (b *backend) pathCollaboratorRoleRead(
+	ctx context.Context, req *logical.Request, d *framework.FieldData,
+) (*logical.Response, error) {
+	role, err := getCollaboratorRole(ctx, d.Get("name").(string), req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]any{
+			keyOrgName:               role.OrgName,
+			keyRepository:            role.Repository,
+			keyPermission:            role.Permission,
+			keyInstallationID:        role.InstallationID,
+			keyUsernameMetadataKey:   role.usernameMetadataKey(),
+			keyUsernameAliasAccessor: role.UsernameAliasAccessor,
+			keyApp:                   role.App,
+			keyTTL:                   int64(role.TTL.Seconds()),
+			keyMaxTTL:                int64(role.MaxTTL.Seconds()),
+		},
+	}, nil
+}
+
+// pathCollaboratorRoleWrite corresponds to CREATE and UPDATE on
+// /github/collaborator/roles/:name.
+func (b *backend) pathCollaboratorRoleWrite(
+	ctx context.Context, req *logical.Request, d *framework.FieldData,
+) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	b.collaboratorLock.Lock()
+	defer b.collaboratorLock.Unlock()
+
+	role, err := getCollaboratorRole(ctx, name, req.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", errUnableToGetCollaboratorRole, name, err)
+	}
+
+	if role == nil {
+		role = &collaboratorRole{Name: name, Permission: d.Get(keyPermission).(string)}
+	}
+
+	if orgName, ok := d.GetOk(keyOrgName); ok {
+		role.OrgName = orgName.(string)
+	}
+
+	if repo, ok := d.GetOk(keyRepository); ok {
+		role.Repository = repo.(string)
+	}
+
+	if permission, ok := d.GetOk(keyPermission); ok {
+		role.Permission = permission.(string)
+	}
+
+	if installationID, ok := d.GetOk(keyInstallationID); ok {
+		role.InstallationID = installationID.(int)
+	}
+
+	if key, ok := d.GetOk(keyUsernameMetadataKey); ok {
+		role.UsernameMetadataKey = key.(string)
+	}
+
+	if accessor, ok := d.GetOk(keyUsernameAliasAccessor); ok {
+		role.UsernameAliasAccessor = accessor.(string)
+	}
+
+	if app, ok := d.GetOk(keyApp); ok {
+		role.App = app.(string)
+	}
+
+	if err = role.roleTTL.update(d); err != nil {
+		return nil, logical.CodedError(http.StatusBadRequest, err.Error())
+	}
+
+	if role.OrgName == "" || role.Repository == "" {
+		return logical.ErrorResponse("%s and %s are required parameters", keyOrgName, keyRepository), nil
+	}
+
+	switch role.Permission {
+	case collaboratorPermissionPull, collaboratorPermissionPush, collaboratorPermissionMaintain:
+	default:
+		return nil, logical.CodedError(http.StatusBadRequest, fmt.Sprintf("%s must be %s, %s or %s",
+			keyPermission, collaboratorPermissionPull, collaboratorPermissionPush, collaboratorPermissionMaintain))
+	}
+
+	return nil, role.save(ctx, req.Storage)
+}
+
+// pathCollaboratorWrite corresponds to READ and UPDATE on
+// /github/collaborator/:role.
+func (b *backend) pathCollaboratorWrite(
+	ctx context.Context, req *logical.Request, d *framework.FieldData,
+) (*logical.Response, error) {
+	name := d.Get("role").(string)
+
+	role, err := getCollaboratorRole(ctx, name, req.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", errUnableToGetCollaboratorRole, name, err)
+	}
+
+	if role == nil {
+		return logical.ErrorResponse("collaborator role '%s' does not exist", name), nil
+	}
+
+	username, err := b.requestUsername(req, &role.usernameMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	client, done, err := b.Client(ctx, req.Storage, role.App)
+	if err != nil {
+		return nil, err
+	}
+
+	defer done()
+
+	tokReq := &tokenRequest{OrgName: role.OrgName, InstallationID: role.InstallationID, App: role.App}
+	if err = client.CheckInstallationAllowed(ctx, tokReq); err != nil {
+		return nil, err
+	}
+
+	if tokReq.InstallationID == 0 {
+		if tokReq.InstallationID, err = client.installationID(ctx, tokReq.OrgName); err != nil {
+			return nil, err
+		}
+	}
+
+	// Refuse existing direct collaborators and invitees, whose access or
+	// invitation would otherwise be removed when the lease ends.
+	direct, invited, err := client.ExistingCollaborator(
+		ctx, tokReq.InstallationID, role.OrgName, role.Repository, username,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if direct {
+		return nil, logical.CodedError(http.StatusConflict,
+			fmt.Sprintf("%s: %s on %s", errAlreadyCollaborator, username, role.Repository))
+	}
+
+	if invited {
+		return nil, logical.CodedError(http.StatusConflict,
+			fmt.Sprintf("%s: %s to %s", errAlreadyInvited, username, role.Repository))
+	}
+
+	id, err := newGrantID()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl, maxTTL := b.leaseTTLs(role.TTL, role.MaxTTL)
+
+	// Record the grant before the collaborator is added, so that it cannot be
+	// missed by the sweep.
+	g := &grant{
+		ExpiresAt:      time.Now().Add(ttl),
+		ID:             id,
+		Role:           name,
+		App:            role.App,
+		OrgName:        role.OrgName,
+		Repository:     role.Repository,
+		Username:       username,
+		InstallationID: tokReq.InstallationID,
+	}
+	if err = g.save(ctx, req.Storage, collaboratorGrants); err != nil {
+		return nil, err
+	}
+
+	invitation, err := client.AddCollaborator(
+		ctx, tokReq.InstallationID, role.OrgName, role.Repository, username, role.Permission,
+	)
+	if err != nil {
+		if delErr := req.Storage.Delete(ctx, collaboratorGrantsStoragePrefix+id); delErr != nil {
+			b.Logger().Warn("unable to delete collaborator grant record", keyGrantID, id, "err", delErr)
+		}
+
+		return nil, err
+	}
+
+	data := map[string]any{
+		keyOrgName:        role.OrgName,
+		keyRepository:     role.Repository,
+		keyPermission:     role.Permission,
+		keyUsername:       username,
+		keyInstallationID: tokReq.InstallationID,
+		"invited":         invitation != nil,
+	}
+
+	if invitation != nil {
+		// Keep the invitation, which is deleted when the lease ends should it
+		// not have been accepted.
+		g.InvitationID = invitation.ID
+		if err = g.save(ctx, req.Storage, collaboratorGrants); err != nil {
+			b.Logger().Warn("unable to update collaborator grant record", keyGrantID, id, "err", err)
+		}
+
+		data[keyInvitationID] = invitation.ID
+	}
+
+	b.Logger().Info("added temporary repository collaborator",
+		keyOrgName, role.OrgName,
+		keyRepository, role.Repository,
+		keyUsername, username,
+		keyPermission, role.Permission,
+		"entity_id", req.EntityID,
+	)
+
+	res := b.Secret(collaboratorSecretType).Response(data, g.internalData())
+	res.Secret.TTL = ttl
+	res.Secret.MaxTTL = maxTTL
+
+	return res, nil
+}
+
+// revokeCollaborator handles Vault lease revocations of temporary repository
+// collaborators by removing them.
+func (b *backend) revokeCollaborator(
+	ctx context.Context, req *logical.Request, _ *framework.FieldData,
+) (*logical.Response, error) {
+	return nil, b.removeGrant(ctx, req.Storage, collaboratorGrants, grantFromInternalData(req.Secret.InternalData))
+}
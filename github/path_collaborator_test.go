@@ -0,0 +1,376 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// testRepository is the website repository of the test-1 organization on a
+// fake GitHub API server, with its direct collaborators by user name and their
+// permission, and its pending invitations by ID. Organization members are added
+// directly whereas others are invited.
+type testRepository struct {
+	ts            *testGitHubServer
+	collaborators map[string]string
+	invitations   map[int]string
+	members       []string
+}
+
+// newTestRepository serves the collaborators and invitations of the
+// repository on the server.
+func newTestRepository(t *testing.T, ts *testGitHubServer, repo *testRepository) *testRepository {
+	t.Helper()
+
+	repo.ts = ts
+	if repo.collaborators == nil {
+		repo.collaborators = map[string]string{}
+	}
+
+	if repo.invitations == nil {
+		repo.invitations = map[int]string{}
+	}
+
+	repoPath := fmt.Sprintf("/repos/%s/website", testOrgName1)
+
+	ts.handle("GET "+repoPath+"/collaborators", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Query().Get("affiliation"), "direct")
+
+		logins := slices.Sorted(maps.Keys(repo.collaborators))
+
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		start, end := min((page-1)*perPage, len(logins)), min(page*perPage, len(logins))
+
+		collaborators := []collaborator{}
+		for _, login := range logins[start:end] {
+			collaborators = append(collaborators, collaborator{Login: login})
+		}
+
+		assert.NilError(t, json.NewEncoder(w).Encode(collaborators))
+	})
+	ts.handle("GET "+repoPath+"/invitations", func(w http.ResponseWriter, r *http.Request) {
+		ids := slices.Sorted(maps.Keys(repo.invitations))
+
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		start, end := min((page-1)*perPage, len(ids)), min(page*perPage, len(ids))
+
+		invitations := []repositoryInvitation{}
+		for _, id := range ids[start:end] {
+			invitations = append(invitations, repositoryInvitation{
+				Invitee: collaborator{Login: repo.invitations[id]},
+				ID:      id,
+			})
+		}
+
+		assert.NilError(t, json.NewEncoder(w).Encode(invitations))
+	})
+	ts.handle("PUT "+repoPath+"/collaborators/{username}", func(w http.ResponseWriter, r *http.Request) {
+		username := r.PathValue("username")
+
+		var body map[string]string
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		if slices.Contains(repo.members, username) {
+			repo.collaborators[username] = body["permission"]
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		}
+
+		id := len(repo.invitations) + 1
+		repo.invitations[id] = username
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id":%d,"permissions":%q}`, id, body["permission"])
+	})
+	ts.handle("DELETE "+repoPath+"/collaborators/{username}", func(w http.ResponseWriter, r *http.Request) {
+		delete(repo.collaborators, r.PathValue("username"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	ts.handle("DELETE "+repoPath+"/invitations/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(r.PathValue("id"))
+		if _, ok := repo.invitations[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		delete(repo.invitations, id)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return repo
+}
+
+// permission returns the permission of the user on the repository, if a
+// direct collaborator.
+func (repo *testRepository) permission(username string) string {
+	repo.ts.mu.Lock()
+	defer repo.ts.mu.Unlock()
+
+	return repo.collaborators[username]
+}
+
+// invited returns the user names of the pending invitations.
+func (repo *testRepository) invited() []string {
+	repo.ts.mu.Lock()
+	defer repo.ts.mu.Unlock()
+
+	return slices.Collect(maps.Values(repo.invitations))
+}
+
+func TestBackend_PathCollaboratorRole(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := testBackend(t)
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternCollaboratorRoles + "/website",
+		Data:      map[string]any{keyOrgName: testOrgName1},
+	})
+	assert.NilError(t, err)
+	assert.ErrorContains(t, r.Error(), "org_name and repository are required parameters")
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternCollaboratorRoles + "/website",
+		Data:      map[string]any{keyOrgName: testOrgName1, keyRepository: "website", keyPermission: "admin"},
+	})
+	assert.ErrorContains(t, err, "permission must be pull, push or maintain")
+
+	var coded logical.HTTPCodedError
+	assert.Assert(t, errors.As(err, &coded))
+	assert.Equal(t, coded.Code(), http.StatusBadRequest)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternCollaboratorRoles + "/website",
+		Data: map[string]any{
+			keyOrgName:    testOrgName1,
+			keyRepository: "website",
+			keyTTL:        "4h",
+			keyMaxTTL:     "1h",
+		},
+	})
+	assert.ErrorContains(t, err, errTTLExceedsMaxTTL.Error())
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternCollaboratorRoles + "/website",
+		Data: map[string]any{
+			keyOrgName:               testOrgName1,
+			keyRepository:            "website",
+			keyUsernameAliasAccessor: "auth_github_1",
+			keyTTL:                   "4h",
+		},
+	})
+	assert.NilError(t, err)
+
+	// Updates only change the given fields.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternCollaboratorRoles + "/website",
+		Data:      map[string]any{keyPermission: collaboratorPermissionPush},
+	})
+	assert.NilError(t, err)
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternCollaboratorRoles + "/website",
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Data, map[string]any{
+		keyOrgName:               testOrgName1,
+		keyRepository:            "website",
+		keyPermission:            collaboratorPermissionPush,
+		keyInstallationID:        0,
+		keyUsernameMetadataKey:   defaultUsernameMetadataKey,
+		keyUsernameAliasAccessor: "auth_github_1",
+		keyApp:                   "",
+		keyTTL:                   int64(14400),
+		keyMaxTTL:                int64(0),
+	})
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ListOperation,
+		Path:      pathPatternCollaboratorRoles,
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Data["keys"], []string{"website"})
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.DeleteOperation,
+		Path:      pathPatternCollaboratorRoles + "/website",
+	})
+	assert.NilError(t, err)
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternCollaboratorRoles + "/website",
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, is.Nil(r))
+}
+
+func TestBackend_PathCollaborator(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := newTestGitHubServer(t)
+	b, storage := testGitHubBackend(t, ts)
+
+	system := b.System().(*logical.StaticSystemView)
+	system.EntityVal = &logical.Entity{
+		ID: "entity-1",
+		Aliases: []*logical.Alias{
+			{MountAccessor: "auth_github_1", Name: "contractor"},
+		},
+	}
+
+	repo := newTestRepository(t, ts, &testRepository{
+		collaborators: map[string]string{"hubot": collaboratorPermissionPull},
+		members:       []string{"octocat"},
+	})
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternCollaboratorRoles + "/website",
+		Data: map[string]any{
+			keyOrgName:               testOrgName1,
+			keyRepository:            "website",
+			keyPermission:            collaboratorPermissionPush,
+			keyUsernameAliasAccessor: "auth_github_1",
+			keyTTL:                   "4h",
+		},
+	})
+	assert.NilError(t, err)
+
+	// Outside collaborators are invited.
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternCollaborator + "/website",
+		EntityID:  "entity-1",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, r.Data[keyUsername], "contractor")
+	assert.Equal(t, r.Data[keyPermission], collaboratorPermissionPush)
+	assert.Equal(t, r.Data["invited"], true)
+	assert.Equal(t, r.Data[keyInvitationID], 1)
+	assert.Equal(t, r.Secret.InternalData["secret_type"], collaboratorSecretType)
+	assert.Equal(t, r.Secret.TTL, 4*time.Hour)
+	assert.DeepEqual(t, repo.invited(), []string{"contractor"})
+
+	grants, err := storage.List(ctx, collaboratorGrantsStoragePrefix)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, grants, []string{r.Secret.InternalData[keyGrantID].(string)})
+
+	// Revoking the lease deletes the pending invitation and its record.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.RevokeOperation,
+		Secret:    r.Secret,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(repo.invited()), 0)
+
+	grants, err = storage.List(ctx, collaboratorGrantsStoragePrefix)
+	assert.NilError(t, err)
+	assert.Equal(t, len(grants), 0)
+
+	// User names that could alter API paths are refused.
+	system.EntityVal.Aliases[0].Name = "../../collaborators/hubot"
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternCollaborator + "/website",
+		EntityID:  "entity-1",
+	})
+	assert.ErrorContains(t, err, errInvalidUsername.Error())
+	assert.Equal(t, repo.permission("hubot"), collaboratorPermissionPull)
+
+	// Organization members are added directly.
+	system.EntityVal.Aliases[0].Name = "octocat"
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternCollaborator + "/website",
+		EntityID:  "entity-1",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, r.Data[keyUsername], "octocat")
+	assert.Equal(t, r.Data["invited"], false)
+	assert.Equal(t, repo.permission("octocat"), collaboratorPermissionPush)
+
+	// Existing direct collaborators are refused.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternCollaborator + "/website",
+		EntityID:  "entity-1",
+	})
+	assert.ErrorContains(t, err, errAlreadyCollaborator.Error())
+
+	var coded logical.HTTPCodedError
+	assert.Assert(t, errors.As(err, &coded))
+	assert.Equal(t, coded.Code(), http.StatusConflict)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.RevokeOperation,
+		Secret:    r.Secret,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, repo.permission("octocat"), "")
+	assert.Equal(t, repo.permission("hubot"), collaboratorPermissionPull)
+
+	// Users with a pending invitation are refused, leaving the invitation.
+	ts.mu.Lock()
+	repo.invitations[9] = "octocat"
+	ts.mu.Unlock()
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternCollaborator + "/website",
+		EntityID:  "entity-1",
+	})
+	assert.ErrorContains(t, err, errAlreadyInvited.Error())
+	assert.Assert(t, errors.As(err, &coded))
+	assert.Equal(t, coded.Code(), http.StatusConflict)
+	assert.DeepEqual(t, repo.invited(), []string{"octocat"})
+
+	for _, constraints := range ts.tokenRequests() {
+		assert.DeepEqual(t, constraints, tokenConstraints{
+			Repositories: []string{"website"},
+			Permissions:  collaboratorsTokenPermissions,
+		})
+	}
+}
@@ -19,14 +19,78 @@ const (
 )
 
 const (
-	keyAppID                      = "app_id"
-	descAppID                     = "Application ID of the GitHub App."
-	keyPrvKey                     = "prv_key"
-	descPrvKey                    = "Private key for signing GitHub access token requests (JWTs)."
-	keyBaseURL                    = "base_url"
-	descBaseURL                   = "Base URL for API requests (defaults to the public GitHub API)."
-	keyExcludeRepositoryMetadata  = "exclude_repository_metadata"
-	descExcludeRepositoryMetadata = "Minimise token response 'data.repositories' content to 'data.repositories.*.names'"
+	keyAppID                         = "app_id"
+	descAppID                        = "Application ID of the GitHub App."
+	keyPrvKey                        = "prv_key"
+	descPrvKey                       = "Private key for signing GitHub access token requests (JWTs)."
+	keyBaseURL                       = "base_url"
+	descBaseURL                      = "Base URL for API requests (defaults to the public GitHub API)."
+	keyPermissionCatalog             = "permission_catalog"
+	descPermissionCatalog            = "Overrides of the built-in catalog of permissions, mapping names to their allowed access levels (comma separated). An empty value removes a permission."
+	keyCheckGrantedPermissions       = "check_granted_permissions"
+	descCheckGrantedPermissions      = "Check requested permissions against those granted to the installation before requesting tokens, explaining any shortfall."
+	keyAllowedOrgNames               = "allowed_org_names"
+	descAllowedOrgNames              = "Glob patterns of the organization names that tokens can be requested for (defaults to any)."
+	keyAllowedInstallationIDs        = "allowed_installation_ids"
+	descAllowedInstallationIDs       = "Glob patterns of the installation IDs that tokens can be requested for (defaults to any)."
+	keyDeniedOrgNames                = "denied_org_names"
+	descDeniedOrgNames               = "Glob patterns of the organization names that tokens cannot be requested for, taking precedence over those allowed."
+	keyDeniedInstallationIDs         = "denied_installation_ids"
+	descDeniedInstallationIDs        = "Glob patterns of the installation IDs that tokens cannot be requested for, taking precedence over those allowed."
+	keyDisableAdhocTokens            = "disable_adhoc_tokens"
+	descDisableAdhocTokens           = "Refuse token requests other than those of permission sets."
+	keyCacheAdhocTokens              = "cache_adhoc_tokens"
+	descCacheAdhocTokens             = "Return cached tokens for identical ad-hoc token requests while they have 'token_cache_min_remaining' left."
+	keyTokenCacheMinRemaining        = "token_cache_min_remaining"
+	descTokenCacheMinRemaining       = "The lifetime a cached token must have left to be returned (defaults to 30m)."
+	keyExcludeRepositoryMetadata     = "exclude_repository_metadata"
+	descExcludeRepositoryMetadata    = "Minimise token response 'data.repositories' content to 'data.repositories.*.names'"
+	keyTransitKey                    = "transit_key"
+	descTransitKey                   = "Name of a Vault Transit RSA key to sign GitHub access token requests (JWTs) with instead of 'prv_key'."
+	keyTransitMount                  = "transit_mount"
+	descTransitMount                 = "Mount path of the Transit secrets engine holding 'transit_key' (defaults to 'transit')."
+	keyVaultAddr                     = "vault_addr"
+	descVaultAddr                    = "Address of the Vault server hosting 'transit_key' (defaults to the plugin's VAULT_ADDR)."
+	keyVaultToken                    = "vault_token"
+	descVaultToken                   = "Vault token permitted to sign with 'transit_key'."
+	keyVaultNamespace                = "vault_namespace"
+	descVaultNamespace               = "Vault Enterprise namespace of 'transit_mount'."
+	keyCACertificate                 = "ca_certificate"
+	descCACertificate                = "PEM encoded CA certificate bundle to trust for GitHub instead of the system trust store."
+	keyClientCertificate             = "client_certificate"
+	descClientCertificate            = "PEM encoded client certificate to present to GitHub for mutual TLS."
+	keyClientKey                     = "client_key"
+	descClientKey                    = "PEM encoded private key of 'client_certificate'."
+	keyTLSMinVersion                 = "tls_min_version"
+	descTLSMinVersion                = "Minimum TLS version to connect to GitHub with: one of 'tls10', 'tls11', 'tls12' or 'tls13' (defaults to 'tls12')."
+	keyTLSServerName                 = "tls_server_name"
+	descTLSServerName                = "Server name to verify the GitHub certificate against, overriding the host of 'base_url'."
+	keyProxyURL                      = "proxy_url"
+	descProxyURL                     = "URL of the proxy to send requests to GitHub through (defaults to the plugin's HTTP(S)_PROXY)."
+	keyNoProxy                       = "no_proxy"
+	keyHTTPProxy                     = "http_proxy"
+	keyHTTPSProxy                    = "https_proxy"
+	descNoProxy                      = "Comma separated hosts to connect to directly, bypassing the proxy (defaults to the plugin's NO_PROXY)."
+	keyRequestTimeout                = "request_timeout"
+	descRequestTimeout               = "Timeout of requests to GitHub (defaults to 10s)."
+	keyDialTimeout                   = "dial_timeout"
+	descDialTimeout                  = "Timeout of connecting to GitHub (defaults to a quarter of 'request_timeout')."
+	keyTLSHandshakeTimeout           = "tls_handshake_timeout"
+	descTLSHandshakeTimeout          = "Timeout of TLS handshakes with GitHub (defaults to a quarter of 'request_timeout')."
+	keyMaxIdleConns                  = "max_idle_conns"
+	descMaxIdleConns                 = "Maximum idle connections kept open in total (defaults to no limit)."
+	keyMaxIdleConnsPerHost           = "max_idle_conns_per_host"
+	descMaxIdleConnsPerHost          = "Maximum idle connections kept open to GitHub (defaults to 2)."
+	keyIdleConnTimeout               = "idle_conn_timeout"
+	descIdleConnTimeout              = "How long idle connections are kept open (defaults to no limit)."
+	keyInstallationCacheTTL          = "installation_cache_ttl"
+	descInstallationCacheTTL         = "How long installations are cached to look up installation IDs by organization name (defaults to 5m, 0 disables)."
+	keyInstallationCacheNegativeTTL  = "installation_cache_negative_ttl"
+	descInstallationCacheNegativeTTL = "How long an organization is cached as not having the App installed (defaults to 1m)."
+	keyInstallationCacheStorage      = "installation_cache_storage"
+	descInstallationCacheStorage     = "Persist the installations cache to storage so that it survives plugin restarts."
+	keyMaxRetries                    = "max_retries"
+	descMaxRetries                   = "How many times a request to GitHub that failed transiently is retried (defaults to 3)."
 )
 
 const pathConfigHelpSyn = `
@@ -36,34 +100,19 @@ Configure the GitHub secrets plugin.
 var pathConfigHelpDesc = fmt.Sprintf(`
 Configure the GitHub secrets plugin using the above parameters.
 
-NOTE: %q must be in PEM PKCS#1 RSAPrivateKey format.`, keyPrvKey)
+NOTE: %q must be a PEM encoded RSA private key of at least %d bits, in either
+PKCS#1 RSAPrivateKey or unencrypted PKCS#8 PrivateKeyInfo format. It is stored in
+PKCS#1 format.
+
+Alternatively, set %q to sign with a Vault Transit RSA key holding the GitHub
+App private key so that the key is never stored by the plugin. %q is then not
+required.`, keyPrvKey, minPrvKeyBits, keyTransitKey, keyPrvKey)
 
 // pathConfig defines the /github/config base path on the backend.
 func (b *backend) pathConfig() *framework.Path {
 	return &framework.Path{
-		Pattern: pathPatternConfig,
-		Fields: map[string]*framework.FieldSchema{
-			keyAppID: {
-				Type:        framework.TypeInt,
-				Description: descAppID,
-				Required:    true,
-			},
-			keyPrvKey: {
-				Type:        framework.TypeString,
-				Description: descPrvKey,
-				Required:    true,
-			},
-			keyExcludeRepositoryMetadata: {
-				Type:        framework.TypeBool,
-				Description: descExcludeRepositoryMetadata,
-				Required:    false,
-				Default:     false,
-			},
-			keyBaseURL: {
-				Type:        framework.TypeString,
-				Description: descBaseURL,
-			},
-		},
+		Pattern:        pathPatternConfig,
+		Fields:         configFields(),
 		ExistenceCheck: b.pathConfigExistenceCheck,
 		Operations: map[logical.Operation]framework.OperationHandler{
 			logical.CreateOperation: &framework.PathOperation{
@@ -84,40 +133,279 @@ func (b *backend) pathConfig() *framework.Path {
 	}
 }
 
+// configFields returns the field schema shared by the default app
+// configuration and named app configurations.
+func configFields() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		keyAppID: {
+			Type:        framework.TypeInt,
+			Description: descAppID,
+			Required:    true,
+		},
+		keyPrvKey: {
+			Type:        framework.TypeString,
+			Description: descPrvKey,
+			Required:    true,
+		},
+		keyPermissionCatalog: {
+			Type:        framework.TypeKVPairs,
+			Description: descPermissionCatalog,
+		},
+		keyCheckGrantedPermissions: {
+			Type:        framework.TypeBool,
+			Description: descCheckGrantedPermissions,
+		},
+		keyAllowedOrgNames: {
+			Type:        framework.TypeCommaStringSlice,
+			Description: descAllowedOrgNames,
+		},
+		keyAllowedInstallationIDs: {
+			Type:        framework.TypeCommaStringSlice,
+			Description: descAllowedInstallationIDs,
+		},
+		keyDeniedOrgNames: {
+			Type:        framework.TypeCommaStringSlice,
+			Description: descDeniedOrgNames,
+		},
+		keyDeniedInstallationIDs: {
+			Type:        framework.TypeCommaStringSlice,
+			Description: descDeniedInstallationIDs,
+		},
+		keyDisableAdhocTokens: {
+			Type:        framework.TypeBool,
+			Description: descDisableAdhocTokens,
+		},
+		keyCacheAdhocTokens: {
+			Type:        framework.TypeBool,
+			Description: descCacheAdhocTokens,
+		},
+		keyTokenCacheMinRemaining: {
+			Type:        framework.TypeDurationSecond,
+			Description: descTokenCacheMinRemaining,
+		},
+		keyExcludeRepositoryMetadata: {
+			Type:        framework.TypeBool,
+			Description: descExcludeRepositoryMetadata,
+			Required:    false,
+			Default:     false,
+		},
+		keyBaseURL: {
+			Type:        framework.TypeString,
+			Description: descBaseURL,
+		},
+		keyTransitKey: {
+			Type:        framework.TypeString,
+			Description: descTransitKey,
+		},
+		keyTransitMount: {
+			Type:        framework.TypeString,
+			Description: descTransitMount,
+		},
+		keyVaultAddr: {
+			Type:        framework.TypeString,
+			Description: descVaultAddr,
+		},
+		keyVaultToken: {
+			Type:        framework.TypeString,
+			Description: descVaultToken,
+		},
+		keyVaultNamespace: {
+			Type:        framework.TypeString,
+			Description: descVaultNamespace,
+		},
+		keyCACertificate: {
+			Type:        framework.TypeString,
+			Description: descCACertificate,
+		},
+		keyClientCertificate: {
+			Type:        framework.TypeString,
+			Description: descClientCertificate,
+		},
+		keyClientKey: {
+			Type:        framework.TypeString,
+			Description: descClientKey,
+		},
+		keyTLSMinVersion: {
+			Type:        framework.TypeString,
+			Description: descTLSMinVersion,
+		},
+		keyTLSServerName: {
+			Type:        framework.TypeString,
+			Description: descTLSServerName,
+		},
+		keyProxyURL: {
+			Type:        framework.TypeString,
+			Description: descProxyURL,
+		},
+		keyNoProxy: {
+			Type:        framework.TypeCommaStringSlice,
+			Description: descNoProxy,
+		},
+		keyRequestTimeout: {
+			Type:        framework.TypeDurationSecond,
+			Description: descRequestTimeout,
+		},
+		keyDialTimeout: {
+			Type:        framework.TypeDurationSecond,
+			Description: descDialTimeout,
+		},
+		keyTLSHandshakeTimeout: {
+			Type:        framework.TypeDurationSecond,
+			Description: descTLSHandshakeTimeout,
+		},
+		keyMaxIdleConns: {
+			Type:        framework.TypeInt,
+			Description: descMaxIdleConns,
+		},
+		keyMaxIdleConnsPerHost: {
+			Type:        framework.TypeInt,
+			Description: descMaxIdleConnsPerHost,
+		},
+		keyIdleConnTimeout: {
+			Type:        framework.TypeDurationSecond,
+			Description: descIdleConnTimeout,
+		},
+		keyInstallationCacheTTL: {
+			Type:        framework.TypeDurationSecond,
+			Description: descInstallationCacheTTL,
+		},
+		keyInstallationCacheNegativeTTL: {
+			Type:        framework.TypeDurationSecond,
+			Description: descInstallationCacheNegativeTTL,
+		},
+		keyInstallationCacheStorage: {
+			Type:        framework.TypeBool,
+			Description: descInstallationCacheStorage,
+		},
+		keyMaxRetries: {
+			Type:        framework.TypeInt,
+			Description: descMaxRetries,
+		},
+		keyVerify: {
+			Type:        framework.TypeBool,
+			Description: descVerify,
+		},
+	}
+}
+
 // pathConfigRead corresponds to READ on /github/config.
 func (b *backend) pathConfigRead(
 	ctx context.Context,
 	req *logical.Request,
 	_ *framework.FieldData,
 ) (*logical.Response, error) {
-	c, err := b.Config(ctx, req.Storage)
+	return b.configRead(ctx, req, "")
+}
+
+// pathConfigWrite corresponds to both CREATE and UPDATE on /github/config.
+func (b *backend) pathConfigWrite(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	return b.configWrite(ctx, req, d, "")
+}
+
+// pathConfigDelete corresponds to DELETE on /github/config.
+func (b *backend) pathConfigDelete(
+	ctx context.Context,
+	req *logical.Request,
+	_ *framework.FieldData,
+) (*logical.Response, error) {
+	return b.configDelete(ctx, req, "")
+}
+
+// pathConfigExistenceCheck is implemented on this path to avoid breaking user
+// backwards compatibility. The CreateOperation will likely be removed in a
+// future major version of the plugin.
+func (b *backend) pathConfigExistenceCheck(
+	ctx context.Context,
+	req *logical.Request,
+	_ *framework.FieldData,
+) (bool, error) {
+	return b.configExistenceCheck(ctx, req, "")
+}
+
+// configRead reads the configuration of the named app.
+func (b *backend) configRead(
+	ctx context.Context,
+	req *logical.Request,
+	app string,
+) (*logical.Response, error) {
+	c, exists, err := b.AppConfig(ctx, req.Storage, app)
 	if err != nil {
 		return nil, err
 	}
 
+	if app != "" && !exists {
+		return nil, nil
+	}
+
 	resData := map[string]any{
 		keyAppID:                     c.AppID,
 		keyBaseURL:                   c.BaseURL,
 		keyExcludeRepositoryMetadata: c.ExcludeRepositoryMetadata,
+		keyPermissionCatalog:         c.PermissionCatalog,
+		keyCheckGrantedPermissions:   c.CheckGrantedPermissions,
+		keyAllowedOrgNames:           c.AllowedOrgNames,
+		keyAllowedInstallationIDs:    c.AllowedInstallationIDs,
+		keyDeniedOrgNames:            c.DeniedOrgNames,
+		keyDeniedInstallationIDs:     c.DeniedInstallationIDs,
+		keyDisableAdhocTokens:        c.DisableAdhocTokens,
+		keyCacheAdhocTokens:          c.CacheAdhocTokens,
+		keyTokenCacheMinRemaining:    int64(c.tokenCacheMinRemaining().Seconds()),
+		keyTransitKey:                c.TransitKey,
+		keyTransitMount:              c.TransitMount,
+		keyVaultAddr:                 c.VaultAddr,
+		keyVaultNamespace:            c.VaultNamespace,
+		keyCACertificate:             c.CACertificate,
+		keyClientCertificate:         c.ClientCertificate,
+		keyTLSMinVersion:             c.TLSMinVersion,
+		keyTLSServerName:             c.TLSServerName,
 	}
 
-	// We don't return the key but indicate its presence for a better UX.
-	if c.PrvKey != "" {
-		resData[keyPrvKey] = "<configured>"
-	} else {
-		resData[keyPrvKey] = "" // Vault renders this as "n/a" which is ideal.
+	// Show the effective transport settings, including defaults and those of
+	// the plugin's environment.
+	proxy := c.proxyConfig()
+	resData[keyProxyURL] = redactProxyURL(c.ProxyURL)
+	resData[keyHTTPProxy] = redactProxyURL(proxy.HTTPProxy)
+	resData[keyHTTPSProxy] = redactProxyURL(proxy.HTTPSProxy)
+	resData[keyNoProxy] = proxy.NoProxy
+	resData[keyRequestTimeout] = int64(c.requestTimeout().Seconds())
+	resData[keyDialTimeout] = int64(c.dialTimeout().Seconds())
+	resData[keyTLSHandshakeTimeout] = int64(c.tlsHandshakeTimeout().Seconds())
+	resData[keyMaxIdleConns] = c.MaxIdleConns
+	resData[keyMaxIdleConnsPerHost] = c.maxIdleConnsPerHost()
+	resData[keyIdleConnTimeout] = int64(c.IdleConnTimeout.Seconds())
+	resData[keyMaxRetries] = c.maxRetries()
+	resData[keyInstallationCacheTTL] = int64(c.installationCacheTTL().Seconds())
+	resData[keyInstallationCacheNegativeTTL] = int64(c.installationCacheNegativeTTL().Seconds())
+	resData[keyInstallationCacheStorage] = c.InstallationCacheStorage
+
+	// We don't return secrets but indicate their presence for a better UX.
+	for key, secret := range map[string]string{
+		keyPrvKey:     c.PrvKey,
+		keyVaultToken: c.VaultToken,
+		keyClientKey:  c.ClientKey,
+	} {
+		if secret != "" {
+			resData[key] = "<configured>"
+		} else {
+			resData[key] = "" // Vault renders this as "n/a" which is ideal.
+		}
 	}
 
 	return &logical.Response{Data: resData}, nil
 }
 
-// pathConfigWrite corresponds to both CREATE and UPDATE on /github/config.
-func (b *backend) pathConfigWrite(
+// configWrite creates or updates the configuration of the named app.
+func (b *backend) configWrite(
 	ctx context.Context,
 	req *logical.Request,
 	d *framework.FieldData,
+	app string,
 ) (*logical.Response, error) {
-	c, err := b.Config(ctx, req.Storage)
+	c, _, err := b.AppConfig(ctx, req.Storage, app)
 	if err != nil {
 		return nil, err
 	}
@@ -128,51 +416,74 @@ func (b *backend) pathConfigWrite(
 		return nil, logical.CodedError(400, err.Error())
 	}
 
+	// Verify the configuration against GitHub before it is persisted.
+	var resp *logical.Response
+	if d.Get(keyVerify).(bool) {
+		if resp, err = b.verifyConfig(ctx, c); err != nil {
+			return nil, err
+		}
+	}
+
 	// Persist only if changed.
 	if changed {
-		var entry *logical.StorageEntry
-
-		if entry, err = logical.StorageEntryJSON(pathPatternConfig, c); err != nil {
-			// NOTE: Failure scenario cannot happen.
-			return nil, fmt.Errorf("%s: %w", errConfMarshal, err)
+		if err = b.saveConfig(ctx, req.Storage, app, c); err != nil {
+			return nil, err
 		}
+	}
 
-		if err = req.Storage.Put(ctx, entry); err != nil {
-			return nil, fmt.Errorf("%s: %w", errConfPersist, err)
-		}
+	return resp, nil
+}
+
+// saveConfig persists the configuration of the named app and invalidates any
+// existing client so it reads the new configuration.
+func (b *backend) saveConfig(ctx context.Context, s logical.Storage, app string, c *Config) error {
+	entry, err := logical.StorageEntryJSON(configKey(app), c)
+	if err != nil {
+		// NOTE: Failure scenario cannot happen.
+		return fmt.Errorf("%s: %w", errConfMarshal, err)
+	}
 
-		// Invalidate existing client so it reads the new configuration.
-		b.Invalidate(ctx, pathPatternConfig)
+	if err = s.Put(ctx, entry); err != nil {
+		return fmt.Errorf("%s: %w", errConfPersist, err)
 	}
 
-	return nil, nil
+	// The cached installations may not belong to the new configuration.
+	if err = s.Delete(ctx, installationsCacheKey(app)); err != nil {
+		return fmt.Errorf("%s: %w", errConfPersist, err)
+	}
+
+	// Invalidate existing client so it reads the new configuration.
+	b.Invalidate(ctx, configKey(app))
+
+	return nil
 }
 
-// pathConfigDelete corresponds to DELETE on /github/config.
-func (b *backend) pathConfigDelete(
+// configDelete deletes the configuration of the named app.
+func (b *backend) configDelete(
 	ctx context.Context,
 	req *logical.Request,
-	_ *framework.FieldData,
+	app string,
 ) (*logical.Response, error) {
-	if err := req.Storage.Delete(ctx, pathPatternConfig); err != nil {
-		return nil, fmt.Errorf("%s: %w", errConfDelete, err)
+	for _, key := range []string{configKey(app), installationsCacheKey(app)} {
+		if err := req.Storage.Delete(ctx, key); err != nil {
+			return nil, fmt.Errorf("%s: %w", errConfDelete, err)
+		}
 	}
 
 	// Invalidate existing client so it reads the new configuration.
-	b.Invalidate(ctx, pathPatternConfig)
+	b.Invalidate(ctx, configKey(app))
 
 	return nil, nil
 }
 
-// pathConfigExistenceCheck is implemented on this path to avoid breaking user
-// backwards compatibility. The CreateOperation will likely be removed in a
-// future major version of the plugin.
-func (b *backend) pathConfigExistenceCheck(
+// configExistenceCheck reports whether the configuration of the named app
+// exists in storage.
+func (b *backend) configExistenceCheck(
 	ctx context.Context,
 	req *logical.Request,
-	_ *framework.FieldData,
+	app string,
 ) (bool, error) {
-	entry, err := req.Storage.Get(ctx, pathPatternConfig)
+	entry, err := req.Storage.Get(ctx, configKey(app))
 	if err != nil {
 		return false, fmt.Errorf("%s: %w", errConfRetrieval, err)
 	}
@@ -0,0 +1,183 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// pathPatternConfigApps is the string used to define the base path of the
+// named app config endpoints as well as the storage prefix of the named app
+// config objects.
+const pathPatternConfigApps = pathPatternConfig + "/apps"
+
+const (
+	keyApp  = "app"
+	descApp = "Name of the configured GitHub App to use (defaults to the app configured at 'config')."
+)
+
+const (
+	pathConfigAppsHelpSyn = `
+Configure a named GitHub App for the GitHub secrets plugin.
+`
+	pathConfigAppsListHelpSyn  = `List configured named GitHub Apps.`
+	pathConfigAppsListHelpDesc = `List the names of configured GitHub Apps.`
+)
+
+var pathConfigAppsHelpDesc = fmt.Sprintf(`
+Configure a named GitHub App so that a single mount can broker tokens from
+several GitHub Apps. Named apps take the same parameters as 'config', which
+itself remains the default app.
+
+To use a named app, pass its name as the %q parameter to the 'token',
+'permissionset/<name>' and 'installations' paths.
+
+NOTE: %q must be a PEM encoded RSA private key of at least %d bits, in either
+PKCS#1 RSAPrivateKey or unencrypted PKCS#8 PrivateKeyInfo format.`,
+	keyApp, keyPrvKey, minPrvKeyBits)
+
+// configKey returns the storage key of the configuration of the named app. An
+// empty name refers to the default app.
+func configKey(app string) string {
+	if app == "" {
+		return pathPatternConfig
+	}
+
+	return fmt.Sprintf("%s/%s", pathPatternConfigApps, app)
+}
+
+// appFromConfigKey is the inverse of configKey. It reports false when the
+// storage key does not belong to an app configuration.
+func appFromConfigKey(key string) (string, bool) {
+	if key == pathPatternConfig {
+		return "", true
+	}
+
+	app, ok := strings.CutPrefix(key, pathPatternConfigApps+"/")
+	if !ok || app == "" || strings.Contains(app, "/") {
+		return "", false
+	}
+
+	return app, true
+}
+
+// pathConfigApps defines the /github/config/apps/<name> path on the backend.
+func (b *backend) pathConfigApps() *framework.Path {
+	fields := configFields()
+	fields["name"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Required. Name of the GitHub App.",
+	}
+
+	return &framework.Path{
+		Pattern:        fmt.Sprintf("%s/%s", pathPatternConfigApps, framework.GenericNameRegex("name")),
+		Fields:         fields,
+		ExistenceCheck: b.pathConfigAppsExistenceCheck,
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.CreateOperation: &framework.PathOperation{
+				Callback: withFieldValidator(b.pathConfigAppsWrite),
+			},
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: withFieldValidator(b.pathConfigAppsRead),
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: withFieldValidator(b.pathConfigAppsWrite),
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: withFieldValidator(b.pathConfigAppsDelete),
+			},
+		},
+		HelpSynopsis:    pathConfigAppsHelpSyn,
+		HelpDescription: pathConfigAppsHelpDesc,
+	}
+}
+
+// pathConfigAppsList defines the /github/config/apps path on the backend.
+func (b *backend) pathConfigAppsList() *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%s/?", pathPatternConfigApps),
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: b.pathConfigAppsListRead,
+			},
+		},
+		HelpSynopsis:    pathConfigAppsListHelpSyn,
+		HelpDescription: pathConfigAppsListHelpDesc,
+	}
+}
+
+// pathConfigAppsRead corresponds to READ on /github/config/apps/<name>.
+func (b *backend) pathConfigAppsRead(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	return b.configRead(ctx, req, d.Get("name").(string))
+}
+
+// pathConfigAppsWrite corresponds to both CREATE and UPDATE on
+// /github/config/apps/<name>.
+func (b *backend) pathConfigAppsWrite(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	return b.configWrite(ctx, req, d, d.Get("name").(string))
+}
+
+// pathConfigAppsDelete corresponds to DELETE on /github/config/apps/<name>.
+func (b *backend) pathConfigAppsDelete(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	return b.configDelete(ctx, req, d.Get("name").(string))
+}
+
+// pathConfigAppsListRead corresponds to LIST on /github/config/apps.
+func (b *backend) pathConfigAppsListRead(
+	ctx context.Context,
+	req *logical.Request,
+	_ *framework.FieldData,
+) (*logical.Response, error) {
+	apps, err := req.Storage.List(ctx, pathPatternConfigApps+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	return logical.ListResponse(apps), nil
+}
+
+// pathConfigAppsExistenceCheck reports whether the named app configuration
+// exists, routing writes to CREATE or UPDATE accordingly.
+func (b *backend) pathConfigAppsExistenceCheck(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (bool, error) {
+	return b.configExistenceCheck(ctx, req, d.Get("name").(string))
+}
+
+// existingAppConfig returns the existing configuration of the app named in the
+// request, responding with a 400 should the app not be configured.
+func (b *backend) existingAppConfig(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*Config, error) {
+	app := d.Get(keyApp).(string)
+
+	c, exists, err := b.AppConfig(ctx, req.Storage, app)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, logical.CodedError(400, fmt.Sprintf("%s: %q", errAppNotConfigured, app))
+	}
+
+	return c, nil
+}
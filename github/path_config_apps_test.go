@@ -0,0 +1,299 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/assert"
+
+	is "gotest.tools/assert/cmp"
+)
+
+const (
+	testAppName1 = "read-only"
+	testAppName2 = "ghes"
+)
+
+func TestConfigKey(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		key  string
+		app  string
+		ok   bool
+	}{
+		{name: "Default", key: pathPatternConfig, ok: true},
+		{name: "Named", key: configKey(testAppName1), app: testAppName1, ok: true},
+		{name: "PermissionSet", key: "permissionset/foo"},
+		{name: "AppsPrefixOnly", key: pathPatternConfigApps + "/"},
+		{name: "Nested", key: pathPatternConfigApps + "/foo/bar"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app, ok := appFromConfigKey(tc.key)
+			assert.Equal(t, ok, tc.ok)
+			assert.Equal(t, app, tc.app)
+
+			if ok {
+				assert.Equal(t, configKey(app), tc.key)
+			}
+		})
+	}
+}
+
+func TestBackend_PathConfigApps(t *testing.T) {
+	t.Parallel()
+
+	t.Run("FieldValidation", func(t *testing.T) {
+		t.Parallel()
+		testFieldValidation(t, logical.UpdateOperation, configKey(testAppName1))
+	})
+
+	t.Run("CRUD", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b, storage := testBackend(t)
+
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.CreateOperation,
+			Path:      configKey(testAppName1),
+			Data: map[string]any{
+				keyAppID:   testAppID2,
+				keyPrvKey:  testPrvKeyValid,
+				keyBaseURL: testBaseURLValid,
+			},
+		})
+		assert.NilError(t, err)
+
+		// The default app is left untouched.
+		config, err := b.Config(ctx, storage)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, config, NewConfig())
+
+		r, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      configKey(testAppName1),
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, r.Data[keyAppID], testAppID2)
+		assert.Equal(t, r.Data[keyPrvKey], "<configured>")
+
+		r, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ListOperation,
+			Path:      pathPatternConfigApps + "/",
+		})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r.Data["keys"], []string{testAppName1})
+
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.DeleteOperation,
+			Path:      configKey(testAppName1),
+		})
+		assert.NilError(t, err)
+
+		r, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      configKey(testAppName1),
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, is.Nil(r))
+	})
+
+	t.Run("FailedValidation", func(t *testing.T) {
+		t.Parallel()
+
+		b, storage := testBackend(t)
+
+		_, err := b.HandleRequest(context.Background(), &logical.Request{
+			Storage:   storage,
+			Operation: logical.CreateOperation,
+			Path:      configKey(testAppName1),
+			Data: map[string]any{
+				keyPrvKey: "not a private key",
+			},
+		})
+		assert.Error(t, err, errKeyNotPEMFormat.Error())
+	})
+
+	t.Run("FailedStorage", func(t *testing.T) {
+		t.Parallel()
+
+		b, storage := testBackend(t, failVerbList)
+
+		_, err := b.HandleRequest(context.Background(), &logical.Request{
+			Storage:   storage,
+			Operation: logical.ListOperation,
+			Path:      pathPatternConfigApps + "/",
+		})
+		assert.Assert(t, err != nil)
+	})
+}
+
+func TestBackend_ClientApps(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TokenFromNamedApp", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b, storage := testBackend(t)
+
+		newServer := func(token string) *httptest.Server {
+			return httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, _ *http.Request) {
+					t.Helper()
+
+					body, _ := json.Marshal(map[string]any{
+						"token":      token,
+						"expires_at": testTokenExp,
+					})
+					w.WriteHeader(http.StatusCreated)
+					w.Write(body)
+				}),
+			)
+		}
+
+		tsDefault := newServer("default")
+		defer tsDefault.Close()
+
+		tsNamed := newServer("named")
+		defer tsNamed.Close()
+
+		for app, url := range map[string]string{
+			"":           tsDefault.URL,
+			testAppName2: tsNamed.URL,
+		} {
+			_, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      configKey(app),
+				Data: map[string]any{
+					keyAppID:   testAppID1,
+					keyPrvKey:  testPrvKeyValid,
+					keyBaseURL: url,
+				},
+			})
+			assert.NilError(t, err)
+		}
+
+		for app, token := range map[string]string{
+			"":           "default",
+			testAppName2: "named",
+		} {
+			r, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      pathPatternToken,
+				Data: map[string]any{
+					keyInstallationID: testInsID1,
+					keyApp:            app,
+				},
+			})
+			assert.NilError(t, err)
+			assert.Equal(t, r.Data["token"], token)
+			assert.Equal(t, r.Secret.InternalData[keyApp], app)
+		}
+
+		// Both clients are cached independently.
+		assert.Equal(t, len(b.clients), 2)
+
+		// Reconfiguring a named app only resets its own client.
+		b.Invalidate(ctx, configKey(testAppName2))
+		assert.Equal(t, len(b.clients), 1)
+		assert.Assert(t, is.Contains(b.clients, ""))
+	})
+
+	t.Run("PermissionSetFromNamedApp", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b, storage := testBackend(t)
+
+		ts := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+
+				body, _ := json.Marshal(map[string]any{
+					"token":      testToken,
+					"expires_at": testTokenExp,
+				})
+				w.WriteHeader(http.StatusCreated)
+				w.Write(body)
+			}),
+		)
+		defer ts.Close()
+
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      configKey(testAppName1),
+			Data: map[string]any{
+				keyAppID:   testAppID1,
+				keyPrvKey:  testPrvKeyValid,
+				keyBaseURL: ts.URL,
+			},
+		})
+		assert.NilError(t, err)
+
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      "permissionset/foo",
+			Data: map[string]any{
+				keyInstallationID: testInsID1,
+				keyApp:            testAppName1,
+			},
+		})
+		assert.NilError(t, err)
+
+		r, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      "permissionset/foo",
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, r.Data[keyApp], testAppName1)
+
+		r, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      fmt.Sprintf("%s/foo", pathPatternToken),
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, r.Data["token"], testToken)
+		assert.Equal(t, r.Data[keyApp], testAppName1)
+	})
+
+	t.Run("UnknownApp", func(t *testing.T) {
+		t.Parallel()
+
+		b, storage := testBackend(t)
+
+		r, err := b.HandleRequest(context.Background(), &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternToken,
+			Data: map[string]any{
+				keyInstallationID: testInsID1,
+				keyApp:            "nonexistent",
+			},
+		})
+		assert.ErrorContains(t, err, errAppNotConfigured.Error())
+		assert.Assert(t, is.Nil(r))
+	})
+}
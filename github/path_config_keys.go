@@ -0,0 +1,276 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// pathPatternConfigKeys is the string used to define the base path of the
+// private key rotation endpoints.
+const pathPatternConfigKeys = pathPatternConfig + "/keys"
+
+const (
+	pathPatternConfigKeysPending = pathPatternConfigKeys + "/pending"
+	pathPatternConfigKeysPromote = pathPatternConfigKeys + "/promote"
+	pathPatternConfigKeysRetire  = pathPatternConfigKeys + "/retire"
+)
+
+const (
+	keyPendingPrvKey  = "pending_prv_key"
+	keyFallbackPrvKey = "fallback_prv_key"
+)
+
+const (
+	errNoPendingPrvKey       = Error("no pending private key to promote")
+	errPendingPrvKeyRefused  = Error("pending private key was not accepted by GitHub")
+	errPrvKeyRotationTransit = Error("private keys cannot be rotated while signing with a Transit key")
+)
+
+const pathConfigKeysHelpSyn = `
+Rotate the private key of a GitHub App without downtime.
+`
+
+var pathConfigKeysHelpDesc = fmt.Sprintf(`
+Rotate the private key of a GitHub App without downtime.
+
+1. Generate a new private key for the App on GitHub.
+2. Stage it by writing it as %q to '%s'.
+3. Promote it by writing to '%s'. The pending key is verified by
+   authenticating as the App against GitHub before it becomes the active key.
+   The previously active key is kept as a fallback and is used automatically
+   should GitHub reject the active key.
+4. Once the old key has been deleted from the App on GitHub, retire the
+   fallback key by writing to '%s'.
+
+Reading '%s' shows the fingerprints of the active, pending and fallback keys
+in the same format GitHub displays them. All of these paths take an optional
+%q parameter to rotate the key of a named app.
+
+Rotation is refused while %q is configured, as JWTs are then signed by
+Transit rather than with the private key.`,
+	keyPrvKey, pathPatternConfigKeysPending, pathPatternConfigKeysPromote,
+	pathPatternConfigKeysRetire, pathPatternConfigKeys, keyApp, keyTransitKey)
+
+// pathConfigKeys defines the /github/config/keys/* paths on the backend.
+func (b *backend) pathConfigKeys() []*framework.Path {
+	appField := map[string]*framework.FieldSchema{
+		keyApp: {
+			Type:        framework.TypeString,
+			Description: descApp,
+		},
+	}
+
+	return []*framework.Path{
+		{
+			Pattern: pathPatternConfigKeys,
+			Fields:  appField,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathConfigKeysRead),
+				},
+			},
+			HelpSynopsis:    pathConfigKeysHelpSyn,
+			HelpDescription: pathConfigKeysHelpDesc,
+		},
+		{
+			Pattern: pathPatternConfigKeysPending,
+			Fields: map[string]*framework.FieldSchema{
+				keyApp: appField[keyApp],
+				keyPrvKey: {
+					Type:        framework.TypeString,
+					Description: "Private key to stage for rotation.",
+					Required:    true,
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathConfigKeysPendingWrite),
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathConfigKeysPendingDelete),
+				},
+			},
+			HelpSynopsis:    pathConfigKeysHelpSyn,
+			HelpDescription: pathConfigKeysHelpDesc,
+		},
+		{
+			Pattern: pathPatternConfigKeysPromote,
+			Fields:  appField,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathConfigKeysPromoteWrite),
+				},
+			},
+			HelpSynopsis:    pathConfigKeysHelpSyn,
+			HelpDescription: pathConfigKeysHelpDesc,
+		},
+		{
+			Pattern: pathPatternConfigKeysRetire,
+			Fields:  appField,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathConfigKeysRetireWrite),
+				},
+			},
+			HelpSynopsis:    pathConfigKeysHelpSyn,
+			HelpDescription: pathConfigKeysHelpDesc,
+		},
+	}
+}
+
+// pathConfigKeysRead corresponds to READ on /github/config/keys.
+func (b *backend) pathConfigKeysRead(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	c, err := b.existingAppConfig(ctx, req, d)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]any{
+			keyPrvKey:         prvKeyFingerprint(c.PrvKey),
+			keyPendingPrvKey:  prvKeyFingerprint(c.PendingPrvKey),
+			keyFallbackPrvKey: prvKeyFingerprint(c.FallbackPrvKey),
+		},
+	}, nil
+}
+
+// pathConfigKeysPendingWrite corresponds to UPDATE on
+// /github/config/keys/pending.
+func (b *backend) pathConfigKeysPendingWrite(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	c, err := b.existingAppConfig(ctx, req, d)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.TransitKey != "" {
+		return nil, logical.CodedError(400, errPrvKeyRotationTransit.Error())
+	}
+
+	prvKey, err := normalisePrvKey(d.Get(keyPrvKey).(string))
+	if err != nil {
+		return nil, logical.CodedError(400, err.Error())
+	}
+
+	c.PendingPrvKey = prvKey
+
+	if err = b.saveConfig(ctx, req.Storage, d.Get(keyApp).(string), c); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// pathConfigKeysPendingDelete corresponds to DELETE on
+// /github/config/keys/pending.
+func (b *backend) pathConfigKeysPendingDelete(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	c, err := b.existingAppConfig(ctx, req, d)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.PendingPrvKey == "" {
+		return nil, nil
+	}
+
+	c.PendingPrvKey = ""
+
+	return nil, b.saveConfig(ctx, req.Storage, d.Get(keyApp).(string), c)
+}
+
+// pathConfigKeysPromoteWrite corresponds to UPDATE on
+// /github/config/keys/promote.
+func (b *backend) pathConfigKeysPromoteWrite(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	c, err := b.existingAppConfig(ctx, req, d)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.TransitKey != "" {
+		return nil, logical.CodedError(400, errPrvKeyRotationTransit.Error())
+	}
+
+	if c.PendingPrvKey == "" {
+		return nil, logical.CodedError(400, errNoPendingPrvKey.Error())
+	}
+
+	// Verify the pending key by authenticating as the App with it alone.
+	signer, err := newRSASigner(c.PendingPrvKey)
+	if err != nil {
+		return nil, logical.CodedError(400, err.Error())
+	}
+
+	pending := *c
+	pending.PrvKey = c.PendingPrvKey
+	pending.FallbackPrvKey = ""
+
+	client, err := NewClient(&pending, WithSigner(signer), WithLogger(b.Logger()))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errClientCreate, err)
+	}
+
+	a, err := client.App(ctx)
+	if err != nil {
+		return nil, logical.CodedError(400, fmt.Sprintf("%s: %s", errPendingPrvKeyRefused, err))
+	}
+
+	c.FallbackPrvKey = c.PrvKey
+	c.PrvKey = c.PendingPrvKey
+	c.PendingPrvKey = ""
+
+	if err = b.saveConfig(ctx, req.Storage, d.Get(keyApp).(string), c); err != nil {
+		return nil, err
+	}
+
+	b.Logger().Info("promoted pending GitHub App private key",
+		"app", d.Get(keyApp).(string),
+		"app_slug", a.Slug,
+		"fingerprint", prvKeyFingerprint(c.PrvKey),
+	)
+
+	return &logical.Response{
+		Data: map[string]any{
+			keyPrvKey:         prvKeyFingerprint(c.PrvKey),
+			keyFallbackPrvKey: prvKeyFingerprint(c.FallbackPrvKey),
+		},
+	}, nil
+}
+
+// pathConfigKeysRetireWrite corresponds to UPDATE on
+// /github/config/keys/retire.
+func (b *backend) pathConfigKeysRetireWrite(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	c, err := b.existingAppConfig(ctx, req, d)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.FallbackPrvKey == "" {
+		return nil, nil
+	}
+
+	c.FallbackPrvKey = ""
+
+	return nil, b.saveConfig(ctx, req.Storage, d.Get(keyApp).(string), c)
+}
@@ -0,0 +1,422 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/assert"
+
+	is "gotest.tools/assert/cmp"
+)
+
+// testGeneratePrvKey returns a freshly generated PEM PKCS#1 RSA private key.
+func testGeneratePrvKey(t *testing.T, bits int) (string, *rsa.PrivateKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	assert.NilError(t, err)
+
+	return strings.TrimSpace(string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))), key
+}
+
+// testJWTSignedBy reports whether the request carries an App JWT signed by the
+// given public key.
+func testJWTSignedBy(r *http.Request, pub *rsa.PublicKey) bool {
+	jwt, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return false
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil
+}
+
+// testAppServer stubs GitHub, only accepting App JWTs signed by the given key.
+func testAppServer(t *testing.T, pub *rsa.PublicKey) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(testAppHandler(t, pub))
+}
+
+// testAppHandler is the handler of testAppServer.
+func testAppHandler(t *testing.T, pub *rsa.PublicKey) http.Handler {
+	t.Helper()
+
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			t.Helper()
+
+			if !testJWTSignedBy(r, pub) {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"message":"A JSON web token could not be decoded"}`))
+
+				return
+			}
+
+			var body []byte
+			if r.URL.Path == "/app" {
+				body, _ = json.Marshal(map[string]any{
+					"id":                  testAppID1,
+					"slug":                "vault",
+					"name":                "Vault",
+					"owner":               map[string]any{"login": testOrgName1},
+					"permissions":         testPerms,
+					"events":              []string{"push"},
+					"installations_count": 2,
+				})
+				w.WriteHeader(http.StatusOK)
+			} else {
+				body, _ = json.Marshal(map[string]any{
+					"token":      testToken,
+					"expires_at": testTokenExp,
+				})
+				w.WriteHeader(http.StatusCreated)
+			}
+
+			w.Write(body)
+		},
+	)
+}
+
+func TestClient_TokenFallbackPrvKey(t *testing.T) {
+	t.Parallel()
+
+	newPrvKey, newKey := testGeneratePrvKey(t, 2048)
+
+	cases := []struct {
+		name   string
+		active string
+		fback  string
+		err    error
+	}{
+		{
+			name:   "ActiveAccepted",
+			active: newPrvKey,
+			fback:  testPrvKeyValid,
+		},
+		{
+			name:   "FallbackAccepted",
+			active: testPrvKeyValid,
+			fback:  newPrvKey,
+		},
+		{
+			name:   "NoFallback",
+			active: testPrvKeyValid,
+			err:    errUnableToCreateAccessToken,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := testAppServer(t, &newKey.PublicKey)
+			defer ts.Close()
+
+			client, err := NewClient(&Config{
+				AppID:          testAppID1,
+				PrvKey:         tc.active,
+				FallbackPrvKey: tc.fback,
+				BaseURL:        ts.URL,
+			})
+			assert.NilError(t, err)
+
+			res, err := client.Token(context.Background(), &tokenRequest{
+				InstallationID: testInsID1,
+				tokenConstraints: tokenConstraints{
+					Permissions: testPerms,
+				},
+			})
+			if tc.err != nil {
+				assert.ErrorContains(t, err, tc.err.Error())
+
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, res.Data["token"], testToken)
+		})
+	}
+}
+
+func TestBackend_PathConfigKeys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("FieldValidation", func(t *testing.T) {
+		t.Parallel()
+		testFieldValidation(t, logical.UpdateOperation, pathPatternConfigKeysPromote)
+	})
+
+	t.Run("Rotation", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b, storage := testBackend(t)
+
+		newPrvKey, newKey := testGeneratePrvKey(t, 2048)
+
+		ts := testAppServer(t, &newKey.PublicKey)
+		defer ts.Close()
+
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      configKey(testAppName1),
+			Data: map[string]any{
+				keyAppID:   testAppID1,
+				keyPrvKey:  testPrvKeyValid,
+				keyBaseURL: ts.URL,
+			},
+		})
+		assert.NilError(t, err)
+
+		// Nothing to promote yet.
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfigKeysPromote,
+			Data:      map[string]any{keyApp: testAppName1},
+		})
+		assert.ErrorContains(t, err, errNoPendingPrvKey.Error())
+
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfigKeysPending,
+			Data: map[string]any{
+				keyApp:    testAppName1,
+				keyPrvKey: newPrvKey,
+			},
+		})
+		assert.NilError(t, err)
+
+		r, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      pathPatternConfigKeys,
+			Data:      map[string]any{keyApp: testAppName1},
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, r.Data[keyPrvKey], prvKeyFingerprint(testPrvKeyValid))
+		assert.Equal(t, r.Data[keyPendingPrvKey], prvKeyFingerprint(newPrvKey))
+		assert.Equal(t, r.Data[keyFallbackPrvKey], "")
+
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfigKeysPromote,
+			Data:      map[string]any{keyApp: testAppName1},
+		})
+		assert.NilError(t, err)
+
+		c, _, err := b.AppConfig(ctx, storage, testAppName1)
+		assert.NilError(t, err)
+		assert.Equal(t, c.PrvKey, newPrvKey)
+		assert.Equal(t, c.PendingPrvKey, "")
+		assert.Equal(t, c.FallbackPrvKey, testPrvKeyValid)
+
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfigKeysRetire,
+			Data:      map[string]any{keyApp: testAppName1},
+		})
+		assert.NilError(t, err)
+
+		c, _, err = b.AppConfig(ctx, storage, testAppName1)
+		assert.NilError(t, err)
+		assert.Equal(t, c.PrvKey, newPrvKey)
+		assert.Equal(t, c.FallbackPrvKey, "")
+	})
+
+	t.Run("PendingRefused", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b, storage := testBackend(t)
+
+		_, acceptedKey := testGeneratePrvKey(t, 2048)
+
+		ts := testAppServer(t, &acceptedKey.PublicKey)
+		defer ts.Close()
+
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfig,
+			Data: map[string]any{
+				keyAppID:   testAppID1,
+				keyPrvKey:  testPrvKeyValid,
+				keyBaseURL: ts.URL,
+			},
+		})
+		assert.NilError(t, err)
+
+		unregisteredPrvKey, _ := testGeneratePrvKey(t, 2048)
+
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfigKeysPending,
+			Data:      map[string]any{keyPrvKey: unregisteredPrvKey},
+		})
+		assert.NilError(t, err)
+
+		r, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfigKeysPromote,
+		})
+		assert.ErrorContains(t, err, errPendingPrvKeyRefused.Error())
+		assert.ErrorContains(t, err, "401")
+		assert.Assert(t, is.Nil(r))
+
+		// The active key is untouched and the pending key can be discarded.
+		c, err := b.Config(ctx, storage)
+		assert.NilError(t, err)
+		assert.Equal(t, c.PrvKey, testPrvKeyValid)
+		assert.Equal(t, c.PendingPrvKey, unregisteredPrvKey)
+
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.DeleteOperation,
+			Path:      pathPatternConfigKeysPending,
+		})
+		assert.NilError(t, err)
+
+		c, err = b.Config(ctx, storage)
+		assert.NilError(t, err)
+		assert.Equal(t, c.PendingPrvKey, "")
+	})
+
+	t.Run("TransitConfigured", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b, storage := testBackend(t)
+
+		newPrvKey, newKey := testGeneratePrvKey(t, 2048)
+
+		ts := testAppServer(t, &newKey.PublicKey)
+		defer ts.Close()
+
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfig,
+			Data: map[string]any{
+				keyAppID:   testAppID1,
+				keyPrvKey:  testPrvKeyValid,
+				keyBaseURL: ts.URL,
+			},
+		})
+		assert.NilError(t, err)
+
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfigKeysPending,
+			Data:      map[string]any{keyPrvKey: newPrvKey},
+		})
+		assert.NilError(t, err)
+
+		// Signing moves to Transit after the pending key was staged.
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfig,
+			Data: map[string]any{
+				keyTransitKey: "github-app",
+				keyVaultAddr:  ts.URL,
+			},
+		})
+		assert.NilError(t, err)
+
+		r, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfigKeysPromote,
+		})
+		assert.ErrorContains(t, err, errPrvKeyRotationTransit.Error())
+		assert.Assert(t, is.Nil(r))
+
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfigKeysPending,
+			Data:      map[string]any{keyPrvKey: newPrvKey},
+		})
+		assert.ErrorContains(t, err, errPrvKeyRotationTransit.Error())
+
+		c, err := b.Config(ctx, storage)
+		assert.NilError(t, err)
+		assert.Equal(t, c.PrvKey, testPrvKeyValid)
+		assert.Equal(t, c.FallbackPrvKey, "")
+	})
+
+	t.Run("InvalidPendingPrvKey", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b, storage := testBackend(t)
+
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfig,
+			Data: map[string]any{
+				keyAppID:  testAppID1,
+				keyPrvKey: testPrvKeyValid,
+			},
+		})
+		assert.NilError(t, err)
+
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfigKeysPending,
+			Data:      map[string]any{keyPrvKey: "not a private key"},
+		})
+		assert.ErrorContains(t, err, errKeyNotPEMFormat.Error())
+	})
+
+	t.Run("AppNotConfigured", func(t *testing.T) {
+		t.Parallel()
+
+		b, storage := testBackend(t)
+
+		_, err := b.HandleRequest(context.Background(), &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      pathPatternConfigKeys,
+			Data:      map[string]any{keyApp: "nonexistent"},
+		})
+		assert.ErrorContains(t, err, errAppNotConfigured.Error())
+	})
+}
@@ -0,0 +1,215 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// pathPatternConfigPolicy is the string used to define the base path of the
+// mount policy endpoint as well as the storage path of all policies.
+const pathPatternConfigPolicy = pathPatternConfig + "/policy"
+
+// pathPatternConfigPolicyInstallations is the string used to define the base
+// path of the installation policy endpoints.
+const pathPatternConfigPolicyInstallations = pathPatternConfigPolicy + "/installations"
+
+const (
+	keyMaxPerms     = "max_permissions"
+	descMaxPerms    = "The highest access levels that permissions can be requested with, e.g. contents=read."
+	keyDeniedPerms  = "denied_permissions"
+	descDeniedPerms = "The permissions that cannot be requested at all."
+)
+
+const pathConfigPolicyHelpSyn = `
+Constrain the permissions that tokens can be requested with.
+`
+
+var pathConfigPolicyHelpDesc = fmt.Sprintf(`
+Constrain the permissions that tokens can be requested with, regardless of the
+permissions of the App.
+
+%q caps the access level of permissions and %q forbids permissions outright.
+The policy at '%s' applies to every token request of the mount. Policies at
+'%s/<installation>', where <installation> is an installation ID or an
+organization name, additionally apply to the token requests of that
+installation; the stricter of the policies wins.
+
+Policies are enforced on token requests, including those of permission sets,
+and on permission set writes. Violations are refused with a 403, logged and
+counted. Since a token requested without permissions has every permission of
+the installation, permissions must be requested explicitly whenever a policy
+applies.`, keyMaxPerms, keyDeniedPerms, pathPatternConfigPolicy, pathPatternConfigPolicyInstallations)
+
+// pathConfigPolicy defines the /github/config/policy paths on the backend.
+func (b *backend) pathConfigPolicy() []*framework.Path {
+	policyFields := map[string]*framework.FieldSchema{
+		keyMaxPerms: {
+			Type:        framework.TypeKVPairs,
+			Description: descMaxPerms,
+		},
+		keyDeniedPerms: {
+			Type:        framework.TypeCommaStringSlice,
+			Description: descDeniedPerms,
+		},
+	}
+
+	policyOperations := map[logical.Operation]framework.OperationHandler{
+		logical.ReadOperation: &framework.PathOperation{
+			Callback: withFieldValidator(b.pathConfigPolicyRead),
+		},
+		logical.UpdateOperation: &framework.PathOperation{
+			Callback: withFieldValidator(b.pathConfigPolicyWrite),
+		},
+		logical.DeleteOperation: &framework.PathOperation{
+			Callback: withFieldValidator(b.pathConfigPolicyDelete),
+		},
+	}
+
+	installationFields := map[string]*framework.FieldSchema{
+		keyInstallation: {
+			Type:        framework.TypeString,
+			Description: descInstallation,
+		},
+	}
+	maps.Copy(installationFields, policyFields)
+
+	return []*framework.Path{
+		{
+			Pattern:         pathPatternConfigPolicy,
+			Fields:          policyFields,
+			Operations:      policyOperations,
+			HelpSynopsis:    pathConfigPolicyHelpSyn,
+			HelpDescription: pathConfigPolicyHelpDesc,
+		},
+		{
+			Pattern: fmt.Sprintf("%s/%s",
+				pathPatternConfigPolicyInstallations, framework.GenericNameRegex(keyInstallation)),
+			Fields:          installationFields,
+			Operations:      policyOperations,
+			HelpSynopsis:    pathConfigPolicyHelpSyn,
+			HelpDescription: pathConfigPolicyHelpDesc,
+		},
+		{
+			Pattern: fmt.Sprintf("%s/?", pathPatternConfigPolicyInstallations),
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.pathConfigPolicyList,
+				},
+			},
+			HelpSynopsis:    pathConfigPolicyHelpSyn,
+			HelpDescription: pathConfigPolicyHelpDesc,
+		},
+	}
+}
+
+// policyInstallation returns the installation named in the request, if any.
+// An empty name refers to the mount.
+func policyInstallation(d *framework.FieldData) string {
+	installation, _ := d.GetOk(keyInstallation)
+	name, _ := installation.(string)
+
+	return name
+}
+
+// pathConfigPolicyRead corresponds to READ on /github/config/policy and
+// /github/config/policy/installations/:installation.
+func (b *backend) pathConfigPolicyRead(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	ps, err := getPolicies(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	p := ps.get(policyInstallation(d))
+	if p == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]any{
+			keyMaxPerms:    p.MaxPermissions,
+			keyDeniedPerms: p.DeniedPermissions,
+		},
+	}, nil
+}
+
+// pathConfigPolicyWrite corresponds to UPDATE on /github/config/policy and
+// /github/config/policy/installations/:installation.
+func (b *backend) pathConfigPolicyWrite(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	b.policyLock.Lock()
+	defer b.policyLock.Unlock()
+
+	ps, err := getPolicies(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	installation := policyInstallation(d)
+
+	p := ps.get(installation)
+	if p == nil {
+		p = &Policy{}
+	}
+
+	if maxPerms, ok := d.GetOk(keyMaxPerms); ok {
+		p.MaxPermissions = maxPerms.(map[string]string)
+	}
+
+	if deniedPerms, ok := d.GetOk(keyDeniedPerms); ok {
+		p.DeniedPermissions = deniedPerms.([]string)
+	}
+
+	if err = p.validate(); err != nil {
+		return nil, logical.CodedError(http.StatusBadRequest, err.Error())
+	}
+
+	ps.set(installation, p)
+
+	return nil, ps.save(ctx, req.Storage)
+}
+
+// pathConfigPolicyDelete corresponds to DELETE on /github/config/policy and
+// /github/config/policy/installations/:installation.
+func (b *backend) pathConfigPolicyDelete(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	b.policyLock.Lock()
+	defer b.policyLock.Unlock()
+
+	ps, err := getPolicies(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.set(policyInstallation(d), nil)
+
+	return nil, ps.save(ctx, req.Storage)
+}
+
+// pathConfigPolicyList corresponds to LIST on
+// /github/config/policy/installations.
+func (b *backend) pathConfigPolicyList(
+	ctx context.Context, req *logical.Request, _ *framework.FieldData,
+) (*logical.Response, error) {
+	ps, err := getPolicies(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	return logical.ListResponse(slices.Sorted(maps.Keys(ps.Installations))), nil
+}
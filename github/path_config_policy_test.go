@@ -0,0 +1,328 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/assert"
+)
+
+func TestBackend_PathConfigPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("FieldValidation", func(t *testing.T) {
+		t.Parallel()
+		testFieldValidation(t, logical.UpdateOperation, pathPatternConfigPolicy)
+	})
+
+	t.Run("HappyPath", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b, storage := testBackend(t)
+
+		for _, path := range []string{
+			pathPatternConfigPolicy,
+			pathPatternConfigPolicyInstallations + "/" + testOrgName1,
+		} {
+			// Nothing configured yet.
+			r, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.ReadOperation,
+				Path:      path,
+			})
+			assert.NilError(t, err)
+			assert.Assert(t, r == nil)
+
+			_, err = b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      path,
+				Data: map[string]any{
+					keyMaxPerms:    map[string]any{"contents": "read"},
+					keyDeniedPerms: "administration,secrets",
+				},
+			})
+			assert.NilError(t, err)
+
+			// Unspecified fields are retained on update.
+			_, err = b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      path,
+				Data:      map[string]any{keyDeniedPerms: "administration"},
+			})
+			assert.NilError(t, err)
+
+			r, err = b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.ReadOperation,
+				Path:      path,
+			})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, r.Data, map[string]any{
+				keyMaxPerms:    map[string]string{"contents": "read"},
+				keyDeniedPerms: []string{"administration"},
+			})
+		}
+
+		r, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ListOperation,
+			Path:      pathPatternConfigPolicyInstallations + "/",
+		})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r.Data["keys"], []string{testOrgName1})
+
+		// Deleting an installation policy leaves the mount policy alone.
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.DeleteOperation,
+			Path:      pathPatternConfigPolicyInstallations + "/" + testOrgName1,
+		})
+		assert.NilError(t, err)
+
+		r, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ListOperation,
+			Path:      pathPatternConfigPolicyInstallations + "/",
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, r.Data["keys"] == nil)
+
+		r, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      pathPatternConfigPolicy,
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, r != nil)
+	})
+
+	t.Run("InvalidLevel", func(t *testing.T) {
+		t.Parallel()
+
+		b, storage := testBackend(t)
+
+		_, err := b.HandleRequest(context.Background(), &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfigPolicy,
+			Data:      map[string]any{keyMaxPerms: map[string]any{"contents": "all"}},
+		})
+		assert.ErrorContains(t, err, errInvalidPolicy.Error())
+
+		var coded logical.HTTPCodedError
+		assert.Assert(t, errors.As(err, &coded))
+		assert.Equal(t, coded.Code(), http.StatusBadRequest)
+	})
+
+	t.Run("StorageFailure", func(t *testing.T) {
+		t.Parallel()
+
+		b, storage := testBackend(t, failVerbPut)
+
+		_, err := b.HandleRequest(context.Background(), &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfigPolicy,
+			Data:      map[string]any{keyDeniedPerms: "administration"},
+		})
+		assert.Assert(t, err != nil)
+	})
+}
+
+func TestBackend_PolicyEnforcement(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		mount        map[string]any
+		installation map[string]any
+		data         map[string]any
+		name         string
+		path         string
+		err          string
+	}{
+		{
+			name:  "NoPolicy",
+			path:  pathPatternToken,
+			data:  map[string]any{keyInstallationID: testInsID1},
+			mount: map[string]any{},
+		},
+		{
+			name:  "Allowed",
+			path:  pathPatternToken,
+			mount: map[string]any{keyMaxPerms: map[string]any{"contents": "read"}},
+			data: map[string]any{
+				keyInstallationID: testInsID1,
+				keyPerms:          map[string]any{"contents": "read"},
+			},
+		},
+		{
+			name:  "Exceeded",
+			path:  pathPatternToken,
+			mount: map[string]any{keyMaxPerms: map[string]any{"contents": "read"}},
+			data: map[string]any{
+				keyInstallationID: testInsID1,
+				keyPerms:          map[string]any{"contents": "write"},
+			},
+			err: "contents (requested write, maximum read)",
+		},
+		{
+			name:  "Unconstrained",
+			path:  pathPatternToken,
+			mount: map[string]any{keyDeniedPerms: "administration"},
+			data:  map[string]any{keyInstallationID: testInsID1},
+			err:   "permissions must be requested explicitly",
+		},
+		{
+			name:         "InstallationByOrgName",
+			path:         pathPatternToken,
+			installation: map[string]any{keyDeniedPerms: "issues"},
+			data: map[string]any{
+				keyInstallationID: testInsID1,
+				keyPerms:          map[string]any{"issues": "read"},
+			},
+			err: "issues (denied)",
+		},
+		{
+			name:         "StricterWins",
+			path:         pathPatternToken,
+			mount:        map[string]any{keyMaxPerms: map[string]any{"contents": "write"}},
+			installation: map[string]any{keyMaxPerms: map[string]any{"contents": "read"}},
+			data: map[string]any{
+				keyOrgName: testOrgName1,
+				keyPerms:   map[string]any{"contents": "write"},
+			},
+			err: "contents (requested write, maximum read)",
+		},
+		{
+			name:  "PermissionSet",
+			path:  pathPatternPermissionSet + "/foo",
+			mount: map[string]any{keyDeniedPerms: "administration"},
+			data: map[string]any{
+				keyInstallationID: testInsID1,
+				keyPerms:          map[string]any{"administration": "read"},
+			},
+			err: "administration (denied)",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b, storage := testBackend(t)
+
+			ts := testRepositoriesServer(t, &testRepositoriesRecorder{}, http.StatusOK)
+			defer ts.Close()
+
+			_, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      pathPatternConfig,
+				Data: map[string]any{
+					keyAppID:   testAppID1,
+					keyPrvKey:  testPrvKeyValid,
+					keyBaseURL: ts.URL,
+				},
+			})
+			assert.NilError(t, err)
+
+			for path, data := range map[string]map[string]any{
+				pathPatternConfigPolicy:                                   tc.mount,
+				pathPatternConfigPolicyInstallations + "/" + testOrgName1: tc.installation,
+			} {
+				if data == nil {
+					continue
+				}
+
+				_, err = b.HandleRequest(ctx, &logical.Request{
+					Storage:   storage,
+					Operation: logical.UpdateOperation,
+					Path:      path,
+					Data:      data,
+				})
+				assert.NilError(t, err)
+			}
+
+			_, err = b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      tc.path,
+				Data:      tc.data,
+			})
+
+			if tc.err == "" {
+				assert.NilError(t, err)
+
+				return
+			}
+
+			assert.ErrorContains(t, err, fmt.Sprintf("%s: %s", errPolicyViolation, tc.err))
+
+			var coded logical.HTTPCodedError
+			assert.Assert(t, errors.As(err, &coded))
+			assert.Equal(t, coded.Code(), http.StatusForbidden)
+		})
+	}
+}
+
+func TestBackend_PolicyEnforcementPermissionSetToken(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := testBackend(t)
+
+	ts := testRepositoriesServer(t, &testRepositoriesRecorder{}, http.StatusOK)
+	defer ts.Close()
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			keyAppID:   testAppID1,
+			keyPrvKey:  testPrvKeyValid,
+			keyBaseURL: ts.URL,
+		},
+	})
+	assert.NilError(t, err)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternPermissionSet + "/foo",
+		Data: map[string]any{
+			keyInstallationID: testInsID1,
+			keyPerms:          map[string]any{"secrets": "write"},
+		},
+	})
+	assert.NilError(t, err)
+
+	// A policy written afterwards still applies to the permission set.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternConfigPolicyInstallations + "/" + testOrgName1,
+		Data:      map[string]any{keyMaxPerms: map[string]any{"secrets": "read"}},
+	})
+	assert.NilError(t, err)
+
+	violations := policyViolations.WithLabelValues(policySourcePermissionSet, "secrets")
+	before := testutil.ToFloat64(violations)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternToken + "/foo",
+	})
+	assert.ErrorContains(t, err, errPolicyViolation.Error())
+	assert.Equal(t, testutil.ToFloat64(violations), before+1)
+}
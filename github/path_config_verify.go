@@ -0,0 +1,106 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// pathPatternConfigVerify is the string used to define the config
+// verification endpoint.
+const pathPatternConfigVerify = pathPatternConfig + "/verify"
+
+const (
+	keyVerify  = "verify"
+	descVerify = "Verify the configuration by authenticating as the GitHub App before persisting it."
+)
+
+const (
+	errConfigVerification = Error("configuration was not accepted by GitHub")
+	errAppIDMismatch      = Error("GitHub authenticated a different App than configured")
+)
+
+const pathConfigVerifyHelpSyn = `
+Verify the GitHub App configuration against GitHub.
+`
+
+var pathConfigVerifyHelpDesc = fmt.Sprintf(`
+Verify the GitHub App configuration by authenticating as the App against the
+configured GitHub, reporting the App's slug, owner, permissions, events and
+installation count.
+
+This catches mistakes such as a wrong %q, a private key belonging to another
+App or a bad %q before the first token request fails. Set %q on writes to
+'config' or 'config/apps/<name>' to verify before the configuration is
+persisted. Pass %q to verify a named app.`,
+	keyAppID, keyBaseURL, keyVerify, keyApp)
+
+// pathConfigVerify defines the /github/config/verify path on the backend.
+func (b *backend) pathConfigVerify() *framework.Path {
+	return &framework.Path{
+		Pattern: pathPatternConfigVerify,
+		Fields: map[string]*framework.FieldSchema{
+			keyApp: {
+				Type:        framework.TypeString,
+				Description: descApp,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: withFieldValidator(b.pathConfigVerifyRead),
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: withFieldValidator(b.pathConfigVerifyRead),
+			},
+		},
+		HelpSynopsis:    pathConfigVerifyHelpSyn,
+		HelpDescription: pathConfigVerifyHelpDesc,
+	}
+}
+
+// pathConfigVerifyRead corresponds to READ and UPDATE on /github/config/verify.
+func (b *backend) pathConfigVerifyRead(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	c, err := b.existingAppConfig(ctx, req, d)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.verifyConfig(ctx, c)
+}
+
+// verifyConfig authenticates as the App of the given configuration, reporting
+// its details or a 400 with the GitHub error should it not be accepted.
+func (b *backend) verifyConfig(ctx context.Context, c *Config) (*logical.Response, error) {
+	client, err := NewClient(c, WithLogger(b.Logger()))
+	if err != nil {
+		return nil, logical.CodedError(400, fmt.Sprintf("%s: %s", errConfigVerification, err))
+	}
+
+	a, err := client.App(ctx)
+	if err != nil {
+		return nil, logical.CodedError(400, fmt.Sprintf("%s: %s", errConfigVerification, err))
+	}
+
+	if a.ID != c.AppID {
+		return nil, logical.CodedError(400, fmt.Sprintf("%s: %s: %d != %d",
+			errConfigVerification, errAppIDMismatch, a.ID, c.AppID))
+	}
+
+	return &logical.Response{
+		Data: map[string]any{
+			keyAppID:              a.ID,
+			"slug":                a.Slug,
+			"name":                a.Name,
+			"owner":               a.Owner.Login,
+			"permissions":         a.Permissions,
+			"events":              a.Events,
+			"installations_count": a.InstallationsCount,
+		},
+	}, nil
+}
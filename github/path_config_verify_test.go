@@ -0,0 +1,178 @@
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/assert"
+
+	is "gotest.tools/assert/cmp"
+)
+
+func TestBackend_PathConfigVerify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("FieldValidation", func(t *testing.T) {
+		t.Parallel()
+		testFieldValidation(t, logical.ReadOperation, pathPatternConfigVerify)
+	})
+
+	t.Run("HappyPath", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b, storage := testBackend(t)
+
+		prvKey, key := testGeneratePrvKey(t, 2048)
+
+		ts := testAppServer(t, &key.PublicKey)
+		defer ts.Close()
+
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      configKey(testAppName1),
+			Data: map[string]any{
+				keyAppID:   testAppID1,
+				keyPrvKey:  prvKey,
+				keyBaseURL: ts.URL,
+			},
+		})
+		assert.NilError(t, err)
+
+		r, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      pathPatternConfigVerify,
+			Data:      map[string]any{keyApp: testAppName1},
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, r.Data[keyAppID], testAppID1)
+		assert.Equal(t, r.Data["slug"], "vault")
+		assert.Equal(t, r.Data["owner"], testOrgName1)
+		assert.DeepEqual(t, r.Data["permissions"], testPerms)
+		assert.DeepEqual(t, r.Data["events"], []string{"push"})
+		assert.Equal(t, r.Data["installations_count"], 2)
+	})
+
+	t.Run("NotAccepted", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b, storage := testBackend(t)
+
+		_, key := testGeneratePrvKey(t, 2048)
+
+		ts := testAppServer(t, &key.PublicKey)
+		defer ts.Close()
+
+		// The configured key belongs to another App.
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfig,
+			Data: map[string]any{
+				keyAppID:   testAppID1,
+				keyPrvKey:  testPrvKeyValid,
+				keyBaseURL: ts.URL,
+			},
+		})
+		assert.NilError(t, err)
+
+		r, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      pathPatternConfigVerify,
+		})
+		assert.ErrorContains(t, err, errConfigVerification.Error())
+		assert.ErrorContains(t, err, "401")
+		assert.Assert(t, is.Nil(r))
+	})
+
+	t.Run("AppNotConfigured", func(t *testing.T) {
+		t.Parallel()
+
+		b, storage := testBackend(t)
+
+		_, err := b.HandleRequest(context.Background(), &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      pathPatternConfigVerify,
+			Data:      map[string]any{keyApp: "nonexistent"},
+		})
+		assert.ErrorContains(t, err, errAppNotConfigured.Error())
+	})
+}
+
+func TestBackend_PathConfigWriteVerify(t *testing.T) {
+	t.Parallel()
+
+	prvKey, key := testGeneratePrvKey(t, 2048)
+
+	cases := []struct {
+		err    error
+		name   string
+		prvKey string
+		appID  int
+	}{
+		{
+			name:   "Verified",
+			prvKey: prvKey,
+			appID:  testAppID1,
+		},
+		{
+			name:   "KeyOfAnotherApp",
+			prvKey: testPrvKeyValid,
+			appID:  testAppID1,
+			err:    errConfigVerification,
+		},
+		{
+			name:   "AppIDMismatch",
+			prvKey: prvKey,
+			appID:  testAppID2,
+			err:    errAppIDMismatch,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b, storage := testBackend(t)
+
+			ts := testAppServer(t, &key.PublicKey)
+			defer ts.Close()
+
+			r, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      pathPatternConfig,
+				Data: map[string]any{
+					keyAppID:   tc.appID,
+					keyPrvKey:  tc.prvKey,
+					keyBaseURL: ts.URL,
+					keyVerify:  true,
+				},
+			})
+
+			exists, existsErr := b.configExistenceCheck(ctx, &logical.Request{Storage: storage}, "")
+			assert.NilError(t, existsErr)
+
+			if tc.err != nil {
+				assert.ErrorContains(t, err, tc.err.Error())
+				assert.Assert(t, is.Nil(r))
+
+				// The bad configuration was not persisted.
+				assert.Assert(t, !exists)
+
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, r.Data["slug"], "vault")
+			assert.Assert(t, exists)
+		})
+	}
+}
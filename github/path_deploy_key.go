@@ -0,0 +1,377 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// pathPatternDeployKey is the string used to define the base path of the
+// deploy key endpoints.
+const pathPatternDeployKey = "deploykey"
+
+// pathPatternDeployKeyRoles is the string used to define the base path of the
+// deploy key role endpoints as well as the storage path of the roles.
+const pathPatternDeployKeyRoles = pathPatternDeployKey + "/roles"
+
+// deployKeySecretType is the type of the Vault leases of deploy keys.
+const deployKeySecretType = "github_deploy_key"
+
+const (
+	keyRepository  = "repository"
+	descRepository = "The name of the repository that deploy keys are registered on."
+	keyReadOnly    = "read_only"
+	descReadOnly   = "Whether deploy keys only have read access to the repository."
+	keyDeployKeyID = "key_id"
+	keyDeployOwner = "owner"
+)
+
+const (
+	errDeployKeyRoleNameEmpty = Error("deploy key role name empty")
+	errUnableToGetDeployRole  = Error("unable to get deploy key role")
+)
+
+const (
+	pathDeployKeyRoleHelpSyn  = `Read/write roles for GitHub deploy keys.`
+	pathDeployKeyRoleHelpDesc = `
+This path allows you to create roles that deploy keys are generated from at
+'deploykey/<role>'. A role pins the repository that its deploy keys are
+registered on, and whether they are read-only. The following is a sample
+payload:
+
+{
+	"org_name": "acme",
+	"repository": "website",
+	"read_only": true,
+	"ttl": 3600
+}
+`
+	pathListDeployKeyRolesHelpSyn  = `List existing deploy key roles.`
+	pathListDeployKeyRolesHelpDesc = `List created deploy key roles.`
+	pathDeployKeyHelpSyn           = `Generate a deploy key from a role.`
+	pathDeployKeyHelpDesc          = `
+This path generates an ed25519 SSH key pair, registers its public key as a
+deploy key on the repository of the role, and returns the private key as a
+Vault lease. The deploy key is deleted from the repository when its lease is
+revoked or expires.
+
+The App needs the 'administration' write permission on the repository.
+`
+)
+
+// deployKeyRole models the stored role that deploy keys are generated from.
+type deployKeyRole struct {
+	Name           string
+	OrgName        string
+	Repository     string
+	App            string `json:",omitempty"`
+	InstallationID int    `json:",omitempty"`
+	ReadOnly       bool
+	roleTTL
+}
+
+func (r *deployKeyRole) save(ctx context.Context, s logical.Storage) error {
+	if r.Name == "" {
+		return errDeployKeyRoleNameEmpty
+	}
+
+	return putRole(ctx, s, pathPatternDeployKeyRoles, r.Name, r)
+}
+
+func getDeployKeyRole(ctx context.Context, name string, s logical.Storage) (*deployKeyRole, error) {
+	return getRole[deployKeyRole](ctx, s, pathPatternDeployKeyRoles, name)
+}
+
+// pathDeployKey defines the /github/deploykey paths on the backend.
+func (b *backend) pathDeployKey() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: fmt.Sprintf("%s/%s", pathPatternDeployKeyRoles, framework.GenericNameRegex("name")),
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Required. Name of the deploy key role.",
+				},
+				keyOrgName: {
+					Type:        framework.TypeString,
+					Description: "Required. The organization (or user) that owns the repository.",
+				},
+				keyRepository: {
+					Type:        framework.TypeString,
+					Description: "Required. " + descRepository,
+				},
+				keyInstallationID: {
+					Type:        framework.TypeInt,
+					Description: "The ID of the App installation on the organization, saving a lookup.",
+				},
+				keyReadOnly: {
+					Type:        framework.TypeBool,
+					Description: descReadOnly,
+					Default:     true,
+				},
+				keyApp: {
+					Type:        framework.TypeString,
+					Description: descApp,
+				},
+				keyTTL: {
+					Type:        framework.TypeDurationSecond,
+					Description: "The TTL of the leases of deploy keys. The deploy key is deleted when its lease expires.",
+				},
+				keyMaxTTL: {
+					Type:        framework.TypeDurationSecond,
+					Description: "The maximum TTL of the leases of deploy keys.",
+				},
+			},
+			ExistenceCheck: pathRoleExistenceCheck[deployKeyRole](pathPatternDeployKeyRoles),
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathDeployKeyRoleRead),
+				},
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathDeployKeyRoleWrite),
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathDeployKeyRoleWrite),
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: withFieldValidator(pathRoleDelete(pathPatternDeployKeyRoles, &b.deployKeyLock)),
+				},
+			},
+			HelpSynopsis:    pathDeployKeyRoleHelpSyn,
+			HelpDescription: pathDeployKeyRoleHelpDesc,
+		},
+		{
+			Pattern: fmt.Sprintf("%s/?", pathPatternDeployKeyRoles),
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: pathRoleList(pathPatternDeployKeyRoles),
+				},
+			},
+			HelpSynopsis:    pathListDeployKeyRolesHelpSyn,
+			HelpDescription: pathListDeployKeyRolesHelpDesc,
+		},
+		{
+			Pattern: fmt.Sprintf("%s/%s", pathPatternDeployKey, framework.GenericNameRegex("role")),
+			Fields: map[string]*framework.FieldSchema{
+				"role": {
+					Type:        framework.TypeString,
+					Description: "Required. Name of the deploy key role.",
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathDeployKeyWrite),
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathDeployKeyWrite),
+				},
+			},
+			HelpSynopsis:    pathDeployKeyHelpSyn,
+			HelpDescription: pathDeployKeyHelpDesc,
+		},
+	}
+}
+
+// deployKeySecret defines the secret type of deploy keys, which are deleted
+// from their repository when revoked.
+func (b *backend) deployKeySecret() *framework.Secret {
+	return &framework.Secret{
+		Type: deployKeySecretType,
+		Fields: map[string]*framework.FieldSchema{
+			"private_key": {
+				Type:        framework.TypeString,
+				Description: "The private key of the deploy key.",
+			},
+		},
+		Revoke: b.revokeDeployKey,
+	}
+}
+
+// pathDeployKeyRoleRead corresponds to READ on /github/deploykey/roles/:name.
+func (b *backend) pathDeployKeyRoleRead(
+	ctx context.Context, req *logical.Request, d *framework.FieldData,
+) (*logical.Response, error) {
+	role, err := getDeployKeyRole(ctx, d.Get("name").(string), req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]any{
+			keyOrgName:        role.OrgName,
+			keyRepository:     role.Repository,
+			keyInstallationID: role.InstallationID,
+			keyReadOnly:       role.ReadOnly,
+			keyApp:            role.App,
+			keyTTL:            int64(role.TTL.Seconds()),
+			keyMaxTTL:         int64(role.MaxTTL.Seconds()),
+		},
+	}, nil
+}
+
+// pathDeployKeyRoleWrite corresponds to CREATE and UPDATE on
+// /github/deploykey/roles/:name.
+func (b *backend) pathDeployKeyRoleWrite(
+	ctx context.Context, req *logical.Request, d *framework.FieldData,
+) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	b.deployKeyLock.Lock()
+	defer b.deployKeyLock.Unlock()
+
+	role, err := getDeployKeyRole(ctx, name, req.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", errUnableToGetDeployRole, name, err)
+	}
+
+	if role == nil {
+		role = &deployKeyRole{Name: name, ReadOnly: d.Get(keyReadOnly).(bool)}
+	}
+
+	if orgName, ok := d.GetOk(keyOrgName); ok {
+		role.OrgName = orgName.(string)
+	}
+
+	if repo, ok := d.GetOk(keyRepository); ok {
+		role.Repository = repo.(string)
+	}
+
+	if installationID, ok := d.GetOk(keyInstallationID); ok {
+		role.InstallationID = installationID.(int)
+	}
+
+	if readOnly, ok := d.GetOk(keyReadOnly); ok {
+		role.ReadOnly = readOnly.(bool)
+	}
+
+	if app, ok := d.GetOk(keyApp); ok {
+		role.App = app.(string)
+	}
+
+	if err = role.roleTTL.update(d); err != nil {
+		return nil, logical.CodedError(http.StatusBadRequest, err.Error())
+	}
+
+	if role.OrgName == "" || role.Repository == "" {
+		return logical.ErrorResponse("%s and %s are required parameters", keyOrgName, keyRepository), nil
+	}
+
+	return nil, role.save(ctx, req.Storage)
+}
+
+// pathDeployKeyWrite corresponds to READ and UPDATE on /github/deploykey/:role.
+func (b *backend) pathDeployKeyWrite(
+	ctx context.Context, req *logical.Request, d *framework.FieldData,
+) (*logical.Response, error) {
+	name := d.Get("role").(string)
+
+	role, err := getDeployKeyRole(ctx, name, req.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", errUnableToGetDeployRole, name, err)
+	}
+
+	if role == nil {
+		return logical.ErrorResponse("deploy key role '%s' does not exist", name), nil
+	}
+
+	client, done, err := b.Client(ctx, req.Storage, role.App)
+	if err != nil {
+		return nil, err
+	}
+
+	defer done()
+
+	tokReq := &tokenRequest{OrgName: role.OrgName, InstallationID: role.InstallationID, App: role.App}
+	if err = client.CheckInstallationAllowed(ctx, tokReq); err != nil {
+		return nil, err
+	}
+
+	if tokReq.InstallationID == 0 {
+		if tokReq.InstallationID, err = client.installationID(ctx, tokReq.OrgName); err != nil {
+			return nil, err
+		}
+	}
+
+	title := fmt.Sprintf("vault-%s-%d", name, time.Now().Unix())
+
+	publicKey, privateKey, err := generateDeployKey(title)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := client.CreateDeployKey(ctx, tokReq.InstallationID, role.OrgName, role.Repository, &deployKey{
+		Key:      publicKey,
+		Title:    title,
+		ReadOnly: role.ReadOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b.Logger().Debug("created deploy key",
+		keyOrgName, role.OrgName,
+		keyRepository, role.Repository,
+		keyDeployKeyID, key.ID,
+	)
+
+	res := b.Secret(deployKeySecretType).Response(map[string]any{
+		"private_key":  privateKey,
+		"public_key":   publicKey,
+		keyDeployKeyID: key.ID,
+		"title":        title,
+		keyOrgName:     role.OrgName,
+		keyRepository:  role.Repository,
+		keyReadOnly:    role.ReadOnly,
+	}, map[string]any{
+		keyApp:            role.App,
+		keyInstallationID: strconv.Itoa(tokReq.InstallationID),
+		keyDeployOwner:    role.OrgName,
+		keyRepository:     role.Repository,
+		keyDeployKeyID:    strconv.Itoa(key.ID),
+	})
+	ttl, maxTTL := b.leaseTTLs(role.TTL, role.MaxTTL)
+	res.Secret.TTL = ttl
+	res.Secret.MaxTTL = maxTTL
+
+	return res, nil
+}
+
+// revokeDeployKey handles Vault lease revocations of deploy keys by deleting
+// them from their repository.
+func (b *backend) revokeDeployKey(
+	ctx context.Context, req *logical.Request, _ *framework.FieldData,
+) (*logical.Response, error) {
+	app, _ := req.Secret.InternalData[keyApp].(string)
+	owner, _ := req.Secret.InternalData[keyDeployOwner].(string)
+	repo, _ := req.Secret.InternalData[keyRepository].(string)
+	installationID, _ := strconv.Atoi(fmt.Sprint(req.Secret.InternalData[keyInstallationID]))
+	id, _ := strconv.Atoi(fmt.Sprint(req.Secret.InternalData[keyDeployKeyID]))
+
+	client, done, err := b.Client(ctx, req.Storage, app)
+	if err != nil {
+		return nil, err
+	}
+
+	defer done()
+
+	if err = client.DeleteDeployKey(ctx, installationID, owner, repo, id); err != nil {
+		return nil, err
+	}
+
+	b.Logger().Debug("deleted deploy key",
+		keyOrgName, owner,
+		keyRepository, repo,
+		keyDeployKeyID, id,
+	)
+
+	return nil, nil
+}
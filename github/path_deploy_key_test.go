@@ -0,0 +1,232 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+const testDeployKeyID = 42
+
+func TestBackend_PathDeployKeyRole(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := testBackend(t)
+
+	// Required parameters.
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternDeployKeyRoles + "/deploy",
+		Data:      map[string]any{keyOrgName: testOrgName1},
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, r.IsError())
+	assert.ErrorContains(t, r.Error(), "org_name and repository are required parameters")
+
+	// TTL within max_ttl.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternDeployKeyRoles + "/deploy",
+		Data: map[string]any{
+			keyOrgName:    testOrgName1,
+			keyRepository: "website",
+			keyTTL:        "2h",
+			keyMaxTTL:     "1h",
+		},
+	})
+	assert.ErrorContains(t, err, errTTLExceedsMaxTTL.Error())
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternDeployKeyRoles + "/deploy",
+		Data: map[string]any{
+			keyOrgName:    testOrgName1,
+			keyRepository: "website",
+			keyTTL:        "1h",
+		},
+	})
+	assert.NilError(t, err)
+
+	// Updates keep what is not written.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternDeployKeyRoles + "/deploy",
+		Data:      map[string]any{keyReadOnly: false},
+	})
+	assert.NilError(t, err)
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternDeployKeyRoles + "/deploy",
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Data, map[string]any{
+		keyOrgName:        testOrgName1,
+		keyRepository:     "website",
+		keyInstallationID: 0,
+		keyReadOnly:       false,
+		keyApp:            "",
+		keyTTL:            int64(3600),
+		keyMaxTTL:         int64(0),
+	})
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ListOperation,
+		Path:      pathPatternDeployKeyRoles,
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Data["keys"], []string{"deploy"})
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.DeleteOperation,
+		Path:      pathPatternDeployKeyRoles + "/deploy",
+	})
+	assert.NilError(t, err)
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternDeployKeyRoles + "/deploy",
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, is.Nil(r))
+}
+
+func TestBackend_PathDeployKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := newTestGitHubServer(t)
+	b, storage := testGitHubBackend(t, ts)
+
+	keysPath := fmt.Sprintf("/repos/%s/website/keys", testOrgName1)
+
+	var (
+		created []deployKey
+		deleted []string
+	)
+
+	ts.handle("POST "+keysPath, func(w http.ResponseWriter, r *http.Request) {
+		var key deployKey
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&key))
+		created = append(created, key)
+
+		key.ID = testDeployKeyID
+
+		w.WriteHeader(http.StatusCreated)
+		assert.NilError(t, json.NewEncoder(w).Encode(key))
+	})
+	ts.handle(fmt.Sprintf("DELETE %s/%d", keysPath, testDeployKeyID), func(w http.ResponseWriter, r *http.Request) {
+		deleted = append(deleted, r.URL.Path)
+
+		// Deleted keys no longer exist.
+		if len(deleted) > 1 {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// Unknown roles are refused.
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternDeployKey + "/deploy",
+	})
+	assert.NilError(t, err)
+	assert.ErrorContains(t, r.Error(), "deploy key role 'deploy' does not exist")
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternDeployKeyRoles + "/deploy",
+		Data: map[string]any{
+			keyOrgName:    testOrgName1,
+			keyRepository: "website",
+			keyTTL:        "10m",
+		},
+	})
+	assert.NilError(t, err)
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternDeployKey + "/deploy",
+	})
+	assert.NilError(t, err)
+
+	// The generated public key is registered read-only, as the installation
+	// limited to the repository.
+	ts.mu.Lock()
+	assert.Equal(t, len(created), 1)
+	assert.Equal(t, created[0].Key, r.Data["public_key"])
+	assert.Equal(t, created[0].Title, r.Data["title"])
+	assert.Assert(t, created[0].ReadOnly)
+	ts.mu.Unlock()
+
+	assert.DeepEqual(t, ts.tokenRequests(), []tokenConstraints{{
+		Repositories: []string{"website"},
+		Permissions:  deployKeysTokenPermissions,
+	}})
+
+	assert.Assert(t, is.Contains(r.Data["private_key"].(string), "OPENSSH PRIVATE KEY"))
+	assert.Equal(t, r.Data[keyDeployKeyID], testDeployKeyID)
+	assert.Equal(t, r.Data[keyRepository], "website")
+	assert.Equal(t, r.Secret.InternalData["secret_type"], deployKeySecretType)
+	assert.Equal(t, r.Secret.TTL, 10*time.Minute)
+
+	// Lease TTLs are capped by the maximum TTL of the mount.
+	b.System().(*logical.StaticSystemView).MaxLeaseTTLVal = time.Hour
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternDeployKeyRoles + "/deploy",
+		Data:      map[string]any{keyTTL: "2h"},
+	})
+	assert.NilError(t, err)
+
+	capped, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternDeployKey + "/deploy",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, capped.Secret.TTL, time.Hour)
+	assert.Equal(t, capped.Secret.MaxTTL, time.Hour)
+
+	// Revoking the lease deletes the deploy key, even if already deleted.
+	for range 2 {
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.RevokeOperation,
+			Secret:    r.Secret,
+		})
+		assert.NilError(t, err)
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	assert.DeepEqual(t, deleted, []string{
+		fmt.Sprintf("/repos/%s/website/keys/%d", testOrgName1, testDeployKeyID),
+		fmt.Sprintf("/repos/%s/website/keys/%d", testOrgName1, testDeployKeyID),
+	})
+}
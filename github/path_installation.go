@@ -0,0 +1,160 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	keyInstallation  = "installation"
+	descInstallation = "The ID of the installation, or the name of the organization (or user) it is on."
+)
+
+const errInstallationNameReserved = Error("installation name is reserved, use the installation ID instead")
+
+const (
+	pathInstallationHelpSyn = `
+Describe a GitHub App installation associated with this plugin's configuration.
+`
+	pathInstallationHelpDesc = `
+This endpoint returns the details of an installation of the App, by its
+installation ID or by the name of the organization (or user) it is on: the
+account and its type (Organization, User or Enterprise), the target ID, whether
+all or only selected repositories are accessible, the permissions granted and
+events subscribed to, and when the installation was created and suspended (if
+it is).
+
+A purely numeric value is treated as an installation ID. The name 'refresh' is
+reserved for 'installations/refresh', so an installation on an organization of
+that name is described by its installation ID instead.
+`
+)
+
+func (b *backend) pathInstallation() *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%s/%s", pathPatternInstallations, framework.GenericNameRegex(keyInstallation)),
+		Fields: map[string]*framework.FieldSchema{
+			keyInstallation: {
+				Type:        framework.TypeString,
+				Description: descInstallation,
+			},
+			keyApp: {
+				Type:        framework.TypeString,
+				Description: descApp,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: withFieldValidator(b.pathInstallationRead),
+			},
+		},
+		HelpSynopsis:    pathInstallationHelpSyn,
+		HelpDescription: pathInstallationHelpDesc,
+	}
+}
+
+// pathInstallationRead corresponds to READ on /github/installations/:installation.
+func (b *backend) pathInstallationRead(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	client, done, err := b.Client(ctx, req.Storage, d.Get(keyApp).(string))
+	if err != nil {
+		return nil, err
+	}
+
+	defer done()
+
+	inst, err := resolveInstallation(ctx, client, d.Get(keyInstallation).(string))
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{Data: installationData(inst)}, nil
+}
+
+// resolveInstallationID returns the installation ID of the App identified by
+// an installation ID or the name of the organization it is on. Organizations
+// the App is not installed on are reported as not found.
+func resolveInstallationID(ctx context.Context, client *Client, idOrOrg string) (int, error) {
+	if installationID, err := strconv.Atoi(idOrOrg); err == nil {
+		return installationID, nil
+	}
+
+	// The name is that of the installations refresh endpoint.
+	if strings.EqualFold(idOrOrg, installationsRefresh) {
+		return 0, logical.CodedError(http.StatusBadRequest,
+			fmt.Sprintf("%s: %s", errInstallationNameReserved, idOrOrg))
+	}
+
+	installationID, err := client.installationID(ctx, idOrOrg)
+	if err != nil {
+		if errors.Is(err, errAppNotInstalled) {
+			return 0, logical.CodedError(http.StatusNotFound,
+				fmt.Sprintf("%s: %s", errAppNotInstalled, idOrOrg))
+		}
+
+		return 0, err
+	}
+
+	return installationID, nil
+}
+
+// resolveInstallation fetches the installation of the App identified by an
+// installation ID or the name of the organization it is on. Unknown
+// installations are reported as not found.
+func resolveInstallation(ctx context.Context, client *Client, idOrOrg string) (*installation, error) {
+	installationID, err := resolveInstallationID(ctx, client, idOrOrg)
+	if err != nil {
+		return nil, err
+	}
+
+	inst, err := client.Installation(ctx, installationID)
+	if err != nil {
+		if errors.Is(err, errInstallationNotFound) {
+			return nil, logical.CodedError(http.StatusNotFound, err.Error())
+		}
+
+		return nil, err
+	}
+
+	return inst, nil
+}
+
+// installationData formats an installation for a response.
+func installationData(inst *installation) map[string]any {
+	accountName, accountType := inst.Account.Login, inst.TargetType
+	if accountName == "" {
+		accountName = inst.Account.Slug
+	}
+
+	if accountType == "" {
+		accountType = inst.Account.Type
+	}
+
+	var suspendedAt string
+	if inst.SuspendedAt != nil {
+		suspendedAt = inst.SuspendedAt.UTC().Format(time.RFC3339)
+	}
+
+	return map[string]any{
+		"id":                   inst.ID,
+		"account":              accountName,
+		"account_type":         accountType,
+		"target_id":            inst.TargetID,
+		"repository_selection": inst.RepositorySelection,
+		"permissions":          inst.Permissions,
+		"events":               inst.Events,
+		"created_at":           inst.CreatedAt.UTC().Format(time.RFC3339),
+		"suspended_at":         suspendedAt,
+	}
+}
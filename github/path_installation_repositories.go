@@ -0,0 +1,102 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	keyRevoke  = "revoke"
+	descRevoke = "Revoke the internal token used to list the repositories afterwards."
+)
+
+const (
+	pathInstallationRepositoriesHelpSyn = `
+List the repositories accessible to a GitHub App installation.
+`
+	pathInstallationRepositoriesHelpDesc = `
+This endpoint lists the repositories accessible to an installation of the App,
+by installation ID or by the name of the organization (or user) it is on, with
+their IDs, names, visibility and whether they are archived. It helps find out
+why a token request naming repositories is refused.
+
+The repositories are listed with a short-lived internal token of the
+installation, limited to reading metadata, which is revoked afterwards unless
+'revoke' is false.
+`
+)
+
+func (b *backend) pathInstallationRepositories() *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%s/%s/repositories",
+			pathPatternInstallations, framework.GenericNameRegex(keyInstallation)),
+		Fields: map[string]*framework.FieldSchema{
+			keyInstallation: {
+				Type:        framework.TypeString,
+				Description: descInstallation,
+			},
+			keyApp: {
+				Type:        framework.TypeString,
+				Description: descApp,
+			},
+			keyRevoke: {
+				Type:        framework.TypeBool,
+				Description: descRevoke,
+				Default:     true,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: withFieldValidator(b.pathInstallationRepositoriesRead),
+			},
+		},
+		HelpSynopsis:    pathInstallationRepositoriesHelpSyn,
+		HelpDescription: pathInstallationRepositoriesHelpDesc,
+	}
+}
+
+// pathInstallationRepositoriesRead corresponds to READ on
+// /github/installations/:installation/repositories.
+func (b *backend) pathInstallationRepositoriesRead(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	client, done, err := b.Client(ctx, req.Storage, d.Get(keyApp).(string))
+	if err != nil {
+		return nil, err
+	}
+
+	defer done()
+
+	installationID, err := resolveInstallationID(ctx, client, d.Get(keyInstallation).(string))
+	if err != nil {
+		return nil, err
+	}
+
+	repos, err := client.InstallationRepositories(ctx, installationID, d.Get(keyRevoke).(bool))
+	if err != nil {
+		return nil, err
+	}
+
+	reposData := make([]any, 0, len(repos))
+	for _, repo := range repos {
+		reposData = append(reposData, map[string]any{
+			"id":         repo.ID,
+			"name":       repo.Name,
+			"full_name":  repo.FullName,
+			"visibility": repo.Visibility,
+			"archived":   repo.Archived,
+		})
+	}
+
+	return &logical.Response{
+		Data: map[string]any{
+			keyInstallationID: installationID,
+			"repositories":    reposData,
+		},
+	}, nil
+}
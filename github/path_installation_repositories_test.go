@@ -0,0 +1,227 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/assert"
+)
+
+// testRepositoriesRecorder records the requests of interest made to a
+// testRepositoriesServer.
+type testRepositoriesRecorder struct {
+	tokens      []tokenConstraints
+	mu          sync.Mutex
+	revocations atomic.Int32
+}
+
+// minted returns the constraints of the tokens created so far.
+func (r *testRepositoriesRecorder) minted() []tokenConstraints {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.tokens)
+}
+
+// testRepositoriesServer stubs GitHub, serving two pages of repositories to
+// installation tokens of testInsID1 and recording token creations and
+// revocations.
+func testRepositoriesServer(t *testing.T, rec *testRepositoriesRecorder, reposStatus int) *httptest.Server {
+	t.Helper()
+
+	var ts *httptest.Server
+
+	ts = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			t.Helper()
+
+			switch r.URL.Path {
+			case "/app/installations":
+				body, _ := json.Marshal([]map[string]any{{
+					"id":      testInsID1,
+					"account": map[string]any{"login": testOrgName1},
+				}})
+				w.Write(body)
+			case fmt.Sprintf("/app/installations/%d/access_tokens", testInsID1):
+				var constraints tokenConstraints
+				assert.NilError(t, json.NewDecoder(r.Body).Decode(&constraints))
+
+				rec.mu.Lock()
+				rec.tokens = append(rec.tokens, constraints)
+				rec.mu.Unlock()
+
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"token":"` + testToken + `"}`))
+			case "/installation/repositories":
+				assert.Equal(t, r.Header.Get("Authorization"), "Bearer "+testToken)
+				assert.Equal(t, r.URL.Query().Get("per_page"), repositoriesPageSize)
+
+				w.Header().Set(headerRateLimitLimit, "5000")
+				w.Header().Set(headerRateLimitRemaining, "4998")
+
+				if reposStatus != http.StatusOK {
+					w.WriteHeader(reposStatus)
+
+					return
+				}
+
+				if r.URL.Query().Get("page") == "2" {
+					w.Write([]byte(`{"total_count":2,"repositories":[
+						{"id":2,"name":"archived","full_name":"test-1/archived","visibility":"private","archived":true}
+					]}`))
+
+					return
+				}
+
+				w.Header().Set("Link", `<`+ts.URL+`/installation/repositories?per_page=100&page=2>; rel="next"`)
+				w.Write([]byte(`{"total_count":2,"repositories":[
+					{"id":1,"name":"public","full_name":"test-1/public","visibility":"public","archived":false}
+				]}`))
+			case "/installation/token":
+				assert.Equal(t, r.Method, http.MethodDelete)
+				rec.revocations.Add(1)
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}),
+	)
+
+	return ts
+}
+
+func TestBackend_PathInstallationRepositoriesRead(t *testing.T) {
+	t.Parallel()
+
+	t.Run("FieldValidation", func(t *testing.T) {
+		t.Parallel()
+		testFieldValidation(t, logical.ReadOperation, pathPatternInstallations+"/1/repositories")
+	})
+
+	cases := []struct {
+		data           map[string]any
+		name           string
+		installation   string
+		err            string
+		reposStatus    int
+		expRevocations int32
+	}{
+		{
+			name:           "ByOrgName",
+			installation:   testOrgName1,
+			reposStatus:    http.StatusOK,
+			expRevocations: 1,
+		},
+		{
+			name:           "ByID",
+			installation:   strconv.Itoa(testInsID1),
+			reposStatus:    http.StatusOK,
+			expRevocations: 1,
+		},
+		{
+			name:         "NoRevoke",
+			installation: testOrgName1,
+			data:         map[string]any{keyRevoke: false},
+			reposStatus:  http.StatusOK,
+		},
+		{
+			name:         "UnknownOrgName",
+			installation: testOrgName2,
+			err:          errAppNotInstalled.Error(),
+		},
+		{
+			name:         "UnknownID",
+			installation: strconv.Itoa(testInsID2),
+			err:          errUnableToCreateAccessToken.Error(),
+		},
+		{
+			name:           "FailedRepositoriesRequest",
+			installation:   testOrgName1,
+			reposStatus:    http.StatusForbidden,
+			err:            errUnableToGetRepositories.Error(),
+			expRevocations: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b, storage := testBackend(t)
+
+			var rec testRepositoriesRecorder
+
+			ts := testRepositoriesServer(t, &rec, tc.reposStatus)
+			defer ts.Close()
+
+			_, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      pathPatternConfig,
+				Data: map[string]any{
+					keyAppID:   testAppID1,
+					keyPrvKey:  testPrvKeyValid,
+					keyBaseURL: ts.URL,
+				},
+			})
+			assert.NilError(t, err)
+
+			r, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.ReadOperation,
+				Path:      fmt.Sprintf("%s/%s/repositories", pathPatternInstallations, tc.installation),
+				Data:      tc.data,
+			})
+			assert.Equal(t, rec.revocations.Load(), tc.expRevocations)
+
+			// Internal tokens are limited to reading metadata.
+			for _, constraints := range rec.minted() {
+				assert.DeepEqual(t, constraints.Permissions, repositoriesTokenPermissions)
+			}
+
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, r.Data[keyInstallationID], testInsID1)
+			assert.DeepEqual(t, r.Data["repositories"], []any{
+				map[string]any{
+					"id":         1,
+					"name":       "public",
+					"full_name":  "test-1/public",
+					"visibility": "public",
+					"archived":   false,
+				},
+				map[string]any{
+					"id":         2,
+					"name":       "archived",
+					"full_name":  "test-1/archived",
+					"visibility": "private",
+					"archived":   true,
+				},
+			})
+
+			// The rate limit of the installation is recorded.
+			client, done, err := b.Client(ctx, storage, "")
+			assert.NilError(t, err)
+
+			defer done()
+
+			_, installations := client.RateLimits()
+			assert.Equal(t, installations[testInsID1]["core"].Remaining, 4998)
+		})
+	}
+}
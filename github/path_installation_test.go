@@ -0,0 +1,172 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/assert"
+)
+
+// testInstallationServer stubs the GitHub installations API with the single
+// installation (ID 1, on octocat) of installationsJSON.
+func testInstallationServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var installations []json.RawMessage
+	assert.NilError(t, json.Unmarshal([]byte(installationsJSON), &installations))
+
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			t.Helper()
+
+			switch r.URL.Path {
+			case "/app/installations":
+				w.Write([]byte(installationsJSON))
+			case "/app/installations/1":
+				w.Write(installations[0])
+			case "/app/installations/500":
+				w.WriteHeader(http.StatusInternalServerError)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}),
+	)
+}
+
+func TestBackend_PathInstallationRead(t *testing.T) {
+	t.Parallel()
+
+	t.Run("FieldValidation", func(t *testing.T) {
+		t.Parallel()
+		testFieldValidation(t, logical.ReadOperation, pathPatternInstallations+"/1")
+	})
+
+	expData := map[string]any{
+		"id":                   1,
+		"account":              "octocat",
+		"account_type":         "Organization",
+		"target_id":            1,
+		"repository_selection": "selected",
+		"permissions": map[string]string{
+			"checks":   "write",
+			"metadata": "read",
+			"contents": "read",
+		},
+		"events":       []string{"push", "pull_request"},
+		"created_at":   "2017-07-08T20:18:44Z",
+		"suspended_at": "",
+	}
+
+	cases := []struct {
+		expData      map[string]any
+		name         string
+		installation string
+		err          string
+		code         int
+	}{
+		{
+			name:         "ByID",
+			installation: "1",
+			expData:      expData,
+		},
+		{
+			name:         "ByOrgName",
+			installation: "OctoCat",
+			expData:      expData,
+		},
+		{
+			name:         "UnknownID",
+			installation: "2",
+			err:          errInstallationNotFound.Error(),
+			code:         http.StatusNotFound,
+		},
+		{
+			name:         "UnknownOrgName",
+			installation: "octodog",
+			err:          errAppNotInstalled.Error(),
+			code:         http.StatusNotFound,
+		},
+		{
+			name:         "ReservedName",
+			installation: "Refresh",
+			err:          errInstallationNameReserved.Error(),
+			code:         http.StatusBadRequest,
+		},
+		{
+			name:         "FailedInstallationRequest",
+			installation: "500",
+			err:          "500 Internal Server Error",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b, storage := testBackend(t)
+
+			ts := testInstallationServer(t)
+			defer ts.Close()
+
+			_, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      pathPatternConfig,
+				Data: map[string]any{
+					keyAppID:   testAppID1,
+					keyPrvKey:  testPrvKeyValid,
+					keyBaseURL: ts.URL,
+				},
+			})
+			assert.NilError(t, err)
+
+			r, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.ReadOperation,
+				Path:      pathPatternInstallations + "/" + tc.installation,
+			})
+
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+
+				if tc.code != 0 {
+					var coded logical.HTTPCodedError
+					assert.Assert(t, errors.As(err, &coded))
+					assert.Equal(t, coded.Code(), tc.code)
+				}
+
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.DeepEqual(t, r.Data, tc.expData)
+		})
+	}
+
+	t.Run("Suspended", func(t *testing.T) {
+		t.Parallel()
+
+		suspended := `{"suspended_at": "2024-06-01T10:00:00Z"}`
+
+		var inst installation
+		assert.NilError(t, json.Unmarshal([]byte(suspended), &inst))
+		assert.Equal(t, installationData(&inst)["suspended_at"], "2024-06-01T10:00:00Z")
+	})
+
+	t.Run("EnterpriseAccount", func(t *testing.T) {
+		t.Parallel()
+
+		enterprise := `{"account": {"slug": "acme"}, "target_type": "Enterprise"}`
+
+		var inst installation
+		assert.NilError(t, json.Unmarshal([]byte(enterprise), &inst))
+		assert.Equal(t, installationData(&inst)["account"], "acme")
+		assert.Equal(t, installationData(&inst)["account_type"], "Enterprise")
+	})
+}
@@ -29,8 +29,13 @@ GitHub Apps installed on many organizations.
 
 func (b *backend) pathInstallations() *framework.Path {
 	return &framework.Path{
-		Pattern:        pathPatternInstallations,
-		Fields:         map[string]*framework.FieldSchema{},
+		Pattern: pathPatternInstallations,
+		Fields: map[string]*framework.FieldSchema{
+			keyApp: {
+				Type:        framework.TypeString,
+				Description: descApp,
+			},
+		},
 		ExistenceCheck: b.pathInstallationsExistenceCheck,
 		Operations: map[logical.Operation]framework.OperationHandler{
 			// As per the issue request in https://git.io/JUhRk, allow Vault
@@ -48,9 +53,9 @@ func (b *backend) pathInstallations() *framework.Path {
 func (b *backend) pathInstallationsWrite(
 	ctx context.Context,
 	req *logical.Request,
-	_ *framework.FieldData,
+	d *framework.FieldData,
 ) (res *logical.Response, err error) {
-	client, done, err := b.Client(ctx, req.Storage)
+	client, done, err := b.Client(ctx, req.Storage, d.Get(keyApp).(string))
 	if err != nil {
 		return nil, err
 	}
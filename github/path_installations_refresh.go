@@ -0,0 +1,87 @@
+package github
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// installationsRefresh is the name under /installations of the installations
+// refresh endpoint. It is reserved, i.e. cannot name an installation.
+const installationsRefresh = "refresh"
+
+// pathPatternInstallationsRefresh is the string used to define the base path
+// of the installations refresh endpoint.
+const pathPatternInstallationsRefresh = pathPatternInstallations + "/" + installationsRefresh
+
+const (
+	pathInstallationsRefreshHelpSyn = `
+Reload the cached GitHub App installations used to look up installation IDs.
+`
+	pathInstallationsRefreshHelpDesc = `
+Token requests that name an organization rather than an installation ID look
+the installation ID up in a cache of the App's installations, reloaded from
+GitHub once older than 'installation_cache_ttl' (or 'installation_cache_negative_ttl'
+for organizations the App is not installed on).
+
+This endpoint forces a reload of the cache, e.g. after installing the App on a
+new organization, and returns the mapping of lower-cased organization names to
+installation IDs.
+`
+)
+
+func (b *backend) pathInstallationsRefresh() *framework.Path {
+	return &framework.Path{
+		Pattern: pathPatternInstallationsRefresh,
+		Fields: map[string]*framework.FieldSchema{
+			keyApp: {
+				Type:        framework.TypeString,
+				Description: descApp,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: withFieldValidator(b.pathInstallationsRefreshWrite),
+			},
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: withFieldValidator(b.pathInstallationsRefreshWrite),
+			},
+		},
+		HelpSynopsis:    pathInstallationsRefreshHelpSyn,
+		HelpDescription: pathInstallationsRefreshHelpDesc,
+	}
+}
+
+// pathInstallationsRefreshWrite corresponds to READ and UPDATE on
+// /github/installations/refresh.
+func (b *backend) pathInstallationsRefreshWrite(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	client, done, err := b.Client(ctx, req.Storage, d.Get(keyApp).(string))
+	if err != nil {
+		return nil, err
+	}
+
+	defer done()
+
+	snapshot, err := client.RefreshInstallations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	installations := make(map[string]any, len(snapshot.Installations))
+	for org, id := range snapshot.Installations {
+		installations[org] = id
+	}
+
+	return &logical.Response{
+		Data: map[string]any{
+			"installations": installations,
+			"fetched_at":    snapshot.FetchedAt.UTC().Format(time.RFC3339),
+		},
+	}, nil
+}
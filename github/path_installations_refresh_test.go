@@ -0,0 +1,97 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/assert"
+)
+
+func TestBackend_PathInstallationsRefresh(t *testing.T) {
+	t.Parallel()
+
+	t.Run("FieldValidation", func(t *testing.T) {
+		t.Parallel()
+		testFieldValidation(t, logical.UpdateOperation, pathPatternInstallationsRefresh)
+	})
+
+	for _, op := range []logical.Operation{logical.UpdateOperation, logical.ReadOperation} {
+		t.Run(string(op), func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b, storage := testBackend(t)
+
+			var calls atomic.Int32
+
+			ts := testInstallationsServer(t, &calls, nil)
+			defer ts.Close()
+
+			_, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      pathPatternConfig,
+				Data: map[string]any{
+					keyAppID:   testAppID1,
+					keyPrvKey:  testPrvKeyValid,
+					keyBaseURL: ts.URL,
+				},
+			})
+			assert.NilError(t, err)
+
+			client, done, err := b.Client(ctx, storage, "")
+			assert.NilError(t, err)
+			_, err = client.installationID(ctx, testOrgName1)
+			done()
+			assert.NilError(t, err)
+			assert.Equal(t, calls.Load(), int32(1))
+
+			// Refreshing reloads despite a fresh cache.
+			r, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: op,
+				Path:      pathPatternInstallationsRefresh,
+			})
+			assert.NilError(t, err)
+			assert.Equal(t, calls.Load(), int32(2))
+			assert.DeepEqual(t, r.Data["installations"], map[string]any{
+				strings.ToLower(testOrgName1): testInsID1,
+			})
+			assert.Assert(t, r.Data["fetched_at"] != "")
+		})
+	}
+
+	t.Run("Failure", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b, storage := testBackend(t)
+
+		ts := httptest.NewServer(http.NotFoundHandler())
+		defer ts.Close()
+
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfig,
+			Data: map[string]any{
+				keyAppID:   testAppID1,
+				keyPrvKey:  testPrvKeyValid,
+				keyBaseURL: ts.URL,
+			},
+		})
+		assert.NilError(t, err)
+
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternInstallationsRefresh,
+		})
+		assert.ErrorContains(t, err, errUnableToGetInstallations.Error())
+	})
+}
@@ -0,0 +1,426 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// pathPatternJIT is the string used to define the base path of the
+// just-in-time team membership endpoints.
+const pathPatternJIT = "jit"
+
+// pathPatternJITRoles is the string used to define the base path of the
+// just-in-time role endpoints as well as the storage path of the roles.
+const pathPatternJITRoles = pathPatternJIT + "/roles"
+
+// teamMembershipSecretType is the type of the Vault leases of just-in-time
+// team memberships.
+const teamMembershipSecretType = "github_team_membership"
+
+const (
+	keyTeam      = "team"
+	descTeam     = "Required. The slug of the team that users are added to."
+	keyTeamRole  = "team_role"
+	descTeamRole = "The role of users in the team, member or maintainer."
+)
+
+const (
+	errJITRoleNameEmpty   = Error("just-in-time role name empty")
+	errUnableToGetJITRole = Error("unable to get just-in-time role")
+	errAlreadyTeamMember  = Error("user is already a member of the team")
+	errNotOrgMember       = Error("user is not a member of the organization")
+)
+
+const (
+	pathJITRoleHelpSyn  = `Read/write roles for just-in-time GitHub team memberships.`
+	pathJITRoleHelpDesc = `
+This path allows you to create roles that grant temporary team memberships at
+'jit/<role>'. A role pins the team that users are added to and their role in
+it, and how the requesting Vault entity maps to a GitHub user. The following is
+a sample payload:
+
+{
+	"org_name": "acme",
+	"team": "production-admins",
+	"team_role": "member",
+	"username_metadata_key": "github_username",
+	"ttl": 3600
+}
+`
+	pathListJITRolesHelpSyn  = `List existing just-in-time roles.`
+	pathListJITRolesHelpDesc = `List created just-in-time roles.`
+	pathJITHelpSyn           = `Grant a just-in-time team membership from a role.`
+	pathJITHelpDesc          = `
+This path adds the GitHub user of the requesting Vault entity to the team of
+the role and returns a Vault lease. The membership is removed when the lease is
+revoked or expires. A durable record of the membership is kept so that it is
+removed by a periodic sweep should the revocation of its lease fail.
+
+The GitHub user name is taken from the alias of the entity on the auth mount
+with the accessor 'username_alias_mount_accessor' if configured, otherwise from
+the 'username_metadata_key' metadata of the entity or its aliases. Users that
+are already members of the team are refused, so that their membership is not
+removed when the lease ends, and so are users that are not members of the
+organization, who would otherwise be invited to it beyond the lease.
+
+The App needs the 'members' write organization permission.
+`
+)
+
+// jitRole models the stored role that just-in-time team memberships are
+// granted from.
+type jitRole struct {
+	Name     string
+	OrgName  string
+	Team     string
+	TeamRole string
+	App      string `json:",omitempty"`
+	usernameMapping
+	InstallationID int `json:",omitempty"`
+	roleTTL
+}
+
+func (r *jitRole) save(ctx context.Context, s logical.Storage) error {
+	if r.Name == "" {
+		return errJITRoleNameEmpty
+	}
+
+	return putRole(ctx, s, pathPatternJITRoles, r.Name, r)
+}
+
+func getJITRole(ctx context.Context, name string, s logical.Storage) (*jitRole, error) {
+	return getRole[jitRole](ctx, s, pathPatternJITRoles, name)
+}
+
+// pathJIT defines the /github/jit paths on the backend.
+func (b *backend) pathJIT() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: fmt.Sprintf("%s/%s", pathPatternJITRoles, framework.GenericNameRegex("name")),
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Required. Name of the just-in-time role.",
+				},
+				keyOrgName: {
+					Type:        framework.TypeString,
+					Description: "Required. The organization of the team.",
+				},
+				keyTeam: {
+					Type:        framework.TypeString,
+					Description: descTeam,
+				},
+				keyTeamRole: {
+					Type:          framework.TypeString,
+					Description:   descTeamRole,
+					Default:       teamRoleMember,
+					AllowedValues: []any{teamRoleMember, teamRoleMaintainer},
+				},
+				keyInstallationID: {
+					Type:        framework.TypeInt,
+					Description: "The ID of the App installation on the organization, saving a lookup.",
+				},
+				keyUsernameMetadataKey: {
+					Type:        framework.TypeString,
+					Description: descUsernameMetadataKey,
+					Default:     defaultUsernameMetadataKey,
+				},
+				keyUsernameAliasAccessor: {
+					Type:        framework.TypeString,
+					Description: descUsernameAliasAccess,
+				},
+				keyApp: {
+					Type:        framework.TypeString,
+					Description: descApp,
+				},
+				keyTTL: {
+					Type:        framework.TypeDurationSecond,
+					Description: "The TTL of the leases of team memberships. The membership is removed when its lease expires.",
+				},
+				keyMaxTTL: {
+					Type:        framework.TypeDurationSecond,
+					Description: "The maximum TTL of the leases of team memberships.",
+				},
+			},
+			ExistenceCheck: pathRoleExistenceCheck[jitRole](pathPatternJITRoles),
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathJITRoleRead),
+				},
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathJITRoleWrite),
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathJITRoleWrite),
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: withFieldValidator(pathRoleDelete(pathPatternJITRoles, &b.jitLock)),
+				},
+			},
+			HelpSynopsis:    pathJITRoleHelpSyn,
+			HelpDescription: pathJITRoleHelpDesc,
+		},
+		{
+			Pattern: fmt.Sprintf("%s/?", pathPatternJITRoles),
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: pathRoleList(pathPatternJITRoles),
+				},
+			},
+			HelpSynopsis:    pathListJITRolesHelpSyn,
+			HelpDescription: pathListJITRolesHelpDesc,
+		},
+		{
+			Pattern: fmt.Sprintf("%s/%s", pathPatternJIT, framework.GenericNameRegex("role")),
+			Fields: map[string]*framework.FieldSchema{
+				"role": {
+					Type:        framework.TypeString,
+					Description: "Required. Name of the just-in-time role.",
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathJITWrite),
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathJITWrite),
+				},
+			},
+			HelpSynopsis:    pathJITHelpSyn,
+			HelpDescription: pathJITHelpDesc,
+		},
+	}
+}
+
+// teamMembershipSecret defines the secret type of just-in-time team
+// memberships, which are removed when revoked.
+func (b *backend) teamMembershipSecret() *framework.Secret {
+	return &framework.Secret{
+		Type: teamMembershipSecretType,
+		Fields: map[string]*framework.FieldSchema{
+			keyUsername: {
+				Type:        framework.TypeString,
+				Description: "The GitHub user name added to the team.",
+			},
+		},
+		Revoke: b.revokeTeamMembership,
+	}
+}
+
+// pathJITRoleRead corresponds to READ on /github/jit/roles/:name.
+func (b *backend) pathJITRoleRead(
+	ctx context.Context, req *logical.Request, d *framework.FieldData,
+) (*logical.Response, error) {
+	role, err := getJITRole(ctx, d.Get("name").(string), req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]any{
+			keyOrgName:               role.OrgName,
+			keyTeam:                  role.Team,
+			keyTeamRole:              role.TeamRole,
+			keyInstallationID:        role.InstallationID,
+			keyUsernameMetadataKey:   role.usernameMetadataKey(),
+			keyUsernameAliasAccessor: role.UsernameAliasAccessor,
+			keyApp:                   role.App,
+			keyTTL:                   int64(role.TTL.Seconds()),
+			keyMaxTTL:                int64(role.MaxTTL.Seconds()),
+		},
+	}, nil
+}
+
+// pathJITRoleWrite corresponds to CREATE and UPDATE on /github/jit/roles/:name.
+func (b *backend) pathJITRoleWrite(
+	ctx context.Context, req *logical.Request, d *framework.FieldData,
+) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	b.jitLock.Lock()
+	defer b.jitLock.Unlock()
+
+	role, err := getJITRole(ctx, name, req.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", errUnableToGetJITRole, name, err)
+	}
+
+	if role == nil {
+		role = &jitRole{Name: name, TeamRole: d.Get(keyTeamRole).(string)}
+	}
+
+	if orgName, ok := d.GetOk(keyOrgName); ok {
+		role.OrgName = orgName.(string)
+	}
+
+	if team, ok := d.GetOk(keyTeam); ok {
+		role.Team = team.(string)
+	}
+
+	if teamRole, ok := d.GetOk(keyTeamRole); ok {
+		role.TeamRole = teamRole.(string)
+	}
+
+	if installationID, ok := d.GetOk(keyInstallationID); ok {
+		role.InstallationID = installationID.(int)
+	}
+
+	if key, ok := d.GetOk(keyUsernameMetadataKey); ok {
+		role.UsernameMetadataKey = key.(string)
+	}
+
+	if accessor, ok := d.GetOk(keyUsernameAliasAccessor); ok {
+		role.UsernameAliasAccessor = accessor.(string)
+	}
+
+	if app, ok := d.GetOk(keyApp); ok {
+		role.App = app.(string)
+	}
+
+	if err = role.roleTTL.update(d); err != nil {
+		return nil, logical.CodedError(http.StatusBadRequest, err.Error())
+	}
+
+	if role.OrgName == "" || role.Team == "" {
+		return logical.ErrorResponse("%s and %s are required parameters", keyOrgName, keyTeam), nil
+	}
+
+	if role.TeamRole != teamRoleMember && role.TeamRole != teamRoleMaintainer {
+		return nil, logical.CodedError(http.StatusBadRequest, fmt.Sprintf(
+			"%s must be %s or %s", keyTeamRole, teamRoleMember, teamRoleMaintainer))
+	}
+
+	return nil, role.save(ctx, req.Storage)
+}
+
+// pathJITWrite corresponds to READ and UPDATE on /github/jit/:role.
+func (b *backend) pathJITWrite(
+	ctx context.Context, req *logical.Request, d *framework.FieldData,
+) (*logical.Response, error) {
+	name := d.Get("role").(string)
+
+	role, err := getJITRole(ctx, name, req.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", errUnableToGetJITRole, name, err)
+	}
+
+	if role == nil {
+		return logical.ErrorResponse("just-in-time role '%s' does not exist", name), nil
+	}
+
+	username, err := b.requestUsername(req, &role.usernameMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	client, done, err := b.Client(ctx, req.Storage, role.App)
+	if err != nil {
+		return nil, err
+	}
+
+	defer done()
+
+	tokReq := &tokenRequest{OrgName: role.OrgName, InstallationID: role.InstallationID, App: role.App}
+	if err = client.CheckInstallationAllowed(ctx, tokReq); err != nil {
+		return nil, err
+	}
+
+	if tokReq.InstallationID == 0 {
+		if tokReq.InstallationID, err = client.installationID(ctx, tokReq.OrgName); err != nil {
+			return nil, err
+		}
+	}
+
+	// Refuse users outside the organization, who would be invited to it and
+	// stay members once the lease ends.
+	member, err := client.OrgMember(ctx, tokReq.InstallationID, role.OrgName, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if !member {
+		return nil, logical.CodedError(http.StatusBadRequest,
+			fmt.Sprintf("%s: %s is not a member of %s", errNotOrgMember, username, role.OrgName))
+	}
+
+	// Refuse existing members, whose membership would otherwise be removed
+	// when the lease ends.
+	existing, err := client.TeamMembership(ctx, tokReq.InstallationID, role.OrgName, role.Team, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		return nil, logical.CodedError(http.StatusConflict,
+			fmt.Sprintf("%s: %s is a %s of %s", errAlreadyTeamMember, username, existing.Role, role.Team))
+	}
+
+	id, err := newGrantID()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl, maxTTL := b.leaseTTLs(role.TTL, role.MaxTTL)
+
+	// Record the grant before the membership is added, so that it cannot be
+	// missed by the sweep.
+	g := &grant{
+		ExpiresAt:      time.Now().Add(ttl),
+		ID:             id,
+		Role:           name,
+		App:            role.App,
+		OrgName:        role.OrgName,
+		Team:           role.Team,
+		Username:       username,
+		InstallationID: tokReq.InstallationID,
+	}
+	if err = g.save(ctx, req.Storage, jitGrants); err != nil {
+		return nil, err
+	}
+
+	membership, err := client.AddTeamMembership(ctx, tokReq.InstallationID, role.OrgName, role.Team, username, role.TeamRole)
+	if err != nil {
+		if delErr := req.Storage.Delete(ctx, jitGrantsStoragePrefix+id); delErr != nil {
+			b.Logger().Warn("unable to delete just-in-time grant record", keyGrantID, id, "err", delErr)
+		}
+
+		return nil, err
+	}
+
+	b.Logger().Info("added just-in-time team membership",
+		keyOrgName, role.OrgName,
+		keyTeam, role.Team,
+		keyUsername, username,
+		"entity_id", req.EntityID,
+	)
+
+	res := b.Secret(teamMembershipSecretType).Response(map[string]any{
+		keyOrgName:        role.OrgName,
+		keyTeam:           role.Team,
+		keyTeamRole:       membership.Role,
+		keyUsername:       username,
+		"state":           membership.State,
+		keyInstallationID: tokReq.InstallationID,
+	}, g.internalData())
+	res.Secret.TTL = ttl
+	res.Secret.MaxTTL = maxTTL
+
+	return res, nil
+}
+
+// revokeTeamMembership handles Vault lease revocations of just-in-time team
+// memberships by removing them.
+func (b *backend) revokeTeamMembership(
+	ctx context.Context, req *logical.Request, _ *framework.FieldData,
+) (*logical.Response, error) {
+	return nil, b.removeGrant(ctx, req.Storage, jitGrants, grantFromInternalData(req.Secret.InternalData))
+}
@@ -0,0 +1,286 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// testTeam is the admins team of the test-1 organization on a fake GitHub API
+// server, with its members by user name and their role. Other organization
+// members are held by user name with the state of their membership.
+type testTeam struct {
+	ts         *testGitHubServer
+	members    map[string]string
+	orgMembers map[string]string
+}
+
+// newTestTeam serves the memberships of the team and its organization on the
+// server.
+func newTestTeam(t *testing.T, ts *testGitHubServer, tm *testTeam) *testTeam {
+	t.Helper()
+
+	tm.ts = ts
+	path := fmt.Sprintf("/orgs/%s/teams/admins/memberships/{username}", testOrgName1)
+
+	ts.handle(fmt.Sprintf("GET /orgs/%s/memberships/{username}", testOrgName1),
+		func(w http.ResponseWriter, r *http.Request) {
+			state, ok := tm.orgMembers[r.PathValue("username")]
+			if _, member := tm.members[r.PathValue("username")]; member {
+				state, ok = orgMembershipStateActive, true
+			}
+
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			fmt.Fprintf(w, `{"role":"member","state":%q}`, state)
+		})
+
+	ts.handle("GET "+path, func(w http.ResponseWriter, r *http.Request) {
+		role, ok := tm.members[r.PathValue("username")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		fmt.Fprintf(w, `{"role":%q,"state":"active"}`, role)
+	})
+	ts.handle("PUT "+path, func(w http.ResponseWriter, r *http.Request) {
+		var membership teamMembership
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&membership))
+		tm.members[r.PathValue("username")] = membership.Role
+
+		fmt.Fprintf(w, `{"role":%q,"state":"active"}`, membership.Role)
+	})
+	ts.handle("DELETE "+path, func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := tm.members[r.PathValue("username")]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		delete(tm.members, r.PathValue("username"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return tm
+}
+
+// role returns the role of the user in the team, if a member.
+func (tm *testTeam) role(username string) string {
+	tm.ts.mu.Lock()
+	defer tm.ts.mu.Unlock()
+
+	return tm.members[username]
+}
+
+func TestBackend_PathJITRole(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := testBackend(t)
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternJITRoles + "/admins",
+		Data:      map[string]any{keyOrgName: testOrgName1},
+	})
+	assert.NilError(t, err)
+	assert.ErrorContains(t, r.Error(), "org_name and team are required parameters")
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternJITRoles + "/admins",
+		Data:      map[string]any{keyOrgName: testOrgName1, keyTeam: "admins", keyTeamRole: "owner"},
+	})
+	assert.ErrorContains(t, err, "team_role must be member or maintainer")
+
+	var coded logical.HTTPCodedError
+	assert.Assert(t, errors.As(err, &coded))
+	assert.Equal(t, coded.Code(), http.StatusBadRequest)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternJITRoles + "/admins",
+		Data:      map[string]any{keyOrgName: testOrgName1, keyTeam: "admins", keyTTL: "1h"},
+	})
+	assert.NilError(t, err)
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternJITRoles + "/admins",
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Data, map[string]any{
+		keyOrgName:               testOrgName1,
+		keyTeam:                  "admins",
+		keyTeamRole:              teamRoleMember,
+		keyInstallationID:        0,
+		keyUsernameMetadataKey:   defaultUsernameMetadataKey,
+		keyUsernameAliasAccessor: "",
+		keyApp:                   "",
+		keyTTL:                   int64(3600),
+		keyMaxTTL:                int64(0),
+	})
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ListOperation,
+		Path:      pathPatternJITRoles,
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Data["keys"], []string{"admins"})
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.DeleteOperation,
+		Path:      pathPatternJITRoles + "/admins",
+	})
+	assert.NilError(t, err)
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternJITRoles + "/admins",
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, is.Nil(r))
+}
+
+func TestBackend_PathJIT(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := newTestGitHubServer(t)
+	b, storage := testGitHubBackend(t, ts)
+
+	system := b.System().(*logical.StaticSystemView)
+	system.EntityVal = &logical.Entity{
+		ID:       "entity-1",
+		Metadata: map[string]string{defaultUsernameMetadataKey: "octocat"},
+	}
+
+	team := newTestTeam(t, ts, &testTeam{
+		members:    map[string]string{"hubot": teamRoleMember},
+		orgMembers: map[string]string{"octocat": orgMembershipStateActive, "newbie": "pending"},
+	})
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternJITRoles + "/admins",
+		Data: map[string]any{
+			keyOrgName:  testOrgName1,
+			keyTeam:     "admins",
+			keyTeamRole: teamRoleMaintainer,
+			keyTTL:      "30m",
+		},
+	})
+	assert.NilError(t, err)
+
+	// Requests without an entity cannot be mapped to a GitHub user.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternJIT + "/admins",
+	})
+	assert.ErrorContains(t, err, errUnableToMapUsername.Error())
+
+	// Users outside the organization, or yet to join it, are refused.
+	for _, username := range []string{"outsider", "newbie"} {
+		system.EntityVal.Metadata[defaultUsernameMetadataKey] = username
+
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternJIT + "/admins",
+			EntityID:  "entity-1",
+		})
+		assert.ErrorContains(t, err, errNotOrgMember.Error())
+
+		var coded logical.HTTPCodedError
+		assert.Assert(t, errors.As(err, &coded))
+		assert.Equal(t, coded.Code(), http.StatusBadRequest)
+		assert.Equal(t, team.role(username), "")
+	}
+
+	// User names that could alter API paths are refused.
+	system.EntityVal.Metadata[defaultUsernameMetadataKey] = "../../memberships/octocat"
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternJIT + "/admins",
+		EntityID:  "entity-1",
+	})
+	assert.ErrorContains(t, err, errInvalidUsername.Error())
+
+	var coded logical.HTTPCodedError
+	assert.Assert(t, errors.As(err, &coded))
+	assert.Equal(t, coded.Code(), http.StatusBadRequest)
+
+	system.EntityVal.Metadata[defaultUsernameMetadataKey] = "octocat"
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternJIT + "/admins",
+		EntityID:  "entity-1",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, r.Data[keyUsername], "octocat")
+	assert.Equal(t, r.Data[keyTeamRole], teamRoleMaintainer)
+	assert.Equal(t, r.Secret.InternalData["secret_type"], teamMembershipSecretType)
+	assert.Equal(t, r.Secret.TTL, 30*time.Minute)
+	assert.Equal(t, team.role("octocat"), teamRoleMaintainer)
+
+	// A durable record of the grant is kept.
+	grants, err := storage.List(ctx, jitGrantsStoragePrefix)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, grants, []string{r.Secret.InternalData[keyGrantID].(string)})
+
+	// Existing members are refused.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternJIT + "/admins",
+		EntityID:  "entity-1",
+	})
+	assert.ErrorContains(t, err, errAlreadyTeamMember.Error())
+	assert.Assert(t, errors.As(err, &coded))
+	assert.Equal(t, coded.Code(), http.StatusConflict)
+
+	// Revoking the lease removes the membership and its record.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.RevokeOperation,
+		Secret:    r.Secret,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, team.role("octocat"), "")
+	assert.Equal(t, team.role("hubot"), teamRoleMember)
+
+	grants, err = storage.List(ctx, jitGrantsStoragePrefix)
+	assert.NilError(t, err)
+	assert.Equal(t, len(grants), 0)
+
+	for _, constraints := range ts.tokenRequests() {
+		assert.DeepEqual(t, constraints.Permissions, teamsTokenPermissions)
+	}
+}
@@ -32,8 +32,21 @@ Display GitHub secrets plugin metrics in a Prometheus exposition format.
 
 In addition to standard Go metrics, the following custom metrics are exposed:
 - %s_request_duration_seconds: a summary of token request latency and status
+- %s_retries_total: a counter of retried GitHub requests by reason
+- %s_ratelimit_{limit,remaining,used,reset_timestamp_seconds}: gauges of the
+  latest GitHub rate limit state of the App and its installations
+- %s_installation_cache_lookups_total: a counter of installation ID lookups
+  by organization name, by cache result
+- %s_policy_violations_total: a counter of permissions refused by policy
+- %s_token_cache_requests_total: a counter of cacheable token requests by
+  cache result
+- %s_runner_request_duration_seconds: a summary of runner token request
+  latency and status
+- %s_sync_pushes_total: a counter of Actions secrets pushed by sync entries
+  by trigger and status
 - %s_build_info: a constant with useful build information
-`, prefixMetrics, prefixMetrics)
+`, prefixMetrics, prefixMetrics, prefixMetrics, prefixMetrics, prefixMetrics, prefixMetrics, prefixMetrics,
+	prefixMetrics, prefixMetrics)
 
 // requestDuration records useful metric data about backend token requests.
 var requestDuration = prometheus.NewSummaryVec(prometheus.SummaryOpts{
@@ -56,6 +69,12 @@ var revokeDuration = prometheus.NewSummaryVec(prometheus.SummaryOpts{
 	Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
 }, []string{"success"})
 
+// retries counts GitHub requests retried after transient failures.
+var retries = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: fmt.Sprintf("%s_retries_total", prefixMetrics),
+	Help: "Total GitHub requests retried after transient failures, by reason.",
+}, []string{"reason"})
+
 func init() {
 	// Register standard and custom metric collectors globally.
 	prometheus.MustRegister(
@@ -63,6 +82,16 @@ func init() {
 		collectors.NewBuildInfoCollector(),
 		requestDuration,
 		revokeDuration,
+		retries,
+		rateLimitLimit,
+		rateLimitRemaining,
+		rateLimitUsed,
+		rateLimitReset,
+		installationCacheLookups,
+		policyViolations,
+		tokenCacheRequests,
+		runnerTokenDuration,
+		syncPushes,
 	)
 }
 
@@ -2,7 +2,10 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
@@ -28,6 +31,7 @@ following is a sample payload:
 {
 	"installation_id": 123,
 	"org_name": "acme",
+	"app": "read-only",
 	"repositories": [
 		"test-repo",
 		"demo-repo",
@@ -44,13 +48,23 @@ following is a sample payload:
 		"pull_requests": "read",
 		"contents": "read",
 		...
-	}
+	},
+	"ttl": 600,
+	"max_ttl": 1800
 }`
 	pathListPermissionSetHelpSyn  = `List existing permission sets.`
 	pathListPermissionSetHelpDesc = `List created permission sets.`
 )
 
 const (
+	keyCacheTokens        = "cache_tokens"
+	descCacheTokens       = "Return cached tokens for identical token requests rather than creating new ones while they have enough lifetime left."
+	keyCacheMinRemaining  = "cache_min_remaining"
+	descCacheMinRemaining = "The lifetime a cached token must have left to be returned (defaults to the configured 'token_cache_min_remaining')."
+)
+
+const (
+	errPermissionSetCacheTokensTTL    = Error("cache_tokens cannot be used with ttl or max_ttl")
 	errPermissionSetNameEmpty         = Error("permission set name empty")
 	errPermissionSetTokenRequestEmpty = Error("permission set token request empty")
 	errUnableToGetPermissionSet       = Error("unable to get permission set")
@@ -61,6 +75,11 @@ const (
 type PermissionSet struct {
 	Name         string
 	TokenRequest *tokenRequest
+
+	// CacheTokens returns cached tokens for identical token requests while
+	// they have CacheMinRemaining left (or the configured default if zero).
+	CacheTokens       bool          `json:",omitempty"`
+	CacheMinRemaining time.Duration `json:",omitempty"`
 }
 
 func (ps *PermissionSet) validate() error {
@@ -138,6 +157,30 @@ func (b *backend) pathPermissionSet() *framework.Path {
 				Type:        framework.TypeKVPairs,
 				Description: descPerms,
 			},
+			keyResolveRepos: {
+				Type:        framework.TypeBool,
+				Description: descResolveRepos + " Only the resolved IDs are stored, so renamed repositories keep working.",
+			},
+			keyApp: {
+				Type:        framework.TypeString,
+				Description: descApp,
+			},
+			keyTTL: {
+				Type:        framework.TypeDurationSecond,
+				Description: descTTL + " Can be shortened when requesting a token.",
+			},
+			keyMaxTTL: {
+				Type:        framework.TypeDurationSecond,
+				Description: descMaxTTL + " Caps the TTL requested with a token.",
+			},
+			keyCacheTokens: {
+				Type:        framework.TypeBool,
+				Description: descCacheTokens,
+			},
+			keyCacheMinRemaining: {
+				Type:        framework.TypeDurationSecond,
+				Description: descCacheMinRemaining,
+			},
 		},
 		ExistenceCheck: b.pathPermissionSetExistenceCheck,
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -193,6 +236,14 @@ func (b *backend) pathPermissionSetRead(
 		keyRepos:          ps.TokenRequest.Repositories,
 		keyRepoIDs:        ps.TokenRequest.RepositoryIDs,
 		keyPerms:          ps.TokenRequest.Permissions,
+		keyApp:            ps.TokenRequest.App,
+		keyTTL:            int64(ps.TokenRequest.TTL.Seconds()),
+		keyMaxTTL:         int64(ps.TokenRequest.MaxTTL.Seconds()),
+		keyCacheTokens:    ps.CacheTokens,
+	}
+
+	if ps.CacheMinRemaining > 0 {
+		data[keyCacheMinRemaining] = int64(ps.CacheMinRemaining.Seconds())
 	}
 
 	return &logical.Response{
@@ -241,6 +292,7 @@ func (b *backend) pathPermissionSetCreateUpdate(
 
 	ps.TokenRequest.InstallationID = d.Get(keyInstallationID).(int)
 	ps.TokenRequest.OrgName = d.Get(keyOrgName).(string)
+	ps.TokenRequest.App = d.Get(keyApp).(string)
 
 	if ps.TokenRequest.InstallationID == 0 && ps.TokenRequest.OrgName == "" {
 		return logical.ErrorResponse(
@@ -250,8 +302,48 @@ func (b *backend) pathPermissionSetCreateUpdate(
 		), nil
 	}
 
+	if ttl, ok := d.GetOk(keyTTL); ok {
+		ps.TokenRequest.TTL = time.Duration(ttl.(int)) * time.Second
+	}
+
+	if maxTTL, ok := d.GetOk(keyMaxTTL); ok {
+		ps.TokenRequest.MaxTTL = time.Duration(maxTTL.(int)) * time.Second
+	}
+
+	if err = ps.TokenRequest.validateTTL(); err != nil {
+		return nil, logical.CodedError(http.StatusBadRequest, err.Error())
+	}
+
+	if cacheTokens, ok := d.GetOk(keyCacheTokens); ok {
+		ps.CacheTokens = cacheTokens.(bool)
+	}
+
+	if minRemaining, ok := d.GetOk(keyCacheMinRemaining); ok {
+		ps.CacheMinRemaining = time.Duration(minRemaining.(int)) * time.Second
+	}
+
+	if ps.CacheMinRemaining < 0 {
+		return nil, logical.CodedError(http.StatusBadRequest,
+			fmt.Errorf("%s: %w", keyCacheMinRemaining, errNegativeValue).Error())
+	}
+
+	// Cached tokens outlive their leases, which would otherwise revoke them.
+	if ps.CacheTokens && (ps.TokenRequest.TTL > 0 || ps.TokenRequest.MaxTTL > 0) {
+		return nil, logical.CodedError(http.StatusBadRequest, errPermissionSetCacheTokensTTL.Error())
+	}
+
 	if perms, ok := d.GetOk(keyPerms); ok {
 		ps.TokenRequest.Permissions = perms.(map[string]string)
+
+		config, _, err := b.AppConfig(ctx, req.Storage, ps.TokenRequest.App)
+		if err != nil {
+			return nil, err
+		}
+
+		// Templated permissions are validated once resolved.
+		if err = config.validatePermissions(untemplatedPermissions(ps.TokenRequest.Permissions)); err != nil {
+			return nil, logical.CodedError(http.StatusBadRequest, err.Error())
+		}
 	}
 
 	if repoIDs, ok := d.GetOk(keyRepoIDs); ok {
@@ -262,12 +354,165 @@ func (b *backend) pathPermissionSetCreateUpdate(
 		ps.TokenRequest.Repositories = repos.([]string)
 	}
 
+	if err = ps.TokenRequest.validateTemplates(); err != nil {
+		return nil, logical.CodedError(http.StatusBadRequest, err.Error())
+	}
+
+	// Templated permission sets are checked when tokens are requested from
+	// them, once resolved for the requesting entity. Others are checked before
+	// resolving repositories, which requires a token of the installation.
+	if !ps.TokenRequest.templated() {
+		if err = b.checkPermissionSetInstallation(ctx, req.Storage, ps); err != nil {
+			return nil, err
+		}
+	}
+
+	var resp *logical.Response
+
+	// Store resolved repository IDs rather than names, which can change.
+	if d.Get(keyResolveRepos).(bool) {
+		if ps.TokenRequest.templated() {
+			return nil, logical.CodedError(http.StatusBadRequest, fmt.Sprintf(
+				"%s cannot be used with templated permission sets", keyResolveRepos))
+		}
+
+		if resp, err = b.resolvePermissionSetRepositories(ctx, req.Storage, ps); err != nil || resp.IsError() {
+			return resp, err
+		}
+	}
+
+	if !ps.TokenRequest.templated() {
+		if err = b.enforcePermissionSetPolicy(ctx, req.Storage, ps); err != nil {
+			return nil, err
+		}
+	}
+
 	// Save permissions set
 	if err = ps.save(ctx, req.Storage); err != nil {
 		return logical.ErrorResponse(err.Error()), nil
 	}
 
-	return nil, nil
+	if ps.TokenRequest.templated() {
+		return resp, nil
+	}
+
+	if warning := b.permissionSetGrantWarning(ctx, req.Storage, ps); warning != "" {
+		if resp == nil {
+			resp = &logical.Response{}
+		}
+
+		resp.AddWarning(warning)
+	}
+
+	return resp, nil
+}
+
+// checkPermissionSetInstallation refuses permission sets for installations
+// that tokens cannot be requested for.
+func (b *backend) checkPermissionSetInstallation(ctx context.Context, s logical.Storage, ps *PermissionSet) error {
+	config, _, err := b.AppConfig(ctx, s, ps.TokenRequest.App)
+	if err != nil || !config.hasInstallationRules() {
+		return err
+	}
+
+	client, done, err := b.Client(ctx, s, ps.TokenRequest.App)
+	if err != nil {
+		return err
+	}
+
+	defer done()
+
+	return client.CheckInstallationAllowed(ctx, ps.TokenRequest)
+}
+
+// enforcePermissionSetPolicy refuses permission sets that violate the policy
+// that applies to them.
+func (b *backend) enforcePermissionSetPolicy(ctx context.Context, s logical.Storage, ps *PermissionSet) error {
+	var done func()
+
+	defer func() {
+		if done != nil {
+			done()
+		}
+	}()
+
+	return b.enforcePolicy(ctx, s, func() (client *Client, err error) {
+		client, done, err = b.Client(ctx, s, ps.TokenRequest.App)
+
+		return client, err
+	}, ps.TokenRequest, policySourcePermissionSet)
+}
+
+// permissionSetGrantWarning returns a warning should the permissions of the
+// permission set not be granted to its installation, in which case tokens can
+// never be created from it. Nothing is checked unless the app is configured
+// to check granted permissions.
+func (b *backend) permissionSetGrantWarning(ctx context.Context, s logical.Storage, ps *PermissionSet) string {
+	if len(ps.TokenRequest.Permissions) == 0 {
+		return ""
+	}
+
+	config, _, err := b.AppConfig(ctx, s, ps.TokenRequest.App)
+	if err != nil || !config.CheckGrantedPermissions {
+		return ""
+	}
+
+	client, done, err := b.Client(ctx, s, ps.TokenRequest.App)
+	if err != nil {
+		return fmt.Sprintf("unable to check granted permissions: %s", err)
+	}
+
+	defer done()
+
+	tokReq := *ps.TokenRequest
+	if tokReq.InstallationID == 0 {
+		if tokReq.InstallationID, err = client.installationID(ctx, tokReq.OrgName); err != nil {
+			return fmt.Sprintf("unable to check granted permissions: %s", err)
+		}
+	}
+
+	if err = client.checkGrantedPermissions(ctx, &tokReq); err != nil {
+		if errors.Is(err, errPermissionsNotGranted) {
+			return fmt.Sprintf("tokens cannot be created from this permission set: %s", err)
+		}
+
+		return fmt.Sprintf("unable to check granted permissions: %s", err)
+	}
+
+	return ""
+}
+
+// resolvePermissionSetRepositories resolves the repositories of the permission
+// set to IDs, returning a response that maps their names to the IDs, or an
+// error response listing the unknown repositories.
+func (b *backend) resolvePermissionSetRepositories(
+	ctx context.Context, s logical.Storage, ps *PermissionSet,
+) (*logical.Response, error) {
+	client, done, err := b.Client(ctx, s, ps.TokenRequest.App)
+	if err != nil {
+		return nil, err
+	}
+
+	defer done()
+
+	// Resolve a copy so that an installation ID looked up by organization name
+	// is not stored.
+	tokReq := *ps.TokenRequest
+
+	resolved, err := client.ResolveRepositories(ctx, &tokReq)
+	if err != nil {
+		return unknownRepositoriesResponse(err)
+	}
+
+	ps.TokenRequest.Repositories = tokReq.Repositories
+	ps.TokenRequest.RepositoryIDs = tokReq.RepositoryIDs
+
+	resolvedData := make(map[string]any, len(resolved))
+	for id, name := range resolved {
+		resolvedData[name] = id
+	}
+
+	return &logical.Response{Data: map[string]any{"resolved_repositories": resolvedData}}, nil
 }
 
 func (b *backend) pathPermissionSetListRead(
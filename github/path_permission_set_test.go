@@ -3,6 +3,9 @@ package github
 import (
 	"context"
 	"errors"
+	"net/http"
+	"strconv"
+	"sync/atomic"
 	"testing"
 
 	"github.com/hashicorp/vault/sdk/logical"
@@ -326,3 +329,130 @@ func TestBackend_PathPermissionSetList(t *testing.T) {
 	t.Parallel()
 	testBackendPathPermissionSetList(t, logical.ListOperation)
 }
+
+func TestBackend_PathPermissionSetWriteResolveRepositories(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := testBackend(t)
+
+	var rec testRepositoriesRecorder
+
+	ts := testRepositoriesServer(t, &rec, http.StatusOK)
+	defer ts.Close()
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			keyAppID:   testAppID1,
+			keyPrvKey:  testPrvKeyValid,
+			keyBaseURL: ts.URL,
+		},
+	})
+	assert.NilError(t, err)
+
+	// Unknown repositories are refused.
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      "permissionset/foo",
+		Data: map[string]any{
+			keyOrgName:      testOrgName1,
+			keyRepoIDs:      []int{3},
+			keyResolveRepos: true,
+		},
+	})
+	assert.NilError(t, err)
+	assert.ErrorContains(t, r.Error(), errUnknownRepositories.Error()+": repository_ids 3")
+	assert.DeepEqual(t, r.Data["data"], map[string]any{
+		"unknown_repositories":   []string{},
+		"unknown_repository_ids": []int{3},
+	})
+
+	ps, err := getPermissionSet(ctx, "foo", storage)
+	assert.NilError(t, err)
+	assert.Assert(t, ps == nil)
+
+	// Known repositories are stored by ID only.
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      "permissionset/foo",
+		Data: map[string]any{
+			keyOrgName:      testOrgName1,
+			keyRepos:        []string{"Public"},
+			keyRepoIDs:      []int{2},
+			keyResolveRepos: true,
+		},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Data["resolved_repositories"], map[string]any{"public": 1, "archived": 2})
+
+	ps, err = getPermissionSet(ctx, "foo", storage)
+	assert.NilError(t, err)
+	assert.Equal(t, ps.TokenRequest.InstallationID, 0)
+	assert.Equal(t, ps.TokenRequest.OrgName, testOrgName1)
+	assert.Assert(t, ps.TokenRequest.Repositories == nil)
+	assert.DeepEqual(t, ps.TokenRequest.RepositoryIDs, []int{1, 2})
+}
+
+func TestBackend_PathPermissionSetWriteGrantWarning(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := testBackend(t)
+
+	var calls atomic.Int32
+
+	ts := testGrantedPermissionsServer(t, &calls)
+	defer ts.Close()
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			keyAppID:                   testAppID1,
+			keyPrvKey:                  testPrvKeyValid,
+			keyBaseURL:                 ts.URL,
+			keyCheckGrantedPermissions: true,
+		},
+	})
+	assert.NilError(t, err)
+
+	// Unsatisfiable permission sets are stored with a warning.
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      "permissionset/foo",
+		Data: map[string]any{
+			keyInstallationID: testInsID1,
+			keyPerms:          map[string]string{"issues": "write"},
+		},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Warnings, []string{
+		"tokens cannot be created from this permission set: " +
+			errPermissionsNotGranted.Error() + " " + strconv.Itoa(testInsID1) +
+			": issues (requested write, granted read)",
+	})
+
+	ps, err := getPermissionSet(ctx, "foo", storage)
+	assert.NilError(t, err)
+	assert.Assert(t, ps != nil)
+
+	// Satisfiable ones are not.
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      "permissionset/foo",
+		Data: map[string]any{
+			keyInstallationID: testInsID1,
+			keyPerms:          map[string]string{"issues": "read"},
+		},
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, r == nil)
+}
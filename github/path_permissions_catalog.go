@@ -0,0 +1,76 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// pathPatternPermissionsCatalog is the string used to define the base path of
+// the permission catalog endpoint.
+const pathPatternPermissionsCatalog = "permissions/catalog"
+
+const (
+	pathPermissionsCatalogHelpSyn = `
+Report the catalog of permissions that tokens can be requested with.
+`
+	pathPermissionsCatalogHelpDesc = `
+This endpoint reports the GitHub App permissions that tokens and permission
+sets can be requested with, mapped to their allowed access levels. Requested
+permissions are checked against it so that misspelled names and disallowed
+access levels are refused up front rather than by GitHub.
+
+The catalog is built in and can be overridden per app with the
+'permission_catalog' configuration, e.g. for GHES versions that differ.
+`
+)
+
+func (b *backend) pathPermissionsCatalog() *framework.Path {
+	return &framework.Path{
+		Pattern: pathPatternPermissionsCatalog,
+		Fields: map[string]*framework.FieldSchema{
+			keyApp: {
+				Type:        framework.TypeString,
+				Description: descApp,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: withFieldValidator(b.pathPermissionsCatalogRead),
+			},
+		},
+		HelpSynopsis:    pathPermissionsCatalogHelpSyn,
+		HelpDescription: pathPermissionsCatalogHelpDesc,
+	}
+}
+
+// pathPermissionsCatalogRead corresponds to READ on /github/permissions/catalog.
+func (b *backend) pathPermissionsCatalogRead(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	app := d.Get(keyApp).(string)
+
+	// The built-in catalog applies to the default app until it is configured.
+	c, exists, err := b.AppConfig(ctx, req.Storage, app)
+	if err != nil {
+		return nil, err
+	}
+
+	if app != "" && !exists {
+		return nil, logical.CodedError(http.StatusBadRequest, fmt.Sprintf("%s: %q", errAppNotConfigured, app))
+	}
+
+	catalog := c.permissionCatalog()
+
+	data := make(map[string]any, len(catalog))
+	for name, levels := range catalog {
+		data[name] = levels
+	}
+
+	return &logical.Response{Data: data}, nil
+}
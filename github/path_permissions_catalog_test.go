@@ -0,0 +1,111 @@
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/assert"
+)
+
+func TestBackend_PathPermissionsCatalogRead(t *testing.T) {
+	t.Parallel()
+
+	t.Run("FieldValidation", func(t *testing.T) {
+		t.Parallel()
+		testFieldValidation(t, logical.ReadOperation, pathPatternPermissionsCatalog)
+	})
+
+	t.Run("BuiltIn", func(t *testing.T) {
+		t.Parallel()
+
+		b, storage := testBackend(t)
+
+		r, err := b.HandleRequest(context.Background(), &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      pathPatternPermissionsCatalog,
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, len(r.Data), len(permissionCatalog))
+		assert.DeepEqual(t, r.Data["contents"], readWrite)
+		assert.DeepEqual(t, r.Data["organization_projects"], readWriteAdmin)
+	})
+
+	t.Run("Overridden", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b, storage := testBackend(t)
+
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfigApps + "/ghes",
+			Data: map[string]any{
+				keyAppID:             testAppID1,
+				keyPrvKey:            testPrvKeyValid,
+				keyPermissionCatalog: map[string]string{"codespaces": "", "contents": "read"},
+			},
+		})
+		assert.NilError(t, err)
+
+		r, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      pathPatternPermissionsCatalog,
+			Data:      map[string]any{keyApp: "ghes"},
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, len(r.Data), len(permissionCatalog)-1)
+		assert.DeepEqual(t, r.Data["contents"], []string{"read"})
+
+		// Tokens and permission sets of the app are checked against it.
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternToken,
+			Data: map[string]any{
+				keyApp:            "ghes",
+				keyInstallationID: testInsID1,
+				keyPerms:          map[string]string{"codespaces": "read"},
+			},
+		})
+		assert.ErrorContains(t, err, `invalid permissions: unknown permission "codespaces"`)
+
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.CreateOperation,
+			Path:      "permissionset/foo",
+			Data: map[string]any{
+				keyApp:            "ghes",
+				keyInstallationID: testInsID1,
+				keyPerms:          map[string]string{"contents": "write"},
+			},
+		})
+		assert.ErrorContains(t, err, `invalid permissions: contents does not allow "write" (allowed: read)`)
+
+		// Invalid access levels are refused.
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfigApps + "/ghes",
+			Data:      map[string]any{keyPermissionCatalog: map[string]string{"contents": "owner"}},
+		})
+		assert.ErrorContains(t, err, `unknown access level "owner"`)
+	})
+
+	t.Run("AppNotConfigured", func(t *testing.T) {
+		t.Parallel()
+
+		b, storage := testBackend(t)
+
+		_, err := b.HandleRequest(context.Background(), &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      pathPatternPermissionsCatalog,
+			Data:      map[string]any{keyApp: "missing"},
+		})
+		assert.ErrorContains(t, err, errAppNotConfigured.Error())
+	})
+}
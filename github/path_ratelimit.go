@@ -0,0 +1,109 @@
+package github
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// pathPatternRateLimit is the string used to define the base path of the rate
+// limit endpoint.
+const pathPatternRateLimit = "ratelimit"
+
+const (
+	keyRefresh  = "refresh"
+	descRefresh = "Fetch the rate limit of the App from GitHub rather than only reporting the latest seen."
+)
+
+const (
+	pathRateLimitHelpSyn = `
+Report the GitHub rate limit state of the App and its installations.
+`
+	pathRateLimitHelpDesc = `
+This endpoint reports the latest GitHub rate limit state seen in responses to
+the plugin's requests, keyed by GitHub API resource (e.g. "core"), for the App
+itself ('app') and for each installation it has revoked tokens of
+('installations'). The same values are exposed as metrics.
+
+Set 'refresh' to fetch the rate limit of the App from GitHub first, which does
+not count against the rate limit. Installation tokens are not kept to do the
+same for installations.
+`
+)
+
+func (b *backend) pathRateLimit() *framework.Path {
+	return &framework.Path{
+		Pattern: pathPatternRateLimit,
+		Fields: map[string]*framework.FieldSchema{
+			keyApp: {
+				Type:        framework.TypeString,
+				Description: descApp,
+			},
+			keyRefresh: {
+				Type:        framework.TypeBool,
+				Description: descRefresh,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: withFieldValidator(b.pathRateLimitRead),
+			},
+		},
+		HelpSynopsis:    pathRateLimitHelpSyn,
+		HelpDescription: pathRateLimitHelpDesc,
+	}
+}
+
+// pathRateLimitRead corresponds to READ on /github/ratelimit.
+func (b *backend) pathRateLimitRead(
+	ctx context.Context,
+	req *logical.Request,
+	d *framework.FieldData,
+) (*logical.Response, error) {
+	client, done, err := b.Client(ctx, req.Storage, d.Get(keyApp).(string))
+	if err != nil {
+		return nil, err
+	}
+
+	defer done()
+
+	if d.Get(keyRefresh).(bool) {
+		if err = client.RefreshRateLimits(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	app, installations := client.RateLimits()
+
+	installationsData := make(map[string]any, len(installations))
+	for id, limits := range installations {
+		installationsData[strconv.Itoa(id)] = rateLimitsData(limits)
+	}
+
+	return &logical.Response{
+		Data: map[string]any{
+			"app":           rateLimitsData(app),
+			"installations": installationsData,
+		},
+	}, nil
+}
+
+// rateLimitsData formats rate limits keyed by resource for a response.
+func rateLimitsData(limits map[string]rateLimit) map[string]any {
+	data := make(map[string]any, len(limits))
+
+	for resource, rl := range limits {
+		data[resource] = map[string]any{
+			"limit":      rl.Limit,
+			"remaining":  rl.Remaining,
+			"used":       rl.Used,
+			"reset":      rl.Reset.UTC().Format(time.RFC3339),
+			"updated_at": rl.UpdatedAt.UTC().Format(time.RFC3339),
+		}
+	}
+
+	return data
+}
@@ -0,0 +1,194 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/assert"
+)
+
+// testRateLimitServer stubs GitHub, responding to token creation, revocation
+// and rate limit requests with the given rate limit state.
+func testRateLimitServer(t *testing.T, remaining int, reset time.Time) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			t.Helper()
+
+			w.Header().Set(headerRateLimitLimit, "5000")
+			w.Header().Set(headerRateLimitRemaining, strconv.Itoa(remaining))
+			w.Header().Set(headerRateLimitUsed, strconv.Itoa(5000-remaining))
+			w.Header().Set(headerRateLimitReset, strconv.FormatInt(reset.Unix(), 10))
+			w.Header().Set(headerRateLimitResource, "core")
+
+			switch r.URL.Path {
+			case "/rate_limit":
+				body, _ := json.Marshal(map[string]any{
+					"resources": map[string]any{
+						"core":   map[string]any{"limit": 5000, "remaining": remaining, "used": 5000 - remaining, "reset": reset.Unix()},
+						"search": map[string]any{"limit": 30, "remaining": 30, "used": 0, "reset": reset.Unix()},
+					},
+				})
+				w.Write(body)
+			case "/installation/token":
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusCreated)
+				body, _ := json.Marshal(map[string]any{
+					"token":      testToken,
+					"expires_at": time.Now().Add(time.Hour).Format(time.RFC3339),
+				})
+				w.Write(body)
+			}
+		}),
+	)
+}
+
+func TestClient_RecordRateLimit(t *testing.T) {
+	t.Parallel()
+
+	reset := time.Now().Add(30 * time.Minute).Truncate(time.Second)
+
+	ts := testRateLimitServer(t, 4321, reset)
+	defer ts.Close()
+
+	client, err := NewClient(&Config{
+		AppID:   testAppID2,
+		PrvKey:  testPrvKeyValid,
+		BaseURL: ts.URL,
+	})
+	assert.NilError(t, err)
+
+	_, err = client.Token(context.Background(), &tokenRequest{InstallationID: testInsID2})
+	assert.NilError(t, err)
+
+	_, err = client.revokeToken(context.Background(), testToken, testInsID2)
+	assert.NilError(t, err)
+
+	app, installations := client.RateLimits()
+	assert.Equal(t, app["core"].Limit, 5000)
+	assert.Equal(t, app["core"].Remaining, 4321)
+	assert.Equal(t, app["core"].Used, 679)
+	assert.Assert(t, app["core"].Reset.Equal(reset))
+	assert.Equal(t, installations[testInsID2]["core"].Remaining, 4321)
+
+	appID := strconv.Itoa(testAppID2)
+	assert.Equal(t, testutil.ToFloat64(rateLimitRemaining.WithLabelValues(appID, "", "core")), float64(4321))
+	assert.Equal(t, testutil.ToFloat64(rateLimitRemaining.WithLabelValues(appID, strconv.Itoa(testInsID2), "core")), float64(4321))
+	assert.Equal(t, testutil.ToFloat64(rateLimitReset.WithLabelValues(appID, "", "core")), float64(reset.Unix()))
+}
+
+func TestBackend_PathRateLimitRead(t *testing.T) {
+	t.Parallel()
+
+	t.Run("FieldValidation", func(t *testing.T) {
+		t.Parallel()
+		testFieldValidation(t, logical.ReadOperation, pathPatternRateLimit)
+	})
+
+	t.Run("HappyPath", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b, storage := testBackend(t)
+
+		reset := time.Now().Add(time.Hour)
+
+		ts := testRateLimitServer(t, 100, reset)
+		defer ts.Close()
+
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfig,
+			Data: map[string]any{
+				keyAppID:   testAppID1,
+				keyPrvKey:  testPrvKeyValid,
+				keyBaseURL: ts.URL,
+			},
+		})
+		assert.NilError(t, err)
+
+		// Nothing seen yet.
+		r, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      pathPatternRateLimit,
+		})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, r.Data["app"], map[string]any{})
+
+		// Seen from a token request.
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternToken,
+			Data:      map[string]any{keyInstallationID: testInsID1},
+		})
+		assert.NilError(t, err)
+
+		r, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      pathPatternRateLimit,
+		})
+		assert.NilError(t, err)
+
+		core := r.Data["app"].(map[string]any)["core"].(map[string]any)
+		assert.Equal(t, core["remaining"], 100)
+		assert.Equal(t, core["reset"], reset.UTC().Format(time.RFC3339))
+
+		// Refreshed from GitHub.
+		r, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      pathPatternRateLimit,
+			Data:      map[string]any{keyRefresh: true},
+		})
+		assert.NilError(t, err)
+
+		search := r.Data["app"].(map[string]any)["search"].(map[string]any)
+		assert.Equal(t, search["limit"], 30)
+
+		// Installation tokens are not kept to refresh installations with.
+		assert.DeepEqual(t, r.Data["installations"], map[string]any{})
+	})
+
+	t.Run("RefreshFailure", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b, storage := testBackend(t)
+
+		ts := httptest.NewServer(http.NotFoundHandler())
+		defer ts.Close()
+
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternConfig,
+			Data: map[string]any{
+				keyAppID:   testAppID1,
+				keyPrvKey:  testPrvKeyValid,
+				keyBaseURL: ts.URL,
+			},
+		})
+		assert.NilError(t, err)
+
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      pathPatternRateLimit,
+			Data:      map[string]any{keyRefresh: true},
+		})
+		assert.ErrorContains(t, err, errUnableToGetRateLimit.Error())
+	})
+}
@@ -0,0 +1,446 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// pathPatternRunner is the string used to define the base path of the runner
+// token endpoints.
+const pathPatternRunner = "runner"
+
+// pathPatternRunnerRoles is the string used to define the base path of the
+// runner role endpoints as well as the storage path of the roles.
+const pathPatternRunnerRoles = pathPatternRunner + "/roles"
+
+const (
+	keyRunnerGroups  = "runner_groups"
+	descRunnerGroups = "The runner groups that runners can be registered in, with JIT configurations only. Only applies to organization runners."
+	keyRunnerGroup   = "runner_group"
+	descRunnerGroup  = "The runner group to register the runner in, one of those of the role (defaults to the first)."
+	keyRunnerName    = "runner_name"
+	descRunnerName   = "Required. The name of the runner."
+	keyLabels        = "labels"
+	descLabels       = "Required. The custom labels of the runner."
+	keyWorkFolder    = "work_folder"
+	descWorkFolder   = "The working directory of the runner (defaults to '_work')."
+)
+
+const (
+	errRunnerRoleNameEmpty   = Error("runner role name empty")
+	errUnableToGetRunnerRole = Error("unable to get runner role")
+	errRunnerGroupsPinned    = Error("registration tokens cannot pin runner groups, create a JIT configuration instead")
+)
+
+const (
+	pathRunnerRoleHelpSyn  = `Read/write roles for GitHub Actions self-hosted runner tokens.`
+	pathRunnerRoleHelpDesc = `
+This path allows you to create roles that self-hosted runner registration and
+removal tokens are created from at 'runner/<role>/registration-token' and
+'runner/<role>/removal-token', and just-in-time runner configurations at
+'runner/<role>/jitconfig'. A role pins the organization, and optionally the
+repository, that runners are registered with, and the runner groups of
+organization runners. Since registration tokens cannot pin a runner group,
+roles with runner groups only create JIT configurations. The following is a
+sample payload:
+
+{
+	"org_name": "acme",
+	"runner_groups": ["ephemeral", "gpu"]
+}
+`
+	pathListRunnerRolesHelpSyn  = `List existing runner roles.`
+	pathListRunnerRolesHelpDesc = `List created runner roles.`
+	pathRunnerTokenHelpSyn      = `Create a self-hosted runner registration or removal token from a role.`
+	pathRunnerTokenHelpDesc     = `
+This path creates a GitHub Actions self-hosted runner registration or removal
+token for the organization, or repository, of the role. The tokens expire after
+an hour and cannot be revoked, so they are not Vault leases. Registration
+tokens are refused for roles with runner groups.
+
+The App needs the 'organization_self_hosted_runners' write permission for
+organization runners, or the 'administration' write permission on the
+repository for repository runners.
+`
+	pathRunnerJITConfigHelpSyn  = `Create a just-in-time self-hosted runner configuration from a role.`
+	pathRunnerJITConfigHelpDesc = `
+This path creates a GitHub Actions just-in-time configuration of a self-hosted
+runner of the organization, or repository, of the role. Runners started with it
+('run.sh --jitconfig') are registered in the runner group chosen with
+'runner_group' from those of the role, and removed after a single job.
+
+The App needs the same permissions as for runner tokens.
+`
+)
+
+// runnerRole models the stored role that runner tokens are created from.
+type runnerRole struct {
+	Name           string
+	OrgName        string
+	Repository     string   `json:",omitempty"`
+	App            string   `json:",omitempty"`
+	RunnerGroups   []string `json:",omitempty"`
+	InstallationID int      `json:",omitempty"`
+}
+
+func (r *runnerRole) save(ctx context.Context, s logical.Storage) error {
+	if r.Name == "" {
+		return errRunnerRoleNameEmpty
+	}
+
+	return putRole(ctx, s, pathPatternRunnerRoles, r.Name, r)
+}
+
+func getRunnerRole(ctx context.Context, name string, s logical.Storage) (*runnerRole, error) {
+	return getRole[runnerRole](ctx, s, pathPatternRunnerRoles, name)
+}
+
+// runnerGroup returns the runner group that a runner should be registered in
+// given the requested group, if any.
+func (r *runnerRole) runnerGroup(requested string) (string, error) {
+	if requested == "" {
+		if len(r.RunnerGroups) == 0 {
+			return "", nil
+		}
+
+		return r.RunnerGroups[0], nil
+	}
+
+	if !slices.ContainsFunc(r.RunnerGroups, func(group string) bool {
+		return strings.EqualFold(group, requested)
+	}) {
+		return "", fmt.Errorf("%s %q is not allowed by the role, allowed: %s",
+			keyRunnerGroup, requested, strings.Join(r.RunnerGroups, ", "))
+	}
+
+	return requested, nil
+}
+
+// pathRunner defines the /github/runner paths on the backend.
+func (b *backend) pathRunner() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: fmt.Sprintf("%s/%s", pathPatternRunnerRoles, framework.GenericNameRegex("name")),
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Required. Name of the runner role.",
+				},
+				keyOrgName: {
+					Type:        framework.TypeString,
+					Description: "Required. The organization that runners are registered with.",
+				},
+				keyRepository: {
+					Type:        framework.TypeString,
+					Description: "The name of the repository that runners are registered with, rather than the organization.",
+				},
+				keyInstallationID: {
+					Type:        framework.TypeInt,
+					Description: "The ID of the App installation on the organization, saving a lookup.",
+				},
+				keyRunnerGroups: {
+					Type:        framework.TypeCommaStringSlice,
+					Description: descRunnerGroups,
+				},
+				keyApp: {
+					Type:        framework.TypeString,
+					Description: descApp,
+				},
+			},
+			ExistenceCheck: pathRoleExistenceCheck[runnerRole](pathPatternRunnerRoles),
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathRunnerRoleRead),
+				},
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathRunnerRoleWrite),
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathRunnerRoleWrite),
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: withFieldValidator(pathRoleDelete(pathPatternRunnerRoles, &b.runnerLock)),
+				},
+			},
+			HelpSynopsis:    pathRunnerRoleHelpSyn,
+			HelpDescription: pathRunnerRoleHelpDesc,
+		},
+		{
+			Pattern: fmt.Sprintf("%s/?", pathPatternRunnerRoles),
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: pathRoleList(pathPatternRunnerRoles),
+				},
+			},
+			HelpSynopsis:    pathListRunnerRolesHelpSyn,
+			HelpDescription: pathListRunnerRolesHelpDesc,
+		},
+		{
+			Pattern: fmt.Sprintf("%s/%s/(?P<kind>%s|%s)-token", pathPatternRunner,
+				framework.GenericNameRegex("role"), runnerTokenRegistration, runnerTokenRemoval),
+			Fields: map[string]*framework.FieldSchema{
+				"role": {
+					Type:        framework.TypeString,
+					Description: "Required. Name of the runner role.",
+				},
+				"kind": {
+					Type:        framework.TypeString,
+					Description: "The kind of runner token, registration or removal.",
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathRunnerTokenWrite),
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathRunnerTokenWrite),
+				},
+			},
+			HelpSynopsis:    pathRunnerTokenHelpSyn,
+			HelpDescription: pathRunnerTokenHelpDesc,
+		},
+		{
+			Pattern: fmt.Sprintf("%s/%s/(?P<kind>%s)", pathPatternRunner,
+				framework.GenericNameRegex("role"), runnerJITConfig),
+			Fields: map[string]*framework.FieldSchema{
+				"role": {
+					Type:        framework.TypeString,
+					Description: "Required. Name of the runner role.",
+				},
+				"kind": {
+					Type:        framework.TypeString,
+					Description: "The kind of runner request, jitconfig.",
+				},
+				keyRunnerName: {
+					Type:        framework.TypeString,
+					Description: descRunnerName,
+				},
+				keyLabels: {
+					Type:        framework.TypeCommaStringSlice,
+					Description: descLabels,
+				},
+				keyWorkFolder: {
+					Type:        framework.TypeString,
+					Description: descWorkFolder,
+				},
+				keyRunnerGroup: {
+					Type:        framework.TypeString,
+					Description: descRunnerGroup,
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathRunnerTokenWrite),
+				},
+			},
+			HelpSynopsis:    pathRunnerJITConfigHelpSyn,
+			HelpDescription: pathRunnerJITConfigHelpDesc,
+		},
+	}
+}
+
+// pathRunnerRoleRead corresponds to READ on /github/runner/roles/:name.
+func (b *backend) pathRunnerRoleRead(
+	ctx context.Context, req *logical.Request, d *framework.FieldData,
+) (*logical.Response, error) {
+	role, err := getRunnerRole(ctx, d.Get("name").(string), req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]any{
+			keyOrgName:        role.OrgName,
+			keyRepository:     role.Repository,
+			keyInstallationID: role.InstallationID,
+			keyRunnerGroups:   role.RunnerGroups,
+			keyApp:            role.App,
+		},
+	}, nil
+}
+
+// pathRunnerRoleWrite corresponds to CREATE and UPDATE on
+// /github/runner/roles/:name.
+func (b *backend) pathRunnerRoleWrite(
+	ctx context.Context, req *logical.Request, d *framework.FieldData,
+) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	b.runnerLock.Lock()
+	defer b.runnerLock.Unlock()
+
+	role, err := getRunnerRole(ctx, name, req.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", errUnableToGetRunnerRole, name, err)
+	}
+
+	if role == nil {
+		role = &runnerRole{Name: name}
+	}
+
+	if orgName, ok := d.GetOk(keyOrgName); ok {
+		role.OrgName = orgName.(string)
+	}
+
+	if repo, ok := d.GetOk(keyRepository); ok {
+		role.Repository = repo.(string)
+	}
+
+	if installationID, ok := d.GetOk(keyInstallationID); ok {
+		role.InstallationID = installationID.(int)
+	}
+
+	if groups, ok := d.GetOk(keyRunnerGroups); ok {
+		role.RunnerGroups = groups.([]string)
+	}
+
+	if app, ok := d.GetOk(keyApp); ok {
+		role.App = app.(string)
+	}
+
+	if role.OrgName == "" {
+		return logical.ErrorResponse("%s is a required parameter", keyOrgName), nil
+	}
+
+	if role.Repository != "" && len(role.RunnerGroups) > 0 {
+		return nil, logical.CodedError(http.StatusBadRequest, fmt.Sprintf(
+			"%s only apply to organization runners, not those of a %s", keyRunnerGroups, keyRepository))
+	}
+
+	return nil, role.save(ctx, req.Storage)
+}
+
+// pathRunnerTokenWrite corresponds to READ and UPDATE on
+// /github/runner/:role/registration-token and /github/runner/:role/removal-token,
+// and UPDATE on /github/runner/:role/jitconfig.
+func (b *backend) pathRunnerTokenWrite(
+	ctx context.Context, req *logical.Request, d *framework.FieldData,
+) (res *logical.Response, err error) {
+	name := d.Get("role").(string)
+	kind := d.Get("kind").(string)
+
+	role, err := getRunnerRole(ctx, name, req.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", errUnableToGetRunnerRole, name, err)
+	}
+
+	if role == nil {
+		return logical.ErrorResponse("runner role '%s' does not exist", name), nil
+	}
+
+	var (
+		group  string
+		jitReq *runnerJITConfigRequest
+	)
+
+	switch kind {
+	case runnerTokenRegistration:
+		// Runners registered with a token can join any runner group.
+		if len(role.RunnerGroups) > 0 {
+			return nil, logical.CodedError(http.StatusBadRequest, errRunnerGroupsPinned.Error())
+		}
+	case runnerJITConfig:
+		if group, err = role.runnerGroup(d.Get(keyRunnerGroup).(string)); err != nil {
+			return nil, logical.CodedError(http.StatusBadRequest, err.Error())
+		}
+
+		jitReq = &runnerJITConfigRequest{
+			Name:       d.Get(keyRunnerName).(string),
+			Labels:     d.Get(keyLabels).([]string),
+			WorkFolder: d.Get(keyWorkFolder).(string),
+		}
+
+		if jitReq.Name == "" || len(jitReq.Labels) == 0 {
+			return logical.ErrorResponse("%s and %s are required parameters", keyRunnerName, keyLabels), nil
+		}
+	}
+
+	client, done, err := b.Client(ctx, req.Storage, role.App)
+	if err != nil {
+		return nil, err
+	}
+
+	defer done()
+
+	// Instrument and log the runner token API call, recording status and
+	// duration.
+	defer func(begin time.Time) {
+		duration := time.Since(begin)
+		b.Logger().Debug("attempted to create a runner token",
+			"took", duration.String(),
+			"err", err,
+			"kind", kind,
+			keyOrgName, role.OrgName,
+			keyRepository, role.Repository,
+			keyRunnerGroup, group,
+		)
+		runnerTokenDuration.With(prometheus.Labels{
+			"success":     strconv.FormatBool(err == nil),
+			"kind":        kind,
+			keyOrgName:    role.OrgName,
+			keyRepository: role.Repository,
+		}).Observe(duration.Seconds())
+	}(time.Now())
+
+	tokReq := &tokenRequest{OrgName: role.OrgName, InstallationID: role.InstallationID, App: role.App}
+	if err = client.CheckInstallationAllowed(ctx, tokReq); err != nil {
+		return nil, err
+	}
+
+	if tokReq.InstallationID == 0 {
+		if tokReq.InstallationID, err = client.installationID(ctx, tokReq.OrgName); err != nil {
+			return nil, err
+		}
+	}
+
+	data := map[string]any{
+		keyOrgName:        role.OrgName,
+		keyInstallationID: tokReq.InstallationID,
+	}
+
+	if jitReq != nil {
+		var config *runnerJITConfigResponse
+
+		if config, err = client.RunnerJITConfig(
+			ctx, tokReq.InstallationID, role.OrgName, role.Repository, group, jitReq,
+		); err != nil {
+			return nil, err
+		}
+
+		data["encoded_jit_config"] = config.EncodedJITConfig
+		data["runner_id"] = config.Runner.ID
+		data[keyRunnerName] = config.Runner.Name
+	} else {
+		var token *runnerToken
+
+		if token, err = client.RunnerToken(ctx, tokReq.InstallationID, role.OrgName, role.Repository, kind); err != nil {
+			return nil, err
+		}
+
+		data["token"] = token.Token
+		data["expires_at"] = token.ExpiresAt.Format(time.RFC3339)
+	}
+
+	if role.Repository != "" {
+		data[keyRepository] = role.Repository
+	}
+
+	if group != "" {
+		data[keyRunnerGroup] = group
+	}
+
+	return &logical.Response{Data: data}, nil
+}
@@ -0,0 +1,283 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+func TestBackend_PathRunnerRole(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := testBackend(t)
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternRunnerRoles + "/ci",
+		Data:      map[string]any{keyRunnerGroups: "gpu"},
+	})
+	assert.NilError(t, err)
+	assert.ErrorContains(t, r.Error(), "org_name is a required parameter")
+
+	// Repository runners have no runner groups.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternRunnerRoles + "/ci",
+		Data: map[string]any{
+			keyOrgName:      testOrgName1,
+			keyRepository:   "website",
+			keyRunnerGroups: "gpu",
+		},
+	})
+	assert.ErrorContains(t, err, "runner_groups only apply to organization runners")
+
+	var coded logical.HTTPCodedError
+	assert.Assert(t, errors.As(err, &coded))
+	assert.Equal(t, coded.Code(), http.StatusBadRequest)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternRunnerRoles + "/ci",
+		Data: map[string]any{
+			keyOrgName:      testOrgName1,
+			keyRunnerGroups: "ephemeral,gpu",
+		},
+	})
+	assert.NilError(t, err)
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternRunnerRoles + "/ci",
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Data, map[string]any{
+		keyOrgName:        testOrgName1,
+		keyRepository:     "",
+		keyInstallationID: 0,
+		keyRunnerGroups:   []string{"ephemeral", "gpu"},
+		keyApp:            "",
+	})
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ListOperation,
+		Path:      pathPatternRunnerRoles,
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Data["keys"], []string{"ci"})
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.DeleteOperation,
+		Path:      pathPatternRunnerRoles + "/ci",
+	})
+	assert.NilError(t, err)
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternRunnerRoles + "/ci",
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, is.Nil(r))
+}
+
+func TestBackend_PathRunnerToken(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := newTestGitHubServer(t)
+	b, storage := testGitHubBackend(t, ts)
+
+	var (
+		paths      []string
+		jitConfigs []runnerJITConfigRequest
+	)
+
+	for _, path := range []string{
+		fmt.Sprintf("/orgs/%s/actions/runners/registration-token", testOrgName1),
+		fmt.Sprintf("/orgs/%s/actions/runners/removal-token", testOrgName1),
+		fmt.Sprintf("/repos/%s/website/actions/runners/registration-token", testOrgName1),
+	} {
+		ts.handle("POST "+path, func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"token":"AABF3JGZDX3P5PMEXLND6TS6FCWO6","expires_at":"2026-01-22T12:13:35Z"}`))
+		})
+	}
+
+	ts.handle(fmt.Sprintf("GET /orgs/%s/actions/runner-groups", testOrgName1),
+		func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte(`{"total_count":3,"runner_groups":[` +
+				`{"id":1,"name":"Default"},{"id":2,"name":"ephemeral"},{"id":3,"name":"gpu"}]}`))
+		})
+
+	for _, path := range []string{
+		fmt.Sprintf("/orgs/%s/actions/runners/generate-jitconfig", testOrgName1),
+		fmt.Sprintf("/repos/%s/website/actions/runners/generate-jitconfig", testOrgName1),
+	} {
+		ts.handle("POST "+path, func(w http.ResponseWriter, r *http.Request) {
+			var jitReq runnerJITConfigRequest
+			assert.NilError(t, json.NewDecoder(r.Body).Decode(&jitReq))
+
+			paths = append(paths, r.URL.Path)
+			jitConfigs = append(jitConfigs, jitReq)
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"runner":{"id":23,"name":%q},"encoded_jit_config":"abc"}`, jitReq.Name)
+		})
+	}
+
+	for name, data := range map[string]map[string]any{
+		"org":    {keyOrgName: testOrgName1},
+		"groups": {keyOrgName: testOrgName1, keyRunnerGroups: "ephemeral,gpu"},
+		"repo":   {keyOrgName: testOrgName1, keyInstallationID: testInsID1, keyRepository: "website"},
+	} {
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.CreateOperation,
+			Path:      pathPatternRunnerRoles + "/" + name,
+			Data:      data,
+		})
+		assert.NilError(t, err)
+	}
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternRunner + "/org/registration-token",
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Data, map[string]any{
+		"token":           "AABF3JGZDX3P5PMEXLND6TS6FCWO6",
+		"expires_at":      "2026-01-22T12:13:35Z",
+		keyOrgName:        testOrgName1,
+		keyInstallationID: testInsID1,
+	})
+	assert.Assert(t, r.Secret == nil)
+
+	// Runner token requests are measured.
+	assert.Assert(t, testutil.CollectAndCount(runnerTokenDuration) >= 1)
+
+	// Registration tokens cannot pin the runner groups of a role.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternRunner + "/groups/registration-token",
+	})
+	assert.ErrorContains(t, err, errRunnerGroupsPinned.Error())
+
+	var coded logical.HTTPCodedError
+	assert.Assert(t, errors.As(err, &coded))
+	assert.Equal(t, coded.Code(), http.StatusBadRequest)
+
+	// Organization runners are registered in a runner group of the role.
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternRunner + "/groups/jitconfig",
+		Data: map[string]any{
+			keyRunnerName:  "gpu-1",
+			keyLabels:      "linux,gpu",
+			keyRunnerGroup: "GPU",
+		},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Data, map[string]any{
+		"encoded_jit_config": "abc",
+		"runner_id":          int64(23),
+		keyRunnerName:        "gpu-1",
+		keyOrgName:           testOrgName1,
+		keyInstallationID:    testInsID1,
+		keyRunnerGroup:       "GPU",
+	})
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternRunner + "/groups/jitconfig",
+		Data: map[string]any{
+			keyRunnerName:  "default-1",
+			keyLabels:      "linux",
+			keyRunnerGroup: "default",
+		},
+	})
+	assert.ErrorContains(t, err, `runner_group "default" is not allowed by the role`)
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternRunner + "/groups/jitconfig",
+		Data:      map[string]any{keyRunnerName: "gpu-2"},
+	})
+	assert.NilError(t, err)
+	assert.ErrorContains(t, r.Error(), "runner_name and labels are required parameters")
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternRunner + "/groups/removal-token",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, r.Data["token"], "AABF3JGZDX3P5PMEXLND6TS6FCWO6")
+
+	// Repository runners.
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternRunner + "/repo/registration-token",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, r.Data[keyRepository], "website")
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternRunner + "/repo/jitconfig",
+		Data:      map[string]any{keyRunnerName: "website-1", keyLabels: "linux", keyWorkFolder: "build"},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, r.Data[keyRepository], "website")
+
+	ts.mu.Lock()
+	assert.DeepEqual(t, paths, []string{
+		fmt.Sprintf("/orgs/%s/actions/runners/registration-token", testOrgName1),
+		fmt.Sprintf("/orgs/%s/actions/runners/generate-jitconfig", testOrgName1),
+		fmt.Sprintf("/orgs/%s/actions/runners/removal-token", testOrgName1),
+		fmt.Sprintf("/repos/%s/website/actions/runners/registration-token", testOrgName1),
+		fmt.Sprintf("/repos/%s/website/actions/runners/generate-jitconfig", testOrgName1),
+	})
+	assert.DeepEqual(t, jitConfigs, []runnerJITConfigRequest{
+		{Name: "gpu-1", Labels: []string{"linux", "gpu"}, RunnerGroupID: 3},
+		{Name: "website-1", Labels: []string{"linux"}, WorkFolder: "build", RunnerGroupID: defaultRunnerGroupID},
+	})
+	ts.mu.Unlock()
+
+	// JIT configurations are created with a single internal token.
+	orgRunners := tokenConstraints{Permissions: map[string]string{"organization_self_hosted_runners": "write"}}
+	repoRunners := tokenConstraints{Repositories: []string{"website"}, Permissions: map[string]string{"administration": "write"}}
+	assert.DeepEqual(t, ts.tokenRequests(), []tokenConstraints{orgRunners, orgRunners, orgRunners, repoRunners, repoRunners})
+
+	// Unknown roles are refused.
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternRunner + "/unknown/removal-token",
+	})
+	assert.NilError(t, err)
+	assert.ErrorContains(t, r.Error(), "runner role 'unknown' does not exist")
+}
@@ -0,0 +1,431 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// pathPatternSync is the string used to define the base path of the Actions
+// secret sync endpoints as well as the storage path of the sync entries.
+const pathPatternSync = "sync"
+
+const (
+	keyEnvironment           = "environment"
+	keySecretName            = "secret_name"
+	descSecretName           = "The name of the Actions secret (defaults to the name of the sync entry)."
+	keyValue                 = "value"
+	keyValueFromEntry        = "value_from_entry"
+	descValueFromEntry       = "The name of another sync entry whose value is synced, rather than a value of this entry."
+	keyVisibility            = "visibility"
+	descVisibility           = "The visibility of organization secrets, all, private or selected."
+	keySelectedRepositoryIDs = "selected_repository_ids"
+)
+
+// secretNameRegex matches valid Actions secret names.
+var secretNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+const (
+	pathSyncHelpSyn  = `Read/write Vault managed values synced to GitHub Actions secrets.`
+	pathSyncHelpDesc = `
+This path allows you to make Vault the source of truth of GitHub Actions
+secrets. A sync entry holds a value, or refers to the value of another sync
+entry, and the organization, repository or repository environment secret that
+it is synced to. The value is encrypted with the public key of the target
+(a libsodium sealed box) and pushed as the App installation when written.
+
+The secrets are periodically reconciled: they are pushed again should their
+value change, or should they be deleted or updated out of band. Deleting a sync
+entry deletes its secret. Values are never returned.
+
+The App needs the 'organization_secrets' write permission for organization
+secrets, the 'secrets' write permission for repository secrets, or the
+'environments' write permission for environment secrets.
+
+NOTE: Plugins cannot read the secrets of other Vault mounts, so values held
+elsewhere (e.g. in a KV mount) must be written to sync entries by the caller,
+for example with: vault kv get -field=token kv/npm | vault write github/sync/NPM_TOKEN org_name=acme value=-
+`
+	pathListSyncHelpSyn  = `List existing sync entries.`
+	pathListSyncHelpDesc = `List created sync entries.`
+)
+
+// pathSync defines the /github/sync paths on the backend.
+func (b *backend) pathSync() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: fmt.Sprintf("%s/%s", pathPatternSync, framework.GenericNameRegex("name")),
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Required. Name of the sync entry.",
+				},
+				keyOrgName: {
+					Type:        framework.TypeString,
+					Description: "Required. The organization (or user) that owns the secret.",
+				},
+				keyRepository: {
+					Type:        framework.TypeString,
+					Description: "The name of a repository that owns the secret, rather than the organization.",
+				},
+				keyEnvironment: {
+					Type:        framework.TypeString,
+					Description: "The name of an environment of the repository that owns the secret.",
+				},
+				keySecretName: {
+					Type:        framework.TypeString,
+					Description: descSecretName,
+				},
+				keyValue: {
+					Type:        framework.TypeString,
+					Description: "The value synced to the secret.",
+				},
+				keyValueFromEntry: {
+					Type:        framework.TypeString,
+					Description: descValueFromEntry,
+				},
+				keyVisibility: {
+					Type:        framework.TypeString,
+					Description: descVisibility,
+					AllowedValues: []any{
+						actionsSecretVisibilityAll,
+						actionsSecretVisibilityPrivate,
+						actionsSecretVisibilitySelected,
+					},
+				},
+				keySelectedRepositoryIDs: {
+					Type:        framework.TypeCommaIntSlice,
+					Description: "The IDs of the repositories that can access an organization secret of selected visibility.",
+				},
+				keyInstallationID: {
+					Type:        framework.TypeInt,
+					Description: "The ID of the App installation on the organization, saving a lookup.",
+				},
+				keyApp: {
+					Type:        framework.TypeString,
+					Description: descApp,
+				},
+			},
+			ExistenceCheck: b.pathSyncExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathSyncRead),
+				},
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathSyncWrite),
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathSyncWrite),
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: withFieldValidator(b.pathSyncDelete),
+				},
+			},
+			HelpSynopsis:    pathSyncHelpSyn,
+			HelpDescription: pathSyncHelpDesc,
+		},
+		{
+			Pattern: fmt.Sprintf("%s/?", pathPatternSync),
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.pathSyncList,
+				},
+			},
+			HelpSynopsis:    pathListSyncHelpSyn,
+			HelpDescription: pathListSyncHelpDesc,
+		},
+	}
+}
+
+// formatSyncTime formats the time of a sync, if any.
+func formatSyncTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// pathSyncRead corresponds to READ on /github/sync/:name. The value is never
+// returned.
+func (b *backend) pathSyncRead(
+	ctx context.Context, req *logical.Request, d *framework.FieldData,
+) (*logical.Response, error) {
+	e, err := getSyncEntry(ctx, d.Get("name").(string), req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if e == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]any{
+			keyOrgName:               e.OrgName,
+			keyRepository:            e.Repository,
+			keyEnvironment:           e.Environment,
+			keySecretName:            e.SecretName,
+			keyValueFromEntry:        e.ValueFromEntry,
+			keyVisibility:            e.Visibility,
+			keySelectedRepositoryIDs: e.SelectedRepositoryIDs,
+			keyInstallationID:        e.InstallationID,
+			keyApp:                   e.App,
+			"synced_at":              formatSyncTime(e.SyncedAt),
+			"reconciled_at":          formatSyncTime(e.ReconciledAt),
+			"last_error":             e.LastError,
+		},
+	}, nil
+}
+
+// pathSyncWrite corresponds to CREATE and UPDATE on /github/sync/:name. The
+// secret is pushed before the entry is saved, so that entries are only saved
+// once synced.
+func (b *backend) pathSyncWrite(
+	ctx context.Context, req *logical.Request, d *framework.FieldData,
+) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	b.syncLock.Lock()
+	defer b.syncLock.Unlock()
+
+	e, err := getSyncEntry(ctx, name, req.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", errUnableToGetSyncEntry, name, err)
+	}
+
+	// Keep the previous entry, whose secret is deleted as the App
+	// installation it was synced with should its target change.
+	var previous *syncEntry
+
+	if e == nil {
+		e = &syncEntry{Name: name, actionsSecretTarget: actionsSecretTarget{SecretName: name}}
+	} else {
+		prev := *e
+		previous = &prev
+	}
+
+	if orgName, ok := d.GetOk(keyOrgName); ok {
+		e.OrgName = orgName.(string)
+	}
+
+	if repo, ok := d.GetOk(keyRepository); ok {
+		e.Repository = repo.(string)
+	}
+
+	if env, ok := d.GetOk(keyEnvironment); ok {
+		e.Environment = env.(string)
+	}
+
+	if secretName, ok := d.GetOk(keySecretName); ok {
+		e.SecretName = secretName.(string)
+	}
+
+	if value, ok := d.GetOk(keyValue); ok {
+		e.Value, e.ValueFromEntry = value.(string), ""
+	}
+
+	if valueFromEntry, ok := d.GetOk(keyValueFromEntry); ok {
+		e.Value, e.ValueFromEntry = "", valueFromEntry.(string)
+	}
+
+	if visibility, ok := d.GetOk(keyVisibility); ok {
+		e.Visibility = visibility.(string)
+	}
+
+	if ids, ok := d.GetOk(keySelectedRepositoryIDs); ok {
+		e.SelectedRepositoryIDs = ids.([]int)
+	}
+
+	if installationID, ok := d.GetOk(keyInstallationID); ok {
+		e.InstallationID = installationID.(int)
+	}
+
+	if app, ok := d.GetOk(keyApp); ok {
+		e.App = app.(string)
+	}
+
+	if e.OrgName == "" {
+		return logical.ErrorResponse("%s is a required parameter", keyOrgName), nil
+	}
+
+	if e.Repository == "" && e.Visibility == "" {
+		e.Visibility = actionsSecretVisibilityPrivate
+	}
+
+	if err = b.validateSyncEntry(ctx, req.Storage, e); err != nil {
+		return nil, err
+	}
+
+	if err = b.pushSyncEntry(ctx, req.Storage, e, true, syncTriggerWrite); err != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToSyncSecret, err)
+	}
+
+	e.ReconciledAt, e.LastError = e.SyncedAt, ""
+
+	if err = e.save(ctx, req.Storage); err != nil {
+		return nil, err
+	}
+
+	// Delete the secret previously synced to another target.
+	if previous != nil && previous.actionsSecretTarget != e.actionsSecretTarget {
+		if err = b.deleteSyncedSecret(ctx, req.Storage, previous); err != nil {
+			return &logical.Response{Warnings: []string{fmt.Sprintf(
+				"%s %s: %s", errUnableToUnsyncSecret, &previous.actionsSecretTarget, err)}}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// validateSyncEntry validates the sync entry, returning client errors.
+func (b *backend) validateSyncEntry(ctx context.Context, s logical.Storage, e *syncEntry) error {
+	badRequest := func(format string, args ...any) error {
+		return logical.CodedError(http.StatusBadRequest, fmt.Sprintf(format, args...))
+	}
+
+	if !secretNameRegex.MatchString(e.SecretName) || strings.HasPrefix(strings.ToUpper(e.SecretName), "GITHUB_") {
+		return badRequest("%s %q must only contain alphanumeric characters or underscores, "+
+			"must not start with a number and must not start with GITHUB_", keySecretName, e.SecretName)
+	}
+
+	if e.Environment != "" && e.Repository == "" {
+		return badRequest("%s requires %s", keyEnvironment, keyRepository)
+	}
+
+	if e.Repository != "" && (e.Visibility != "" || len(e.SelectedRepositoryIDs) > 0) {
+		return badRequest("%s and %s only apply to organization secrets", keyVisibility, keySelectedRepositoryIDs)
+	}
+
+	if len(e.SelectedRepositoryIDs) > 0 && e.Visibility != actionsSecretVisibilitySelected {
+		return badRequest("%s requires the %s %s", keySelectedRepositoryIDs, actionsSecretVisibilitySelected, keyVisibility)
+	}
+
+	if e.ValueFromEntry == "" {
+		if e.Value == "" {
+			return badRequest("one of %s or %s is required", keyValue, keyValueFromEntry)
+		}
+
+		return nil
+	}
+
+	// References are a single level deep.
+	referrers, err := syncReferrers(ctx, s, e.Name)
+	if err != nil {
+		return err
+	}
+
+	if len(referrers) > 0 {
+		return badRequest("%s: %s is referenced by %s",
+			errSyncEntryIsReferenced, e.Name, strings.Join(referrers, ", "))
+	}
+
+	if e.ValueFromEntry == e.Name {
+		return badRequest("%s: %s refers to itself", errSyncValueFromEntryReference, keyValueFromEntry)
+	}
+
+	ref, err := getSyncEntry(ctx, e.ValueFromEntry, s)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", errUnableToGetSyncEntry, e.ValueFromEntry, err)
+	}
+
+	if ref == nil {
+		return badRequest("%s: %s", errSyncValueFromEntryNotFound, e.ValueFromEntry)
+	}
+
+	if ref.ValueFromEntry != "" {
+		return badRequest("%s: %s refers to %s", errSyncValueFromEntryReference, e.ValueFromEntry, ref.ValueFromEntry)
+	}
+
+	return nil
+}
+
+// pathSyncDelete corresponds to DELETE on /github/sync/:name. The secret is
+// deleted before the entry, which is kept should that fail.
+func (b *backend) pathSyncDelete(
+	ctx context.Context, req *logical.Request, d *framework.FieldData,
+) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	b.syncLock.Lock()
+	defer b.syncLock.Unlock()
+
+	e, err := getSyncEntry(ctx, name, req.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", errUnableToGetSyncEntry, name, err)
+	}
+
+	if e == nil {
+		return nil, nil
+	}
+
+	// Refuse to delete the values of other entries.
+	referrers, err := syncReferrers(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(referrers) > 0 {
+		return nil, logical.CodedError(http.StatusConflict, fmt.Sprintf("%s: %s is referenced by %s",
+			errSyncEntryIsReferenced, name, strings.Join(referrers, ", ")))
+	}
+
+	if err = b.deleteSyncedSecret(ctx, req.Storage, e); err != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToUnsyncSecret, err)
+	}
+
+	return nil, req.Storage.Delete(ctx, fmt.Sprintf("%s/%s", pathPatternSync, name))
+}
+
+// syncReferrers returns the names of the sync entries that refer to the value
+// of the named entry.
+func syncReferrers(ctx context.Context, s logical.Storage, name string) ([]string, error) {
+	names, err := s.List(ctx, pathPatternSync+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	var referrers []string
+
+	for _, other := range names {
+		e, err := getSyncEntry(ctx, other, s)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", errUnableToGetSyncEntry, other, err)
+		}
+
+		if e != nil && e.ValueFromEntry == name {
+			referrers = append(referrers, other)
+		}
+	}
+
+	return referrers, nil
+}
+
+// pathSyncList corresponds to LIST on /github/sync.
+func (b *backend) pathSyncList(
+	ctx context.Context, req *logical.Request, _ *framework.FieldData,
+) (*logical.Response, error) {
+	names, err := req.Storage.List(ctx, pathPatternSync+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	return logical.ListResponse(names), nil
+}
+
+func (b *backend) pathSyncExistenceCheck(
+	ctx context.Context, req *logical.Request, d *framework.FieldData,
+) (bool, error) {
+	e, err := getSyncEntry(ctx, d.Get("name").(string), req.Storage)
+	if err != nil {
+		return false, err
+	}
+
+	return e != nil, nil
+}
@@ -0,0 +1,419 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/nacl/box"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+const testActionsKeyID = "568250167242549743"
+
+// testSyncedSecret is an Actions secret as held by a test GitHub API server,
+// with its decrypted value.
+type testSyncedSecret struct {
+	UpdatedAt  time.Time
+	Value      string
+	Visibility string
+}
+
+// testActionsSecrets holds the Actions secrets of a fake GitHub API server by
+// API path, and the key pair that their values are encrypted with. Secrets are
+// refused while failing.
+type testActionsSecrets struct {
+	ts      *testGitHubServer
+	secrets map[string]testSyncedSecret
+	pub     *[32]byte
+	prv     *[32]byte
+	puts    int
+	failing bool
+}
+
+// newTestActionsSecrets serves the Actions secrets of organizations,
+// repositories and environments on the server.
+func newTestActionsSecrets(t *testing.T, ts *testGitHubServer) *testActionsSecrets {
+	t.Helper()
+
+	pub, prv, err := box.GenerateKey(rand.Reader)
+	assert.NilError(t, err)
+
+	actions := &testActionsSecrets{ts: ts, secrets: map[string]testSyncedSecret{}, pub: pub, prv: prv}
+
+	for _, secretsPath := range []string{
+		"/orgs/{org}/actions/secrets",
+		"/repos/{owner}/{repo}/actions/secrets",
+		"/repos/{owner}/{repo}/environments/{environment}/secrets",
+	} {
+		ts.handle("GET "+secretsPath+"/public-key", func(w http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(w, `{"key_id":%q,"key":%q}`,
+				testActionsKeyID, base64.StdEncoding.EncodeToString(pub[:]))
+		})
+		ts.handle("GET "+secretsPath+"/{name}", func(w http.ResponseWriter, r *http.Request) {
+			secret, ok := actions.secrets[strings.TrimPrefix(r.URL.Path, "/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			assert.NilError(t, json.NewEncoder(w).Encode(actionsSecret{
+				Name:      r.PathValue("name"),
+				UpdatedAt: secret.UpdatedAt,
+			}))
+		})
+		ts.handle("PUT "+secretsPath+"/{name}", func(w http.ResponseWriter, r *http.Request) {
+			if actions.failing {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+
+				return
+			}
+
+			var value actionsSecretValue
+			assert.NilError(t, json.NewDecoder(r.Body).Decode(&value))
+			assert.Equal(t, value.KeyID, testActionsKeyID)
+
+			sealed, err := base64.StdEncoding.DecodeString(value.EncryptedValue)
+			assert.NilError(t, err)
+
+			plain, opened := box.OpenAnonymous(nil, sealed, pub, prv)
+			assert.Assert(t, opened)
+
+			actions.puts++
+			actions.secrets[strings.TrimPrefix(r.URL.Path, "/")] = testSyncedSecret{
+				UpdatedAt:  time.Now().UTC(),
+				Value:      string(plain),
+				Visibility: value.Visibility,
+			}
+
+			w.WriteHeader(http.StatusCreated)
+		})
+		ts.handle("DELETE "+secretsPath+"/{name}", func(w http.ResponseWriter, r *http.Request) {
+			path := strings.TrimPrefix(r.URL.Path, "/")
+			if _, ok := actions.secrets[path]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			delete(actions.secrets, path)
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+
+	return actions
+}
+
+// secret returns the Actions secret at the API path, if any.
+func (a *testActionsSecrets) secret(path string) (testSyncedSecret, bool) {
+	a.ts.mu.Lock()
+	defer a.ts.mu.Unlock()
+
+	secret, ok := a.secrets[path]
+
+	return secret, ok
+}
+
+// pushes returns the number of Actions secrets created or updated.
+func (a *testActionsSecrets) pushes() int {
+	a.ts.mu.Lock()
+	defer a.ts.mu.Unlock()
+
+	return a.puts
+}
+
+func TestBackend_PathSyncValidation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := testBackend(t)
+
+	assert.NilError(t, (&syncEntry{
+		Name:                "NPM_TOKEN",
+		actionsSecretTarget: actionsSecretTarget{OrgName: testOrgName1, SecretName: "NPM_TOKEN"},
+		Value:               "s3cr3t",
+	}).save(ctx, storage))
+	assert.NilError(t, (&syncEntry{
+		Name:                "website-npm",
+		actionsSecretTarget: actionsSecretTarget{OrgName: testOrgName1, SecretName: "NPM_TOKEN"},
+		ValueFromEntry:      "NPM_TOKEN",
+	}).save(ctx, storage))
+
+	cases := []struct {
+		data map[string]any
+		name string
+		err  string
+	}{
+		{
+			name: "InvalidSecretName",
+			data: map[string]any{keyOrgName: testOrgName1, keyValue: "v", keySecretName: "npm-token"},
+			err:  `secret_name "npm-token" must only contain alphanumeric characters or underscores`,
+		},
+		{
+			name: "GitHubSecretName",
+			data: map[string]any{keyOrgName: testOrgName1, keyValue: "v", keySecretName: "github_token"},
+			err:  "must not start with GITHUB_",
+		},
+		{
+			name: "EnvironmentWithoutRepository",
+			data: map[string]any{keyOrgName: testOrgName1, keyValue: "v", keyEnvironment: "prod"},
+			err:  "environment requires repository",
+		},
+		{
+			name: "RepositoryVisibility",
+			data: map[string]any{keyOrgName: testOrgName1, keyValue: "v", keyRepository: "website", keyVisibility: "all"},
+			err:  "visibility and selected_repository_ids only apply to organization secrets",
+		},
+		{
+			name: "SelectedRepositoriesVisibility",
+			data: map[string]any{keyOrgName: testOrgName1, keyValue: "v", keySelectedRepositoryIDs: "1,2"},
+			err:  "selected_repository_ids requires the selected visibility",
+		},
+		{
+			name: "NoValue",
+			data: map[string]any{keyOrgName: testOrgName1},
+			err:  "one of value or value_from_entry is required",
+		},
+		{
+			name: "ValueFromEntryMissing",
+			data: map[string]any{keyOrgName: testOrgName1, keyValueFromEntry: "missing"},
+			err:  errSyncValueFromEntryNotFound.Error(),
+		},
+		{
+			name: "ValueFromEntrySelf",
+			data: map[string]any{keyOrgName: testOrgName1, keyValueFromEntry: "TEST_SECRET"},
+			err:  "value_from_entry refers to itself",
+		},
+		{
+			name: "ValueFromEntryReference",
+			data: map[string]any{keyOrgName: testOrgName1, keyValueFromEntry: "website-npm"},
+			err:  "website-npm refers to NPM_TOKEN",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.CreateOperation,
+				Path:      pathPatternSync + "/TEST_SECRET",
+				Data:      tc.data,
+			})
+			assert.ErrorContains(t, err, tc.err)
+
+			var coded logical.HTTPCodedError
+			assert.Assert(t, errors.As(err, &coded))
+			assert.Equal(t, coded.Code(), http.StatusBadRequest)
+		})
+	}
+
+	// Referenced entries cannot refer to others in turn.
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternSync + "/NPM_TOKEN",
+		Data:      map[string]any{keyValueFromEntry: "website-npm"},
+	})
+	assert.ErrorContains(t, err, "NPM_TOKEN is referenced by website-npm")
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternSync + "/TEST_SECRET",
+		Data:      map[string]any{keyValue: "v"},
+	})
+	assert.NilError(t, err)
+	assert.ErrorContains(t, r.Error(), "org_name is a required parameter")
+}
+
+func TestBackend_PathSync(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := newTestGitHubServer(t)
+	b, storage := testGitHubBackend(t, ts)
+
+	actions := newTestActionsSecrets(t, ts)
+
+	orgSecret := fmt.Sprintf("orgs/%s/actions/secrets/NPM_TOKEN", testOrgName1)
+	repoSecret := fmt.Sprintf("repos/%s/website/actions/secrets/NPM_TOKEN", testOrgName1)
+	envSecret := fmt.Sprintf("repos/%s/website/environments/prod/secrets/NPM_TOKEN", testOrgName1)
+
+	// Organization secret, named after the entry.
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternSync + "/NPM_TOKEN",
+		Data:      map[string]any{keyOrgName: testOrgName1, keyValue: "s3cr3t"},
+	})
+	assert.NilError(t, err)
+
+	secret, ok := actions.secret(orgSecret)
+	assert.Assert(t, ok)
+	assert.Equal(t, secret.Value, "s3cr3t")
+	assert.Equal(t, secret.Visibility, actionsSecretVisibilityPrivate)
+
+	// Values are never returned.
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternSync + "/NPM_TOKEN",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, r.Data[keySecretName], "NPM_TOKEN")
+	assert.Equal(t, r.Data[keyVisibility], actionsSecretVisibilityPrivate)
+	assert.Equal(t, r.Data["last_error"], "")
+	assert.Assert(t, r.Data["synced_at"] != "")
+	assert.Assert(t, !strings.Contains(fmt.Sprint(r.Data), "s3cr3t"))
+
+	// Repository secret, referring to the value of the organization secret.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternSync + "/website-npm",
+		Data: map[string]any{
+			keyOrgName:        testOrgName1,
+			keyRepository:     "website",
+			keySecretName:     "NPM_TOKEN",
+			keyValueFromEntry: "NPM_TOKEN",
+		},
+	})
+	assert.NilError(t, err)
+
+	secret, ok = actions.secret(repoSecret)
+	assert.Assert(t, ok)
+	assert.Equal(t, secret.Value, "s3cr3t")
+	assert.Equal(t, secret.Visibility, "")
+
+	// Referenced entries are not deleted.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.DeleteOperation,
+		Path:      pathPatternSync + "/NPM_TOKEN",
+	})
+	assert.ErrorContains(t, err, errSyncEntryIsReferenced.Error())
+
+	var coded logical.HTTPCodedError
+	assert.Assert(t, errors.As(err, &coded))
+	assert.Equal(t, coded.Code(), http.StatusConflict)
+
+	// Moving the secret to an environment deletes the repository secret.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternSync + "/website-npm",
+		Data:      map[string]any{keyEnvironment: "prod"},
+	})
+	assert.NilError(t, err)
+
+	_, ok = actions.secret(repoSecret)
+	assert.Assert(t, !ok)
+
+	secret, ok = actions.secret(envSecret)
+	assert.Assert(t, ok)
+	assert.Equal(t, secret.Value, "s3cr3t")
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ListOperation,
+		Path:      pathPatternSync,
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.Data["keys"], []string{"NPM_TOKEN", "website-npm"})
+
+	// Deleting entries deletes their secrets.
+	for _, name := range []string{"website-npm", "NPM_TOKEN"} {
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.DeleteOperation,
+			Path:      pathPatternSync + "/" + name,
+		})
+		assert.NilError(t, err)
+
+		r, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      pathPatternSync + "/" + name,
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, is.Nil(r))
+	}
+
+	_, ok = actions.secret(envSecret)
+	assert.Assert(t, !ok)
+
+	_, ok = actions.secret(orgSecret)
+	assert.Assert(t, !ok)
+}
+
+func TestBackend_PathSyncInstallationChange(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := newTestGitHubServer(t)
+	b, storage := testGitHubBackend(t, ts)
+
+	actions := newTestActionsSecrets(t, ts)
+
+	var otherTokens int
+
+	ts.handle(fmt.Sprintf("POST /app/installations/%d/access_tokens", testInsID2),
+		func(w http.ResponseWriter, _ *http.Request) {
+			otherTokens++
+
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"token":"` + testToken + `"}`))
+		})
+
+	orgSecret := fmt.Sprintf("orgs/%s/actions/secrets/NPM_TOKEN", testOrgName1)
+	renamedSecret := fmt.Sprintf("orgs/%s/actions/secrets/NODE_TOKEN", testOrgName1)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternSync + "/NPM_TOKEN",
+		Data:      map[string]any{keyOrgName: testOrgName1, keyValue: "s3cr3t", keyInstallationID: testInsID2},
+	})
+	assert.NilError(t, err)
+
+	_, ok := actions.secret(orgSecret)
+	assert.Assert(t, ok)
+
+	ts.mu.Lock()
+	synced := otherTokens
+	ts.mu.Unlock()
+
+	// Renaming the secret along with changing its installation deletes the
+	// previous secret as the installation it was synced with.
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternSync + "/NPM_TOKEN",
+		Data:      map[string]any{keySecretName: "NODE_TOKEN", keyInstallationID: testInsID1},
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, r == nil || len(r.Warnings) == 0)
+
+	_, ok = actions.secret(orgSecret)
+	assert.Assert(t, !ok)
+
+	_, ok = actions.secret(renamedSecret)
+	assert.Assert(t, ok)
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	assert.Equal(t, otherTokens, synced+1)
+}
@@ -2,7 +2,9 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 	"time"
 
@@ -30,6 +32,12 @@ const (
 	descPerms          = "The permissions granted to the token."
 	keyInstallationID  = "installation_id"
 	descInstallationID = "The ID of the App installation that the token should have access to."
+	keyResolveRepos    = "resolve_repositories" // NOTE: Not a real API attribute.
+	descResolveRepos   = "Resolve the repositories to IDs against those accessible to the installation first, reporting any that are not."
+	keyTTL             = "ttl" // NOTE: Not a real API attribute.
+	descTTL            = "The TTL of the lease of the token, if shorter than the token's lifetime. The token is revoked when the lease expires."
+	keyMaxTTL          = "max_ttl" // NOTE: Not a real API attribute.
+	descMaxTTL         = "The maximum TTL of the lease of the token."
 )
 
 //nolint:gosec // false positive.
@@ -56,7 +64,18 @@ The quickest way to find a repository ID: https://stackoverflow.com/a/47223479
 * %q is a map of permission names to their access type (read or write).
 
 Permission names taken from: https://developer.github.com/v3/apps/permissions
-`, keyInstallationID, keyOrgName, keyRepos, keyRepoIDs, keyPerms)
+They are checked against the catalog of permissions at 'permissions/catalog'.
+
+Set %q to resolve the repository names to IDs (and check the IDs) against the
+repositories accessible to the installation before the token is created. Any
+that are not accessible are listed in the error, rather than GitHub refusing
+the request as a whole. This costs additional requests to GitHub.
+
+The token is created by the default app unless %q names a configured app.
+
+The Vault lease of the token is aligned to its expiry (an hour) unless %q (and
+%q) shorten it. The token is revoked when its lease expires.
+`, keyInstallationID, keyOrgName, keyRepos, keyRepoIDs, keyPerms, keyResolveRepos, keyApp, keyTTL, keyMaxTTL)
 
 func (b *backend) pathToken() *framework.Path {
 	return &framework.Path{
@@ -82,6 +101,22 @@ func (b *backend) pathToken() *framework.Path {
 				Type:        framework.TypeKVPairs,
 				Description: descPerms,
 			},
+			keyResolveRepos: {
+				Type:        framework.TypeBool,
+				Description: descResolveRepos,
+			},
+			keyApp: {
+				Type:        framework.TypeString,
+				Description: descApp,
+			},
+			keyTTL: {
+				Type:        framework.TypeDurationSecond,
+				Description: descTTL,
+			},
+			keyMaxTTL: {
+				Type:        framework.TypeDurationSecond,
+				Description: descMaxTTL,
+			},
 		},
 		ExistenceCheck: b.pathTokenExistenceCheck,
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -108,17 +143,24 @@ func (b *backend) pathTokenWrite(
 	req *logical.Request,
 	d *framework.FieldData,
 ) (res *logical.Response, err error) {
-	client, done, err := b.Client(ctx, req.Storage)
+	// Safely parse the request and any options from interface types.
+	tokReq := &tokenRequest{
+		InstallationID: d.Get(keyInstallationID).(int),
+		OrgName:        d.Get(keyOrgName).(string),
+		App:            d.Get(keyApp).(string),
+		TTL:            time.Duration(d.Get(keyTTL).(int)) * time.Second,
+		MaxTTL:         time.Duration(d.Get(keyMaxTTL).(int)) * time.Second,
+	}
+
+	client, done, err := b.Client(ctx, req.Storage, tokReq.App)
 	if err != nil {
 		return nil, err
 	}
 
 	defer done()
 
-	// Safely parse the request and any options from interface types.
-	tokReq := &tokenRequest{
-		InstallationID: d.Get(keyInstallationID).(int),
-		OrgName:        d.Get(keyOrgName).(string),
+	if client.DisableAdhocTokens {
+		return nil, logical.CodedError(http.StatusForbidden, errAdhocTokensDisabled.Error())
 	}
 
 	if tokReq.InstallationID == 0 && tokReq.OrgName == "" {
@@ -129,8 +171,16 @@ func (b *backend) pathTokenWrite(
 		), nil
 	}
 
+	if err = tokReq.validateTTL(); err != nil {
+		return nil, logical.CodedError(http.StatusBadRequest, err.Error())
+	}
+
 	if perms, ok := d.GetOk(keyPerms); ok {
 		tokReq.Permissions = perms.(map[string]string)
+
+		if err = client.validatePermissions(tokReq.Permissions); err != nil {
+			return nil, logical.CodedError(http.StatusBadRequest, err.Error())
+		}
 	}
 
 	if repoIDs, ok := d.GetOk(keyRepoIDs); ok {
@@ -145,17 +195,25 @@ func (b *backend) pathTokenWrite(
 	// whether any constraints (permissions, repository IDs) were requested.
 	defer func(begin time.Time) {
 		duration := time.Since(begin)
+
+		// Error responses, e.g. listing unknown repositories, are failures too.
+		failure := err
+		if failure == nil {
+			failure = res.Error()
+		}
+
 		b.Logger().Debug("attempted to create a new installation token",
 			"took", duration.String(),
-			"err", err,
+			"err", failure,
 			"permissions", tokReq.Permissions,
 			"org_name", tokReq.OrgName,
+			"app", tokReq.App,
 			"installation_id", fmt.Sprint(tokReq.InstallationID),
 			"repository_ids", fmt.Sprint(tokReq.RepositoryIDs),
 			"repositories", fmt.Sprint(tokReq.Repositories),
 		)
 		requestDuration.With(prometheus.Labels{
-			"success":         strconv.FormatBool(err == nil),
+			"success":         strconv.FormatBool(failure == nil),
 			keyOrgName:        tokReq.OrgName,
 			keyInstallationID: fmt.Sprint(tokReq.InstallationID),
 			keyPerms:          strconv.FormatBool(len(tokReq.Permissions) > 0),
@@ -164,10 +222,61 @@ func (b *backend) pathTokenWrite(
 		}).Observe(duration.Seconds())
 	}(time.Now())
 
-	// Perform the token request.
+	// Check the installation before resolving repositories, which requires a
+	// token of the installation.
+	if err = client.CheckInstallationAllowed(ctx, tokReq); err != nil {
+		return nil, err
+	}
+
+	if d.Get(keyResolveRepos).(bool) {
+		if _, err = client.ResolveRepositories(ctx, tokReq); err != nil {
+			return unknownRepositoriesResponse(err)
+		}
+	}
+
+	if err = b.enforcePolicy(ctx, req.Storage, func() (*Client, error) { return client, nil },
+		tokReq, policySourceToken); err != nil {
+		return nil, err
+	}
+
+	// Perform the token request, from the cache if enabled.
+	if client.CacheAdhocTokens {
+		return client.CachedToken(ctx, tokReq, client.tokenCacheMinRemaining())
+	}
+
 	return client.Token(ctx, tokReq)
 }
 
+// unknownRepositoriesResponse returns the client error response listing the
+// unknown repositories of the given error, both in its message and as data, or
+// the error itself if it is of another kind.
+func unknownRepositoriesResponse(err error) (*logical.Response, error) {
+	var unknown *unknownRepositoriesError
+	if !errors.As(err, &unknown) {
+		return nil, err
+	}
+
+	names, ids := unknown.Names, unknown.IDs
+	if names == nil {
+		names = []string{}
+	}
+
+	if ids == nil {
+		ids = []int{}
+	}
+
+	// Vault returns the "data" of error responses alongside their error.
+	return &logical.Response{
+		Data: map[string]any{
+			"error": unknown.Error(),
+			"data": map[string]any{
+				"unknown_repositories":   names,
+				"unknown_repository_ids": ids,
+			},
+		},
+	}, nil
+}
+
 // pathTokenExistenceCheck always returns false to force the Create path. This
 // plugin predates the framework's 'ExistenceCheck' features and we wish to
 // avoid changing any contracts with the user at this stage. Tokens are created
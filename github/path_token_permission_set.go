@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
 	"time"
 
@@ -48,6 +49,10 @@ func (b *backend) pathTokenPermissionSet() *framework.Path {
 				Type:        framework.TypeString,
 				Description: "Required. Name of the permission set.",
 			},
+			keyTTL: {
+				Type:        framework.TypeDurationSecond,
+				Description: descTTL + " Overrides that of the permission set, up to its max_ttl.",
+			},
 		},
 		ExistenceCheck: b.pathTokenPermissionSetExistenceCheck,
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -74,21 +79,51 @@ func (b *backend) pathTokenPermissionSetWrite(
 	req *logical.Request,
 	d *framework.FieldData,
 ) (res *logical.Response, err error) {
-	client, done, err := b.Client(ctx, req.Storage)
+	psName := d.Get("permissionset").(string)
+
+	ps, err := getPermissionSet(ctx, psName, req.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", errUnableToGetPermissionSet, psName, err)
+	}
+
+	if ps == nil {
+		return logical.ErrorResponse("permission set '%s' does not exist", psName), nil
+	}
+
+	opts := ps.TokenRequest
+
+	client, done, err := b.Client(ctx, req.Storage, opts.App)
 	if err != nil {
 		return nil, err
 	}
 
 	defer done()
 
-	psName := d.Get("permissionset").(string)
+	// Override the lease TTL of the token if requested, within the permission
+	// set's maximum TTL. Without one, the permission set's TTL is the maximum.
+	if ttl, ok := d.GetOk(keyTTL); ok {
+		withTTL := *opts
+		withTTL.TTL = time.Duration(ttl.(int)) * time.Second
+		opts = &withTTL
 
-	ps, _ := getPermissionSet(ctx, psName, req.Storage)
-	if ps == nil {
-		return logical.ErrorResponse("permission set '%s' does not exist", psName), nil
+		if opts.MaxTTL == 0 && ps.TokenRequest.TTL > 0 {
+			opts.TTL = min(opts.TTL, ps.TokenRequest.TTL)
+		}
+
+		if err = opts.validateTTL(); err != nil {
+			return nil, logical.CodedError(http.StatusBadRequest, err.Error())
+		}
 	}
 
-	opts := ps.TokenRequest
+	if opts.templated() {
+		if opts, err = b.renderPermissionSet(req, opts); err != nil {
+			return nil, err
+		}
+
+		if err = client.validatePermissions(opts.Permissions); err != nil {
+			return nil, logical.CodedError(http.StatusBadRequest, err.Error())
+		}
+	}
 
 	// Instrument and log the token API call, recording status, duration and
 	// whether any constraints (permissions, repositories, repository IDs) were
@@ -100,6 +135,7 @@ func (b *backend) pathTokenPermissionSetWrite(
 			"err", err,
 			"permissions", opts.Permissions,
 			"org_name", opts.OrgName,
+			"app", opts.App,
 			"installation_id", fmt.Sprint(opts.InstallationID),
 			"repository_ids", fmt.Sprint(opts.RepositoryIDs),
 			"repositories", fmt.Sprint(opts.Repositories),
@@ -114,10 +150,57 @@ func (b *backend) pathTokenPermissionSetWrite(
 		}).Observe(duration.Seconds())
 	}(time.Now())
 
-	// Perform the token request.
+	// Enforce the installation rules and policy as they stand, which may have
+	// changed since the permission set was written.
+	if err = client.CheckInstallationAllowed(ctx, opts); err != nil {
+		return nil, err
+	}
+
+	if err = b.enforcePolicy(ctx, req.Storage, func() (*Client, error) { return client, nil },
+		opts, policySourcePermissionSet); err != nil {
+		return nil, err
+	}
+
+	// Perform the token request, from the cache if enabled.
+	if ps.CacheTokens {
+		minRemaining := ps.CacheMinRemaining
+		if minRemaining == 0 {
+			minRemaining = client.tokenCacheMinRemaining()
+		}
+
+		return client.CachedToken(ctx, opts, minRemaining)
+	}
+
 	return client.Token(ctx, opts)
 }
 
+// renderPermissionSet resolves the identity templates of the token request of
+// a permission set for the requesting entity.
+func (b *backend) renderPermissionSet(req *logical.Request, tokReq *tokenRequest) (*tokenRequest, error) {
+	var (
+		entity *logical.Entity
+		groups []*logical.Group
+		err    error
+	)
+
+	if req.EntityID != "" {
+		if entity, err = b.System().EntityInfo(req.EntityID); err != nil {
+			return nil, err
+		}
+
+		if groups, err = b.System().GroupsForEntity(req.EntityID); err != nil {
+			return nil, err
+		}
+	}
+
+	rendered, err := tokReq.renderTemplates(entity, groups)
+	if err != nil {
+		return nil, logical.CodedError(http.StatusBadRequest, err.Error())
+	}
+
+	return rendered, nil
+}
+
 // pathTokenPermissionSetExistenceCheck always returns false to force the Create
 // path. This plugin predates the framework's 'ExistenceCheck' features and we
 // wish to avoid changing any contracts with the user at this stage. Tokens are
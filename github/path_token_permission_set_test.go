@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/logical"
 	"gotest.tools/assert"
@@ -130,7 +131,7 @@ func testBackendPathTokenPermissionSetWrite(t *testing.T, op logical.Operation)
 		))
 	})
 
-	t.Run("FailedClient", func(t *testing.T) {
+	t.Run("FailedStorage", func(t *testing.T) {
 		t.Parallel()
 
 		b, storage := testBackend(t, failVerbRead)
@@ -140,7 +141,7 @@ func testBackendPathTokenPermissionSetWrite(t *testing.T, op logical.Operation)
 			Operation: op,
 			Path:      fmt.Sprintf("%s/foo", pathPatternToken),
 		})
-		assert.ErrorContains(t, err, errConfRetrieval.Error())
+		assert.ErrorContains(t, err, errUnableToGetPermissionSet.Error())
 		assert.Assert(t, is.Nil(r))
 	})
 
@@ -187,3 +188,128 @@ func TestBackend_PathTokenPermissionSetWriteUpdate(t *testing.T) {
 	t.Parallel()
 	testBackendPathTokenPermissionSetWrite(t, logical.UpdateOperation)
 }
+
+func TestBackend_PathTokenPermissionSetTTL(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := testBackend(t)
+
+	ts := testRateLimitServer(t, 5000, time.Now().Add(time.Hour))
+	defer ts.Close()
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			keyAppID:   testAppID1,
+			keyPrvKey:  testPrvKeyValid,
+			keyBaseURL: ts.URL,
+		},
+	})
+	assert.NilError(t, err)
+
+	// The TTL of a permission set cannot exceed its maximum.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternPermissionSet + "/ci",
+		Data: map[string]any{
+			keyInstallationID: testInsID1,
+			keyTTL:            "1h",
+			keyMaxTTL:         "15m",
+		},
+	})
+	assert.ErrorContains(t, err, errTTLExceedsMaxTTL.Error())
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternPermissionSet + "/ci",
+		Data: map[string]any{
+			keyInstallationID: testInsID1,
+			keyTTL:            "10m",
+			keyMaxTTL:         "15m",
+		},
+	})
+	assert.NilError(t, err)
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternPermissionSet + "/ci",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, r.Data[keyTTL], int64(600))
+	assert.Equal(t, r.Data[keyMaxTTL], int64(900))
+
+	cases := []struct {
+		data   map[string]any
+		name   string
+		err    string
+		expTTL time.Duration
+	}{
+		{
+			name:   "PermissionSetTTL",
+			expTTL: 10 * time.Minute,
+		},
+		{
+			name:   "Shortened",
+			data:   map[string]any{keyTTL: "2m"},
+			expTTL: 2 * time.Minute,
+		},
+		{
+			name:   "Lengthened",
+			data:   map[string]any{keyTTL: "12m"},
+			expTTL: 12 * time.Minute,
+		},
+		{
+			name: "ExceedsMaxTTL",
+			data: map[string]any{keyTTL: "20m"},
+			err:  errTTLExceedsMaxTTL.Error(),
+		},
+	}
+
+	// NOTE: Not parallel, to share the test server.
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      pathPatternToken + "/ci",
+				Data:      tc.data,
+			})
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, r.Secret.TTL, tc.expTTL)
+			assert.Equal(t, r.Secret.MaxTTL, 15*time.Minute)
+		})
+	}
+
+	// Without a maximum TTL, requested TTLs are capped at the permission set's.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternPermissionSet + "/capped",
+		Data: map[string]any{
+			keyInstallationID: testInsID1,
+			keyTTL:            "10m",
+		},
+	})
+	assert.NilError(t, err)
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternToken + "/capped",
+		Data:      map[string]any{keyTTL: "30m"},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, r.Secret.TTL, 10*time.Minute)
+}
@@ -3,10 +3,12 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/logical"
 	"gotest.tools/assert"
@@ -201,3 +203,116 @@ func TestBackend_PathTokenWriteUpdate(t *testing.T) {
 	t.Parallel()
 	testBackendPathTokenWrite(t, logical.UpdateOperation)
 }
+
+func TestBackend_PathTokenWriteResolveRepositories(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := testBackend(t)
+
+	var rec testRepositoriesRecorder
+
+	ts := testRepositoriesServer(t, &rec, http.StatusOK)
+	defer ts.Close()
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			keyAppID:   testAppID1,
+			keyPrvKey:  testPrvKeyValid,
+			keyBaseURL: ts.URL,
+		},
+	})
+	assert.NilError(t, err)
+
+	// Unknown repositories are listed in a client error response.
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternToken,
+		Data: map[string]any{
+			keyOrgName:      testOrgName1,
+			keyRepos:        []string{"public", "typo"},
+			keyResolveRepos: true,
+		},
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, r.IsError())
+	assert.ErrorContains(t, r.Error(), errUnknownRepositories.Error()+": repositories typo")
+	assert.DeepEqual(t, r.Data["data"], map[string]any{
+		"unknown_repositories":   []string{"typo"},
+		"unknown_repository_ids": []int{},
+	})
+
+	// Known repositories are requested by ID.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternToken,
+		Data: map[string]any{
+			keyOrgName:      testOrgName1,
+			keyRepos:        []string{"public", "archived"},
+			keyResolveRepos: true,
+		},
+	})
+	assert.NilError(t, err)
+
+	minted := rec.minted()
+	assert.DeepEqual(t, minted[len(minted)-1], tokenConstraints{RepositoryIDs: []int{1, 2}})
+}
+
+func TestBackend_PathTokenWriteTTL(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := testBackend(t)
+
+	ts := testRateLimitServer(t, 5000, time.Now().Add(time.Hour))
+	defer ts.Close()
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			keyAppID:   testAppID1,
+			keyPrvKey:  testPrvKeyValid,
+			keyBaseURL: ts.URL,
+		},
+	})
+	assert.NilError(t, err)
+
+	// The lease is shortened, and revokes the token when it expires.
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternToken,
+		Data: map[string]any{
+			keyInstallationID: testInsID1,
+			keyTTL:            "5m",
+			keyMaxTTL:         "10m",
+		},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, r.Secret.TTL, 5*time.Minute)
+	assert.Equal(t, r.Secret.MaxTTL, 10*time.Minute)
+	assert.Equal(t, r.Secret.InternalData["secret_type"], backendSecretType)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternToken,
+		Data: map[string]any{
+			keyInstallationID: testInsID1,
+			keyTTL:            "20m",
+			keyMaxTTL:         "10m",
+		},
+	})
+	assert.ErrorContains(t, err, errTTLExceedsMaxTTL.Error())
+
+	var coded logical.HTTPCodedError
+	assert.Assert(t, errors.As(err, &coded))
+	assert.Equal(t, coded.Code(), http.StatusBadRequest)
+}
@@ -0,0 +1,27 @@
+package github
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// periodicFunc is called by Vault periodically (every minute by default) to
+// perform housekeeping that must survive plugin restarts. It is left to the
+// active node of the primary cluster, as performance standbys and secondaries
+// cannot write to storage and would otherwise duplicate its calls to GitHub.
+func (b *backend) periodicFunc(ctx context.Context, req *logical.Request) error {
+	if b.System().ReplicationState().HasState(
+		consts.ReplicationPerformanceStandby | consts.ReplicationPerformanceSecondary,
+	) {
+		return nil
+	}
+
+	return errors.Join(
+		b.sweepGrants(ctx, req.Storage, jitGrants),
+		b.sweepGrants(ctx, req.Storage, collaboratorGrants),
+		b.reconcileSyncEntries(ctx, req.Storage),
+	)
+}
@@ -0,0 +1,50 @@
+package github
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/assert"
+)
+
+func TestBackend_PeriodicFuncReplicationState(t *testing.T) {
+	t.Parallel()
+
+	for name, state := range map[string]consts.ReplicationState{
+		"PerformanceStandby":   consts.ReplicationPerformanceStandby,
+		"PerformanceSecondary": consts.ReplicationPerformanceSecondary,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ts := newTestGitHubServer(t)
+			b, storage := testGitHubBackend(t, ts)
+
+			team := newTestTeam(t, ts, &testTeam{members: map[string]string{"expired": teamRoleMember}})
+
+			assert.NilError(t, (&grant{
+				ExpiresAt:      time.Now().Add(-time.Minute),
+				ID:             "expired",
+				OrgName:        testOrgName1,
+				Team:           "admins",
+				Username:       "expired",
+				InstallationID: testInsID1,
+			}).save(ctx, storage, jitGrants))
+
+			// Housekeeping is left to the active node of the primary cluster.
+			b.System().(*logical.StaticSystemView).ReplicationStateVal = state
+
+			assert.NilError(t, b.periodicFunc(ctx, &logical.Request{Storage: storage}))
+			assert.Equal(t, team.role("expired"), teamRoleMember)
+			assert.Equal(t, len(ts.tokenRequests()), 0)
+
+			grants, err := storage.List(ctx, jitGrantsStoragePrefix)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, grants, []string{"expired"})
+		})
+	}
+}
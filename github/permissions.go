@@ -0,0 +1,237 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	errInvalidPermissions    = Error("invalid permissions")
+	errPermissionsNotGranted = Error("permissions not granted to installation")
+)
+
+// Permission access levels.
+const (
+	permissionRead  = "read"
+	permissionWrite = "write"
+	permissionAdmin = "admin"
+)
+
+// permissionLevels ranks access levels, each including those below it.
+var permissionLevels = map[string]int{
+	permissionRead:  1,
+	permissionWrite: 2,
+	permissionAdmin: 3,
+}
+
+var (
+	readWrite      = []string{permissionRead, permissionWrite}
+	readWriteAdmin = []string{permissionRead, permissionWrite, permissionAdmin}
+)
+
+// permissionCatalog is the built-in catalog of the permissions that can be
+// requested of GitHub App installation tokens and their allowed access levels,
+// as per https://docs.github.com/en/rest/apps/apps#create-an-installation-access-token-for-an-app.
+var permissionCatalog = map[string][]string{
+	// Repository permissions.
+	"actions":                      readWrite,
+	"administration":               readWrite,
+	"checks":                       readWrite,
+	"codespaces":                   readWrite,
+	"contents":                     readWrite,
+	"dependabot_secrets":           readWrite,
+	"deployments":                  readWrite,
+	"environments":                 readWrite,
+	"issues":                       readWrite,
+	"metadata":                     readWrite,
+	"packages":                     readWrite,
+	"pages":                        readWrite,
+	"pull_requests":                readWrite,
+	"repository_custom_properties": readWrite,
+	"repository_hooks":             readWrite,
+	"repository_projects":          readWriteAdmin,
+	"secret_scanning_alerts":       readWrite,
+	"secrets":                      readWrite,
+	"security_events":              readWrite,
+	"single_file":                  readWrite,
+	"statuses":                     readWrite,
+	"vulnerability_alerts":         readWrite,
+	"workflows":                    {permissionWrite},
+
+	// Organization permissions.
+	"members":                                     readWrite,
+	"organization_administration":                 readWrite,
+	"organization_announcement_banners":           readWrite,
+	"organization_copilot_seat_management":        {permissionWrite},
+	"organization_custom_org_roles":               readWrite,
+	"organization_custom_properties":              readWriteAdmin,
+	"organization_custom_roles":                   readWrite,
+	"organization_events":                         {permissionRead},
+	"organization_hooks":                          readWrite,
+	"organization_packages":                       readWrite,
+	"organization_personal_access_token_requests": readWrite,
+	"organization_personal_access_tokens":         readWrite,
+	"organization_plan":                           {permissionRead},
+	"organization_projects":                       readWriteAdmin,
+	"organization_secrets":                        readWrite,
+	"organization_self_hosted_runners":            readWrite,
+	"organization_user_blocking":                  readWrite,
+	"team_discussions":                            readWrite,
+
+	// Account permissions.
+	"email_addresses":    readWrite,
+	"followers":          readWrite,
+	"git_ssh_keys":       readWrite,
+	"gpg_keys":           readWrite,
+	"interaction_limits": readWrite,
+	"profile":            {permissionWrite},
+	"starring":           readWrite,
+}
+
+// permissionCatalog returns the effective permission catalog: the built-in
+// catalog with the configured overrides applied. An override with no access
+// levels removes the permission.
+func (c *Config) permissionCatalog() map[string][]string {
+	catalog := maps.Clone(permissionCatalog)
+
+	for name, levels := range c.PermissionCatalog {
+		if strings.TrimSpace(levels) == "" {
+			delete(catalog, name)
+
+			continue
+		}
+
+		catalog[name] = splitAccessLevels(levels)
+	}
+
+	return catalog
+}
+
+// splitAccessLevels splits comma separated access levels, trimming spaces
+// around each.
+func splitAccessLevels(levels string) []string {
+	split := strings.Split(levels, ",")
+	for i := range split {
+		split[i] = strings.TrimSpace(split[i])
+	}
+
+	return split
+}
+
+// validatePermissions checks the requested permissions against the effective
+// permission catalog, listing every unknown permission and disallowed access
+// level.
+func (c *Config) validatePermissions(perms map[string]string) error {
+	catalog := c.permissionCatalog()
+
+	var invalid []string
+
+	for _, name := range slices.Sorted(maps.Keys(perms)) {
+		levels, ok := catalog[name]
+		if !ok {
+			invalid = append(invalid, fmt.Sprintf("unknown permission %q", name))
+
+			continue
+		}
+
+		if level := perms[name]; !slices.Contains(levels, level) {
+			invalid = append(invalid, fmt.Sprintf("%s does not allow %q (allowed: %s)",
+				name, level, strings.Join(levels, ", ")))
+		}
+	}
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("%w: %s", errInvalidPermissions, strings.Join(invalid, "; "))
+	}
+
+	return nil
+}
+
+// validatePermissionCatalog checks configured permission catalog overrides.
+func validatePermissionCatalog(overrides map[string]string) error {
+	for name, levels := range overrides {
+		if strings.TrimSpace(levels) == "" {
+			continue
+		}
+
+		for _, level := range splitAccessLevels(levels) {
+			if !slices.Contains(readWriteAdmin, level) {
+				return fmt.Errorf("%w: %s: unknown access level %q", errInvalidPermissions, name, level)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GrantedPermissions returns the permissions granted to the installation. They
+// are cached for as long as the installations cache.
+func (c *Client) GrantedPermissions(ctx context.Context, installationID int) (map[string]string, error) {
+	cache := c.installations
+
+	cache.mu.Lock()
+	granted, ok := cache.granted[installationID]
+	cache.mu.Unlock()
+
+	if ok && time.Since(granted.fetchedAt) < c.installationCacheTTL() {
+		return granted.permissions, nil
+	}
+
+	inst, err := c.Installation(ctx, installationID)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.mu.Lock()
+	if cache.granted == nil {
+		cache.granted = make(map[int]grantedPermissions)
+	}
+
+	cache.granted[installationID] = grantedPermissions{
+		fetchedAt:   time.Now(),
+		permissions: inst.Permissions,
+	}
+	cache.mu.Unlock()
+
+	return inst.Permissions, nil
+}
+
+// checkGrantedPermissions checks that the permissions of the token request
+// were granted to its installation, listing every one that was not.
+func (c *Client) checkGrantedPermissions(ctx context.Context, tokReq *tokenRequest) error {
+	granted, err := c.GrantedPermissions(ctx, tokReq.InstallationID)
+	if err != nil {
+		return err
+	}
+
+	if ungranted := ungrantedPermissions(tokReq.Permissions, granted); len(ungranted) > 0 {
+		return fmt.Errorf("%w %d: %s", errPermissionsNotGranted,
+			tokReq.InstallationID, strings.Join(ungranted, "; "))
+	}
+
+	return nil
+}
+
+// ungrantedPermissions lists the requested permissions that exceed those
+// granted, e.g. "issues (requested write, granted read)".
+func ungrantedPermissions(requested, granted map[string]string) []string {
+	var ungranted []string
+
+	for _, name := range slices.Sorted(maps.Keys(requested)) {
+		level, grantedLevel := requested[name], granted[name]
+
+		switch {
+		case grantedLevel == "":
+			ungranted = append(ungranted, fmt.Sprintf("%s (requested %s, not granted)", name, level))
+		case permissionLevels[level] > permissionLevels[grantedLevel]:
+			ungranted = append(ungranted, fmt.Sprintf("%s (requested %s, granted %s)",
+				name, level, grantedLevel))
+		}
+	}
+
+	return ungranted
+}
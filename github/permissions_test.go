@@ -0,0 +1,243 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/assert"
+)
+
+func TestConfig_ValidatePermissions(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		catalog map[string]string
+		perms   map[string]string
+		name    string
+		err     string
+	}{
+		{
+			name:  "None",
+			perms: nil,
+		},
+		{
+			name:  "Valid",
+			perms: map[string]string{"contents": "read", "repository_projects": "admin", "workflows": "write"},
+		},
+		{
+			name:  "UnknownPermission",
+			perms: map[string]string{"contnets": "read"},
+			err:   `invalid permissions: unknown permission "contnets"`,
+		},
+		{
+			name:  "DisallowedLevel",
+			perms: map[string]string{"contents": "admin", "workflows": "read"},
+			err: `invalid permissions: contents does not allow "admin" (allowed: read, write); ` +
+				`workflows does not allow "read" (allowed: write)`,
+		},
+		{
+			name:    "OverrideAdded",
+			catalog: map[string]string{"custom_thing": "read"},
+			perms:   map[string]string{"custom_thing": "read"},
+		},
+		{
+			name:    "OverrideReplaced",
+			catalog: map[string]string{"contents": "read"},
+			perms:   map[string]string{"contents": "write"},
+			err:     `invalid permissions: contents does not allow "write" (allowed: read)`,
+		},
+		{
+			name:    "OverrideSpaced",
+			catalog: map[string]string{"custom_thing": "read, write"},
+			perms:   map[string]string{"custom_thing": "write"},
+		},
+		{
+			name:    "OverrideRemoved",
+			catalog: map[string]string{"codespaces": ""},
+			perms:   map[string]string{"codespaces": "read"},
+			err:     `invalid permissions: unknown permission "codespaces"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := &Config{PermissionCatalog: tc.catalog}
+
+			err := c.validatePermissions(tc.perms)
+			if tc.err != "" {
+				assert.Error(t, err, tc.err)
+
+				return
+			}
+
+			assert.NilError(t, err)
+		})
+	}
+
+	t.Run("CatalogUnchangedByOverrides", func(t *testing.T) {
+		t.Parallel()
+
+		c := &Config{PermissionCatalog: map[string]string{"contents": "read", "actions": ""}}
+		assert.DeepEqual(t, c.permissionCatalog()["contents"], []string{"read"})
+		assert.DeepEqual(t, permissionCatalog["contents"], readWrite)
+		assert.DeepEqual(t, permissionCatalog["actions"], readWrite)
+	})
+}
+
+func TestValidatePermissionCatalog(t *testing.T) {
+	t.Parallel()
+
+	assert.NilError(t, validatePermissionCatalog(map[string]string{"a": "read,write,admin", "b": ""}))
+	assert.NilError(t, validatePermissionCatalog(map[string]string{"a": "read, write ,admin", "b": " "}))
+	assert.Error(t, validatePermissionCatalog(map[string]string{"a": "read,owner"}),
+		`invalid permissions: a: unknown access level "owner"`)
+}
+
+func TestUngrantedPermissions(t *testing.T) {
+	t.Parallel()
+
+	granted := map[string]string{"contents": "write", "issues": "read", "organization_projects": "admin"}
+
+	cases := []struct {
+		requested map[string]string
+		name      string
+		exp       []string
+	}{
+		{
+			name:      "Equal",
+			requested: map[string]string{"contents": "write", "issues": "read"},
+		},
+		{
+			name:      "Lower",
+			requested: map[string]string{"contents": "read", "organization_projects": "write"},
+		},
+		{
+			name:      "Higher",
+			requested: map[string]string{"issues": "write", "contents": "admin"},
+			exp: []string{
+				"contents (requested admin, granted write)",
+				"issues (requested write, granted read)",
+			},
+		},
+		{
+			name:      "NotGranted",
+			requested: map[string]string{"pages": "read"},
+			exp:       []string{"pages (requested read, not granted)"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.DeepEqual(t, ungrantedPermissions(tc.requested, granted), tc.exp)
+		})
+	}
+}
+
+// testGrantedPermissionsServer stubs GitHub, granting testInsID1 contents
+// write and issues read, and counting the installation requests it receives.
+func testGrantedPermissionsServer(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			t.Helper()
+
+			switch r.URL.Path {
+			case fmt.Sprintf("/app/installations/%d", testInsID1):
+				calls.Add(1)
+				w.Write([]byte(`{"id":` + strconv.Itoa(testInsID1) +
+					`,"permissions":{"contents":"write","issues":"read"}}`))
+			case fmt.Sprintf("/app/installations/%d/access_tokens", testInsID1):
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"token":"` + testToken + `"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}),
+	)
+}
+
+func TestClient_TokenCheckGrantedPermissions(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		perms    map[string]string
+		name     string
+		err      string
+		check    bool
+		expCalls int32
+	}{
+		{
+			name:     "Granted",
+			check:    true,
+			perms:    map[string]string{"contents": "read", "issues": "read"},
+			expCalls: 1,
+		},
+		{
+			name:     "NotGranted",
+			check:    true,
+			perms:    map[string]string{"contents": "write", "issues": "write", "pages": "read"},
+			err:      "issues (requested write, granted read); pages (requested read, not granted)",
+			expCalls: 1,
+		},
+		{
+			name:  "Unconstrained",
+			check: true,
+		},
+		{
+			name:  "Disabled",
+			perms: map[string]string{"issues": "write"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+
+			ts := testGrantedPermissionsServer(t, &calls)
+			defer ts.Close()
+
+			client, err := NewClient(&Config{
+				AppID:                   testAppID1,
+				PrvKey:                  testPrvKeyValid,
+				BaseURL:                 ts.URL,
+				CheckGrantedPermissions: tc.check,
+			})
+			assert.NilError(t, err)
+
+			// The granted permissions are cached.
+			for range 2 {
+				_, err = client.Token(context.Background(), &tokenRequest{
+					InstallationID:   testInsID1,
+					tokenConstraints: tokenConstraints{Permissions: tc.perms},
+				})
+
+				if tc.err != "" {
+					assert.ErrorContains(t, err, fmt.Sprintf("%s %d: %s", errPermissionsNotGranted, testInsID1, tc.err))
+
+					var coded logical.HTTPCodedError
+					assert.Assert(t, errors.As(err, &coded))
+					assert.Equal(t, coded.Code(), http.StatusBadRequest)
+
+					continue
+				}
+
+				assert.NilError(t, err)
+			}
+
+			assert.Equal(t, calls.Load(), tc.expCalls)
+		})
+	}
+}
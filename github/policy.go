@@ -0,0 +1,247 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	errPolicyViolation = Error("denied by policy")
+	errInvalidPolicy   = Error("invalid policy")
+)
+
+// Sources of the token requests that policies are enforced on.
+const (
+	policySourceToken         = "token"
+	policySourcePermissionSet = "permissionset"
+)
+
+// policyViolations counts the permissions refused by policy.
+var policyViolations = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: fmt.Sprintf("%s_policy_violations_total", prefixMetrics),
+	Help: "Total permissions refused by policy, by request source and permission.",
+}, []string{"source", "permission"})
+
+// Policy constrains the permissions that tokens can be requested with.
+type Policy struct {
+	// MaxPermissions are the highest access levels that can be requested of
+	// permissions, e.g. "contents": "read".
+	MaxPermissions map[string]string `json:"max_permissions,omitempty"`
+
+	// DeniedPermissions are permissions that cannot be requested at all.
+	DeniedPermissions []string `json:"denied_permissions,omitempty"`
+}
+
+// empty reports whether the policy constrains nothing.
+func (p *Policy) empty() bool {
+	return p == nil || (len(p.MaxPermissions) == 0 && len(p.DeniedPermissions) == 0)
+}
+
+// validate checks the access levels of the policy.
+func (p *Policy) validate() error {
+	for _, name := range slices.Sorted(maps.Keys(p.MaxPermissions)) {
+		if level := p.MaxPermissions[name]; permissionLevels[level] == 0 {
+			return fmt.Errorf("%w: %s: unknown access level %q", errInvalidPolicy, name, level)
+		}
+	}
+
+	return nil
+}
+
+// merge returns the stricter combination of the policy and another: the
+// lower of their maximum access levels and all of their denied permissions.
+func (p *Policy) merge(o *Policy) *Policy {
+	merged := &Policy{MaxPermissions: make(map[string]string)}
+
+	for _, policy := range []*Policy{p, o} {
+		if policy == nil {
+			continue
+		}
+
+		for name, level := range policy.MaxPermissions {
+			if current, ok := merged.MaxPermissions[name]; !ok || permissionLevels[level] < permissionLevels[current] {
+				merged.MaxPermissions[name] = level
+			}
+		}
+
+		for _, name := range policy.DeniedPermissions {
+			if !slices.Contains(merged.DeniedPermissions, name) {
+				merged.DeniedPermissions = append(merged.DeniedPermissions, name)
+			}
+		}
+	}
+
+	return merged
+}
+
+// violations lists the requested permissions the policy refuses, keyed by
+// permission. Requesting no permissions at all is a violation of a policy
+// that constrains anything, since the token would have every permission of
+// the installation.
+func (p *Policy) violations(perms map[string]string) map[string]string {
+	if p.empty() {
+		return nil
+	}
+
+	if len(perms) == 0 {
+		return map[string]string{"*": "permissions must be requested explicitly"}
+	}
+
+	violations := make(map[string]string)
+
+	for name, level := range perms {
+		if slices.Contains(p.DeniedPermissions, name) {
+			violations[name] = fmt.Sprintf("%s (denied)", name)
+
+			continue
+		}
+
+		if maxLevel, ok := p.MaxPermissions[name]; ok && permissionLevels[level] > permissionLevels[maxLevel] {
+			violations[name] = fmt.Sprintf("%s (requested %s, maximum %s)", name, level, maxLevel)
+		}
+	}
+
+	return violations
+}
+
+// policies are the mount policy and the installation policies, keyed by
+// lower-cased installation ID or organization name. They are stored together
+// so that they can be enforced with a single storage read.
+type policies struct {
+	Mount         *Policy            `json:"mount,omitempty"`
+	Installations map[string]*Policy `json:"installations,omitempty"`
+}
+
+// getPolicies returns the stored policies, which are empty if there are none.
+func getPolicies(ctx context.Context, s logical.Storage) (*policies, error) {
+	p := &policies{Installations: make(map[string]*Policy)}
+
+	entry, err := s.Get(ctx, pathPatternConfigPolicy)
+	if err != nil || entry == nil {
+		return p, err
+	}
+
+	if err = entry.DecodeJSON(p); err != nil {
+		return nil, err
+	}
+
+	if p.Installations == nil {
+		p.Installations = make(map[string]*Policy)
+	}
+
+	return p, nil
+}
+
+// save persists the policies.
+func (p *policies) save(ctx context.Context, s logical.Storage) error {
+	entry, err := logical.StorageEntryJSON(pathPatternConfigPolicy, p)
+	if err != nil {
+		return err
+	}
+
+	return s.Put(ctx, entry)
+}
+
+// get returns the policy of the installation with the given ID or
+// organization name, or of the mount for an empty name.
+func (p *policies) get(installation string) *Policy {
+	if installation == "" {
+		return p.Mount
+	}
+
+	return p.Installations[strings.ToLower(installation)]
+}
+
+// set replaces the policy of the installation with the given ID or
+// organization name, or of the mount for an empty name. A nil policy removes
+// it.
+func (p *policies) set(installation string, policy *Policy) {
+	switch {
+	case installation == "":
+		p.Mount = policy
+	case policy == nil:
+		delete(p.Installations, strings.ToLower(installation))
+	default:
+		p.Installations[strings.ToLower(installation)] = policy
+	}
+}
+
+// effectivePolicy returns the policy that applies to the token request: that
+// of the mount combined with those of its installation, by ID and by
+// organization name. The installation is only resolved if there are
+// installation policies.
+func (b *backend) effectivePolicy(
+	ctx context.Context,
+	s logical.Storage,
+	client func() (*Client, error),
+	tokReq *tokenRequest,
+) (*Policy, error) {
+	ps, err := getPolicies(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ps.Installations) == 0 {
+		return ps.get(""), nil
+	}
+
+	c, err := client()
+	if err != nil {
+		return nil, err
+	}
+
+	installationID, orgName, err := c.installationIdentity(ctx, tokReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return ps.get("").merge(ps.get(strconv.Itoa(installationID))).merge(ps.get(orgName)), nil
+}
+
+// enforcePolicy checks the token request against the policy that applies to
+// it, logging and counting any violations and refusing them with a 403. The
+// client is only obtained if needed to look up the installation.
+func (b *backend) enforcePolicy(
+	ctx context.Context,
+	s logical.Storage,
+	client func() (*Client, error),
+	tokReq *tokenRequest,
+	source string,
+) error {
+	policy, err := b.effectivePolicy(ctx, s, client, tokReq)
+	if err != nil {
+		return err
+	}
+
+	violations := policy.violations(tokReq.Permissions)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	names := slices.Sorted(maps.Keys(violations))
+	reasons := make([]string, 0, len(names))
+
+	for _, name := range names {
+		reasons = append(reasons, violations[name])
+		policyViolations.With(prometheus.Labels{"source": source, "permission": name}).Inc()
+	}
+
+	b.Logger().Warn("token request denied by policy",
+		"source", source,
+		"app", tokReq.App,
+		"org_name", tokReq.OrgName,
+		"installation_id", tokReq.InstallationID,
+		"violations", reasons,
+	)
+
+	return logical.CodedError(http.StatusForbidden,
+		fmt.Sprintf("%s: %s", errPolicyViolation, strings.Join(reasons, "; ")))
+}
@@ -0,0 +1,119 @@
+package github
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	t.Parallel()
+
+	assert.NilError(t, (&Policy{MaxPermissions: map[string]string{"contents": "read"}}).validate())
+	assert.ErrorContains(t,
+		(&Policy{MaxPermissions: map[string]string{"contents": "none"}}).validate(),
+		errInvalidPolicy.Error()+`: contents: unknown access level "none"`)
+}
+
+func TestPolicy_Merge(t *testing.T) {
+	t.Parallel()
+
+	mount := &Policy{
+		MaxPermissions:    map[string]string{"contents": "write", "issues": "read"},
+		DeniedPermissions: []string{"administration"},
+	}
+	installation := &Policy{
+		MaxPermissions:    map[string]string{"contents": "read", "issues": "write", "pages": "write"},
+		DeniedPermissions: []string{"administration", "secrets"},
+	}
+
+	assert.DeepEqual(t, mount.merge(installation), &Policy{
+		MaxPermissions:    map[string]string{"contents": "read", "issues": "read", "pages": "write"},
+		DeniedPermissions: []string{"administration", "secrets"},
+	})
+
+	// Either side may be absent.
+	assert.DeepEqual(t, (*Policy)(nil).merge(mount), mount.merge(nil))
+	assert.Assert(t, (*Policy)(nil).merge(nil).empty())
+}
+
+func TestPolicy_Violations(t *testing.T) {
+	t.Parallel()
+
+	policy := &Policy{
+		MaxPermissions:    map[string]string{"contents": "read"},
+		DeniedPermissions: []string{"administration"},
+	}
+
+	cases := []struct {
+		policy *Policy
+		perms  map[string]string
+		exp    map[string]string
+		name   string
+	}{
+		{
+			name:  "NoPolicy",
+			perms: map[string]string{"administration": "write"},
+		},
+		{
+			name:   "EmptyPolicy",
+			policy: &Policy{},
+		},
+		{
+			name:   "Allowed",
+			policy: policy,
+			perms:  map[string]string{"contents": "read", "issues": "write"},
+			exp:    map[string]string{},
+		},
+		{
+			name:   "Exceeded",
+			policy: policy,
+			perms:  map[string]string{"contents": "write"},
+			exp:    map[string]string{"contents": "contents (requested write, maximum read)"},
+		},
+		{
+			name:   "Denied",
+			policy: policy,
+			perms:  map[string]string{"administration": "read"},
+			exp:    map[string]string{"administration": "administration (denied)"},
+		},
+		{
+			name:   "Unconstrained",
+			policy: policy,
+			exp:    map[string]string{"*": "permissions must be requested explicitly"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.DeepEqual(t, tc.policy.violations(tc.perms), tc.exp)
+		})
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	_, storage := testBackend(t)
+
+	ps, err := getPolicies(ctx, storage)
+	assert.NilError(t, err)
+	assert.Assert(t, ps.get("") == nil)
+
+	ps.set("", &Policy{DeniedPermissions: []string{"administration"}})
+	ps.set("Test-Org", &Policy{DeniedPermissions: []string{"secrets"}})
+	assert.NilError(t, ps.save(ctx, storage))
+
+	ps, err = getPolicies(ctx, storage)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ps.get("").DeniedPermissions, []string{"administration"})
+	assert.DeepEqual(t, ps.get("test-org").DeniedPermissions, []string{"secrets"})
+
+	ps.set("TEST-ORG", nil)
+	assert.Assert(t, ps.get("test-org") == nil)
+	assert.Equal(t, len(ps.Installations), 0)
+}
@@ -0,0 +1,208 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	errUnableToGetRateLimit       = Error("unable to get rate limit")
+	errUnableToDecodeRateLimitRes = Error("unable to decode rate limit response")
+)
+
+const (
+	headerRateLimitLimit    = "X-RateLimit-Limit"
+	headerRateLimitUsed     = "X-RateLimit-Used"
+	headerRateLimitResource = "X-RateLimit-Resource"
+)
+
+// defaultRateLimitResource is the GitHub API resource of responses that do not
+// name theirs.
+const defaultRateLimitResource = "core"
+
+// rateLimitLabels are the labels of the rate limit gauges. The installation ID
+// is empty for the rate limit of the App itself, i.e. requests authenticated
+// with an App JWT.
+var rateLimitLabels = []string{keyAppID, keyInstallationID, "resource"}
+
+// Rate limit gauges record the latest rate limit state seen from GitHub.
+var (
+	rateLimitLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: fmt.Sprintf("%s_ratelimit_limit", prefixMetrics),
+		Help: "Maximum GitHub requests permitted per rate limit window.",
+	}, rateLimitLabels)
+	rateLimitRemaining = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: fmt.Sprintf("%s_ratelimit_remaining", prefixMetrics),
+		Help: "GitHub requests remaining in the current rate limit window.",
+	}, rateLimitLabels)
+	rateLimitUsed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: fmt.Sprintf("%s_ratelimit_used", prefixMetrics),
+		Help: "GitHub requests made in the current rate limit window.",
+	}, rateLimitLabels)
+	rateLimitReset = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: fmt.Sprintf("%s_ratelimit_reset_timestamp_seconds", prefixMetrics),
+		Help: "Time at which the current GitHub rate limit window resets, in seconds since the epoch.",
+	}, rateLimitLabels)
+)
+
+// rateLimit is the state of a GitHub rate limit as last seen.
+type rateLimit struct {
+	Reset     time.Time
+	UpdatedAt time.Time
+	Limit     int
+	Remaining int
+	Used      int
+}
+
+// rateLimitKey identifies a rate limit: the App's own (installation ID 0) or
+// an installation's, for a GitHub API resource such as "core" or "search".
+type rateLimitKey struct {
+	resource       string
+	installationID int
+}
+
+// rateLimits holds the latest rate limit state seen by a client.
+type rateLimits struct {
+	limits map[rateLimitKey]rateLimit
+	mu     sync.RWMutex
+}
+
+// recordRateLimit records the rate limit state in the headers of a response
+// to a request authenticated as the given installation, or as the App itself
+// for installation ID 0.
+func (c *Client) recordRateLimit(installationID int, h http.Header) {
+	limit, err := strconv.Atoi(h.Get(headerRateLimitLimit))
+	if err != nil {
+		return
+	}
+
+	rl := rateLimit{Limit: limit, UpdatedAt: time.Now()}
+	rl.Remaining, _ = strconv.Atoi(h.Get(headerRateLimitRemaining))
+	rl.Used, _ = strconv.Atoi(h.Get(headerRateLimitUsed))
+
+	if reset, err := strconv.ParseInt(h.Get(headerRateLimitReset), 10, 64); err == nil {
+		rl.Reset = time.Unix(reset, 0)
+	}
+
+	resource := h.Get(headerRateLimitResource)
+	if resource == "" {
+		resource = defaultRateLimitResource
+	}
+
+	c.setRateLimit(rateLimitKey{resource: resource, installationID: installationID}, rl)
+}
+
+// setRateLimit stores the rate limit and updates the rate limit gauges.
+func (c *Client) setRateLimit(key rateLimitKey, rl rateLimit) {
+	c.rateLimits.mu.Lock()
+	c.rateLimits.limits[key] = rl
+	c.rateLimits.mu.Unlock()
+
+	var installationID string
+	if key.installationID != 0 {
+		installationID = strconv.Itoa(key.installationID)
+	}
+
+	labels := prometheus.Labels{
+		keyAppID:          strconv.Itoa(c.AppID),
+		keyInstallationID: installationID,
+		"resource":        key.resource,
+	}
+
+	rateLimitLimit.With(labels).Set(float64(rl.Limit))
+	rateLimitRemaining.With(labels).Set(float64(rl.Remaining))
+	rateLimitUsed.With(labels).Set(float64(rl.Used))
+	rateLimitReset.With(labels).Set(float64(rl.Reset.Unix()))
+}
+
+// RateLimits returns the latest rate limit state seen by the client, keyed by
+// resource, for the App itself and for each installation.
+func (c *Client) RateLimits() (map[string]rateLimit, map[int]map[string]rateLimit) {
+	c.rateLimits.mu.RLock()
+	defer c.rateLimits.mu.RUnlock()
+
+	app := make(map[string]rateLimit)
+	installations := make(map[int]map[string]rateLimit)
+
+	for key, rl := range c.rateLimits.limits {
+		if key.installationID == 0 {
+			app[key.resource] = rl
+
+			continue
+		}
+
+		if installations[key.installationID] == nil {
+			installations[key.installationID] = make(map[string]rateLimit)
+		}
+
+		installations[key.installationID][key.resource] = rl
+	}
+
+	return app, installations
+}
+
+// RefreshRateLimits fetches the rate limit state of the App from GitHub. The
+// request does not count against the rate limit. Installation tokens are not
+// kept to do the same for installations, whose rate limit state is instead
+// recorded from the responses to the requests made as them.
+func (c *Client) RefreshRateLimits(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.rateLimitURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errUnableToGetRateLimit, err)
+	}
+
+	req.Header.Set("User-Agent", projectName)
+
+	res, err := c.do(req, c.doApp)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errUnableToGetRateLimit, err)
+	}
+
+	defer res.Body.Close() //nolint:errcheck
+
+	if statusCode(res.StatusCode).Unsuccessful() {
+		var bodyBytes []byte
+
+		if bodyBytes, err = io.ReadAll(res.Body); err != nil {
+			return fmt.Errorf("%s: %w", errUnableToGetRateLimit, err)
+		}
+
+		bodyErr := fmt.Errorf("%s: %s", res.Status, string(bodyBytes))
+
+		return fmt.Errorf("%s: %w", errUnableToGetRateLimit, bodyErr)
+	}
+
+	var rateLimitResult struct {
+		Resources map[string]struct {
+			Limit     int   `json:"limit"`
+			Remaining int   `json:"remaining"`
+			Used      int   `json:"used"`
+			Reset     int64 `json:"reset"`
+		} `json:"resources"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&rateLimitResult); err != nil {
+		return fmt.Errorf("%s: %w", errUnableToDecodeRateLimitRes, err)
+	}
+
+	now := time.Now()
+
+	for resource, r := range rateLimitResult.Resources {
+		c.setRateLimit(rateLimitKey{resource: resource}, rateLimit{
+			Limit:     r.Limit,
+			Remaining: r.Remaining,
+			Used:      r.Used,
+			Reset:     time.Unix(r.Reset, 0),
+			UpdatedAt: now,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	errUnableToGetRepositories       = Error("unable to get installation repositories")
+	errUnableToDecodeRepositoriesRes = Error("unable to decode installation repositories response")
+	errUnknownRepositories           = Error("repositories not accessible to the installation")
+)
+
+// repositoriesPageSize is the number of repositories requested per page, the
+// most GitHub allows.
+const repositoriesPageSize = "100"
+
+// repositoriesTokenPermissions are the permissions of the internal tokens used
+// to list repositories; metadata is granted to every App.
+var repositoriesTokenPermissions = map[string]string{"metadata": "read"}
+
+// Model the parts of a repository that we care about.
+type repository struct {
+	Name       string `json:"name"`
+	FullName   string `json:"full_name"`
+	Visibility string `json:"visibility"`
+	ID         int    `json:"id"`
+	Archived   bool   `json:"archived"`
+}
+
+// InstallationRepositories lists the repositories accessible to the given
+// installation. It mints a short-lived internal token of the installation to
+// do so, which is revoked afterwards if requested.
+func (c *Client) InstallationRepositories(
+	ctx context.Context,
+	installationID int,
+	revoke bool,
+) ([]repository, error) {
+	tokRes, err := c.token(ctx, &tokenRequest{
+		InstallationID: installationID,
+		tokenConstraints: tokenConstraints{
+			Permissions: repositoriesTokenPermissions,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	token, _ := tokRes.Data["token"].(string)
+
+	if revoke {
+		defer func() {
+			if _, err := c.revokeToken(ctx, token, installationID); err != nil {
+				// NOTE: The token expires within the hour regardless.
+				c.logger.Warn("unable to revoke internal token",
+					keyInstallationID, installationID,
+					"err", err,
+				)
+			}
+		}()
+	}
+
+	var allRepositories []repository
+
+	u := c.repositoriesURL.String()
+	for u != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errUnableToGetRepositories, err)
+		}
+
+		req.Header.Set("User-Agent", projectName)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+		// Perform the request as the installation, re-using the shared
+		// transport.
+		res, err := c.do(req, c.revocationClient.Do)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errUnableToGetRepositories, err)
+		}
+
+		defer res.Body.Close() //nolint:errcheck
+
+		c.recordRateLimit(installationID, res.Header)
+
+		if statusCode(res.StatusCode).Unsuccessful() {
+			var bodyBytes []byte
+
+			if bodyBytes, err = io.ReadAll(res.Body); err != nil {
+				return nil, fmt.Errorf("%s: %w", errUnableToGetRepositories, err)
+			}
+
+			bodyErr := fmt.Errorf("%s: %s", res.Status, string(bodyBytes))
+
+			return nil, fmt.Errorf("%s: %w", errUnableToGetRepositories, bodyErr)
+		}
+
+		var reposResult struct {
+			Repositories []repository `json:"repositories"`
+		}
+		if err = json.NewDecoder(res.Body).Decode(&reposResult); err != nil {
+			return nil, fmt.Errorf("%s: %w", errUnableToDecodeRepositoriesRes, err)
+		}
+
+		allRepositories = append(allRepositories, reposResult.Repositories...)
+
+		// Check for pagination
+		u = getNextPageURL(res.Header.Get("Link"))
+	}
+
+	return allRepositories, nil
+}
+
+// unknownRepositoriesError reports the requested repositories that are not
+// accessible to an installation.
+type unknownRepositoriesError struct {
+	Names []string
+	IDs   []int
+}
+
+func (e *unknownRepositoriesError) Error() string {
+	var unknown []string
+
+	if len(e.Names) > 0 {
+		unknown = append(unknown, fmt.Sprintf("%s %s", keyRepos, strings.Join(e.Names, ", ")))
+	}
+
+	if len(e.IDs) > 0 {
+		ids := make([]string, 0, len(e.IDs))
+		for _, id := range e.IDs {
+			ids = append(ids, strconv.Itoa(id))
+		}
+
+		unknown = append(unknown, fmt.Sprintf("%s %s", keyRepoIDs, strings.Join(ids, ", ")))
+	}
+
+	return fmt.Sprintf("%s: %s", errUnknownRepositories, strings.Join(unknown, "; "))
+}
+
+// ResolveRepositories resolves the repository names of the token request to
+// IDs against the repositories accessible to its installation, and checks
+// that its repository IDs are accessible too. On success, the request is left
+// constrained to repository IDs only and the names of the repositories are
+// returned keyed by ID. Otherwise, an *unknownRepositoriesError lists the
+// repositories that are not accessible.
+func (c *Client) ResolveRepositories(ctx context.Context, tokReq *tokenRequest) (map[int]string, error) {
+	if len(tokReq.Repositories) == 0 && len(tokReq.RepositoryIDs) == 0 {
+		return nil, nil
+	}
+
+	if tokReq.InstallationID == 0 {
+		var err error
+		if tokReq.InstallationID, err = c.installationID(ctx, tokReq.OrgName); err != nil {
+			return nil, err
+		}
+	}
+
+	repos, err := c.InstallationRepositories(ctx, tokReq.InstallationID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]repository, len(repos))
+	byID := make(map[int]repository, len(repos))
+
+	for _, repo := range repos {
+		byName[strings.ToLower(repo.Name)] = repo
+		byID[repo.ID] = repo
+	}
+
+	var (
+		unknown  unknownRepositoriesError
+		resolved = make(map[int]string, len(tokReq.Repositories)+len(tokReq.RepositoryIDs))
+	)
+
+	for _, name := range tokReq.Repositories {
+		repo, ok := byName[strings.ToLower(name)]
+		if !ok {
+			unknown.Names = append(unknown.Names, name)
+
+			continue
+		}
+
+		resolved[repo.ID] = repo.Name
+	}
+
+	for _, id := range tokReq.RepositoryIDs {
+		repo, ok := byID[id]
+		if !ok {
+			unknown.IDs = append(unknown.IDs, id)
+
+			continue
+		}
+
+		resolved[repo.ID] = repo.Name
+	}
+
+	if len(unknown.Names) > 0 || len(unknown.IDs) > 0 {
+		return nil, &unknown
+	}
+
+	tokReq.Repositories = nil
+	tokReq.RepositoryIDs = slices.Sorted(maps.Keys(resolved))
+
+	return resolved, nil
+}
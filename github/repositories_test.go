@@ -0,0 +1,113 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestClient_ResolveRepositories(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		tokReq      *tokenRequest
+		expResolved map[int]string
+		expUnknown  *unknownRepositoriesError
+		name        string
+		expIDs      []int
+		expTokens   int
+	}{
+		{
+			name:   "Unconstrained",
+			tokReq: &tokenRequest{InstallationID: testInsID1},
+		},
+		{
+			name: "NamesToIDs",
+			tokReq: &tokenRequest{
+				InstallationID:   testInsID1,
+				tokenConstraints: tokenConstraints{Repositories: []string{"Public", "archived"}},
+			},
+			expIDs:      []int{1, 2},
+			expResolved: map[int]string{1: "public", 2: "archived"},
+			expTokens:   1,
+		},
+		{
+			name: "IDsToNames",
+			tokReq: &tokenRequest{
+				InstallationID:   testInsID1,
+				tokenConstraints: tokenConstraints{RepositoryIDs: []int{2}},
+			},
+			expIDs:      []int{2},
+			expResolved: map[int]string{2: "archived"},
+			expTokens:   1,
+		},
+		{
+			name: "Duplicates",
+			tokReq: &tokenRequest{
+				OrgName: testOrgName1,
+				tokenConstraints: tokenConstraints{
+					Repositories:  []string{"public"},
+					RepositoryIDs: []int{1},
+				},
+			},
+			expIDs:      []int{1},
+			expResolved: map[int]string{1: "public"},
+			expTokens:   1,
+		},
+		{
+			name: "Unknown",
+			tokReq: &tokenRequest{
+				InstallationID: testInsID1,
+				tokenConstraints: tokenConstraints{
+					Repositories:  []string{"public", "pubilc", "private"},
+					RepositoryIDs: []int{2, 3},
+				},
+			},
+			expUnknown: &unknownRepositoriesError{
+				Names: []string{"pubilc", "private"},
+				IDs:   []int{3},
+			},
+			expTokens: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var rec testRepositoriesRecorder
+
+			ts := testRepositoriesServer(t, &rec, http.StatusOK)
+			defer ts.Close()
+
+			client, err := NewClient(&Config{
+				AppID:   testAppID1,
+				PrvKey:  testPrvKeyValid,
+				BaseURL: ts.URL,
+			})
+			assert.NilError(t, err)
+
+			resolved, err := client.ResolveRepositories(context.Background(), tc.tokReq)
+			assert.Equal(t, len(rec.minted()), tc.expTokens)
+			assert.Equal(t, rec.revocations.Load(), int32(tc.expTokens))
+
+			if tc.expUnknown != nil {
+				var unknown *unknownRepositoriesError
+				assert.Assert(t, errors.As(err, &unknown))
+				assert.DeepEqual(t, unknown, tc.expUnknown)
+				assert.Error(t, err, errUnknownRepositories.Error()+
+					": repositories pubilc, private; repository_ids 3")
+
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.DeepEqual(t, resolved, tc.expResolved)
+			assert.DeepEqual(t, tc.tokReq.RepositoryIDs, tc.expIDs)
+			assert.Assert(t, tc.tokReq.Repositories == nil)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package github
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultMaxRetries is how many times a failed request to GitHub is retried
+// when not configured.
+const defaultMaxRetries = 3
+
+// retryBaseDelay and retryMaxDelay bound the exponential backoff between
+// retries that GitHub gave no hint for.
+//
+// NOTE: Variables only so that tests need not wait.
+var (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 10 * time.Second
+)
+
+// maxRetryWait is the longest a retry waits for, even when GitHub asks to wait
+// longer, such as until a primary rate limit resets.
+const maxRetryWait = time.Minute
+
+const (
+	headerRetryAfter         = "Retry-After"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// maxRetries returns the effective number of retries of failed requests.
+func (c *Config) maxRetries() int {
+	if c.MaxRetries != nil {
+		return *c.MaxRetries
+	}
+
+	return defaultMaxRetries
+}
+
+// do sends the request with send, retrying transient failures: transport
+// errors, server errors and primary or secondary rate limiting. Retries honour
+// the Retry-After and X-RateLimit-* headers of GitHub where present, otherwise
+// backing off exponentially with jitter, and are bounded by the request
+// context.
+func (c *Client) do(
+	req *http.Request,
+	send func(*http.Request) (*http.Response, error),
+) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			// Rewind the request body, if any, for the next attempt.
+			retry := req.Clone(ctx)
+
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+
+				retry.Body = body
+			}
+
+			req = retry
+		}
+
+		res, err := send(req)
+
+		reason, retryable := retryReason(ctx, res, err)
+		if !retryable || attempt >= c.maxRetries() || (req.Body != nil && req.GetBody == nil) {
+			return res, err
+		}
+
+		delay := retryDelay(attempt, res)
+		if deadline, ok := ctx.Deadline(); delay > maxRetryWait || ok && time.Until(deadline) < delay {
+			return res, err
+		}
+
+		c.logger.Warn("retrying GitHub request",
+			"method", req.Method,
+			"url", req.URL.Redacted(),
+			"reason", reason,
+			"attempt", attempt+1,
+			"delay", delay.String(),
+		)
+		retries.With(prometheus.Labels{"reason": reason}).Inc()
+
+		if res != nil {
+			io.Copy(io.Discard, res.Body) //nolint:errcheck,gosec
+			res.Body.Close()              //nolint:errcheck,gosec
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryReason reports whether the outcome of a request is worth retrying, and
+// why.
+func retryReason(ctx context.Context, res *http.Response, err error) (string, bool) {
+	if err != nil {
+		// Certificates will not verify any better the next time around, and
+		// should the caller have given up, so should we.
+		var certErr *tls.CertificateVerificationError
+
+		return "error", ctx.Err() == nil && !errors.As(err, &certErr)
+	}
+
+	switch {
+	case res.StatusCode >= http.StatusInternalServerError,
+		res.StatusCode == http.StatusTooManyRequests:
+		return strconv.Itoa(res.StatusCode), true
+	case res.StatusCode == http.StatusForbidden:
+		// GitHub signals rate limiting with a 403 and these headers; other
+		// 403s are permission errors and not worth retrying.
+		return strconv.Itoa(res.StatusCode),
+			res.Header.Get(headerRetryAfter) != "" || res.Header.Get(headerRateLimitRemaining) == "0"
+	default:
+		return "", false
+	}
+}
+
+// retryDelay returns how long to wait before retrying the given attempt. The
+// wait GitHub asks for is preferred over exponential backoff with jitter.
+func retryDelay(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if delay, ok := retryAfter(res.Header, time.Now()); ok {
+			return delay
+		}
+	}
+
+	delay := retryMaxDelay
+	if attempt < 32 && retryBaseDelay<<attempt < retryMaxDelay {
+		delay = retryBaseDelay << attempt
+	}
+
+	// Equal jitter: wait at least half the backoff to keep making progress.
+	return delay/2 + rand.N(delay/2+1) //nolint:gosec // Jitter need not be secure.
+}
+
+// retryAfter returns the wait GitHub asks for in the given response headers,
+// if any.
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	if v := h.Get(headerRetryAfter); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+
+		if at, err := http.ParseTime(v); err == nil {
+			return max(at.Sub(now), 0), true
+		}
+	}
+
+	if h.Get(headerRateLimitRemaining) == "0" {
+		if reset, err := strconv.ParseInt(h.Get(headerRateLimitReset), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(now), 0), true
+		}
+	}
+
+	return 0, false
+}
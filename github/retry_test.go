@@ -0,0 +1,299 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/assert"
+)
+
+func init() {
+	// Keep retries of failed requests from slowing tests down.
+	retryBaseDelay, retryMaxDelay = time.Millisecond, 2*time.Millisecond
+}
+
+// testCanonicalHeader returns the header with canonical keys, as received.
+func testCanonicalHeader(h http.Header) http.Header {
+	canonical := make(http.Header, len(h))
+	for k, v := range h {
+		canonical[http.CanonicalHeaderKey(k)] = v
+	}
+
+	return canonical
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	cases := []struct {
+		header http.Header
+		name   string
+		exp    time.Duration
+		ok     bool
+	}{
+		{
+			name:   "None",
+			header: http.Header{},
+		},
+		{
+			name:   "RetryAfterSeconds",
+			header: http.Header{headerRetryAfter: {"30"}},
+			exp:    30 * time.Second,
+			ok:     true,
+		},
+		{
+			name:   "RetryAfterDate",
+			header: http.Header{headerRetryAfter: {now.Add(time.Minute).UTC().Format(http.TimeFormat)}},
+			exp:    time.Minute,
+			ok:     true,
+		},
+		{
+			name:   "RetryAfterInvalid",
+			header: http.Header{headerRetryAfter: {"soon"}},
+		},
+		{
+			name: "RateLimitReset",
+			header: http.Header{
+				headerRateLimitRemaining: {"0"},
+				headerRateLimitReset:     {strconv.FormatInt(now.Add(time.Minute).Unix(), 10)},
+			},
+			exp: time.Minute,
+			ok:  true,
+		},
+		{
+			name: "RateLimitResetPast",
+			header: http.Header{
+				headerRateLimitRemaining: {"0"},
+				headerRateLimitReset:     {strconv.FormatInt(now.Add(-time.Minute).Unix(), 10)},
+			},
+			ok: true,
+		},
+		{
+			name: "RateLimitRemaining",
+			header: http.Header{
+				headerRateLimitRemaining: {"10"},
+				headerRateLimitReset:     {strconv.FormatInt(now.Add(time.Minute).Unix(), 10)},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			delay, ok := retryAfter(testCanonicalHeader(tc.header), now)
+			assert.Equal(t, ok, tc.ok)
+			// HTTP dates and reset times have a resolution of a second.
+			assert.Assert(t, delay > tc.exp-time.Second && delay <= tc.exp, delay)
+		})
+	}
+}
+
+func TestRetryReason(t *testing.T) {
+	t.Parallel()
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := []struct {
+		ctx    context.Context
+		err    error
+		header http.Header
+		name   string
+		reason string
+		status int
+		retry  bool
+	}{
+		{name: "OK", status: http.StatusOK},
+		{name: "NotFound", status: http.StatusNotFound},
+		{name: "BadGateway", status: http.StatusBadGateway, reason: "502", retry: true},
+		{name: "TooManyRequests", status: http.StatusTooManyRequests, reason: "429", retry: true},
+		{name: "Forbidden", status: http.StatusForbidden, reason: "403"},
+		{
+			name:   "SecondaryRateLimit",
+			status: http.StatusForbidden,
+			header: http.Header{headerRetryAfter: {"60"}},
+			reason: "403",
+			retry:  true,
+		},
+		{
+			name:   "PrimaryRateLimit",
+			status: http.StatusForbidden,
+			header: http.Header{headerRateLimitRemaining: {"0"}},
+			reason: "403",
+			retry:  true,
+		},
+		{name: "Error", err: errors.New("connection reset"), reason: "error", retry: true},
+		{name: "Cancelled", ctx: cancelled, err: context.Canceled, reason: "error"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := tc.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			var res *http.Response
+			if tc.err == nil {
+				res = &http.Response{StatusCode: tc.status, Header: testCanonicalHeader(tc.header)}
+			}
+
+			reason, retry := retryReason(ctx, res, tc.err)
+			assert.Equal(t, reason, tc.reason)
+			assert.Equal(t, retry, tc.retry)
+		})
+	}
+}
+
+func TestClient_TokenRetries(t *testing.T) {
+	t.Parallel()
+
+	zero := 0
+
+	cases := []struct {
+		header     http.Header
+		maxRetries *int
+		timeout    time.Duration
+		name       string
+		failures   int32
+		status     int
+		expCalls   int32
+		err        bool
+	}{
+		{
+			name:     "RecoversFromServerErrors",
+			status:   http.StatusBadGateway,
+			failures: 2,
+			expCalls: 3,
+		},
+		{
+			name:     "RecoversFromSecondaryRateLimit",
+			status:   http.StatusForbidden,
+			header:   http.Header{headerRetryAfter: {"0"}},
+			failures: 1,
+			expCalls: 2,
+		},
+		{
+			name:     "GivesUpAfterMaxRetries",
+			status:   http.StatusServiceUnavailable,
+			failures: 10,
+			expCalls: defaultMaxRetries + 1,
+			err:      true,
+		},
+		{
+			name:       "RetriesDisabled",
+			status:     http.StatusServiceUnavailable,
+			maxRetries: &zero,
+			failures:   10,
+			expCalls:   1,
+			err:        true,
+		},
+		{
+			name:     "PermissionErrorNotRetried",
+			status:   http.StatusForbidden,
+			failures: 10,
+			expCalls: 1,
+			err:      true,
+		},
+		{
+			name:     "WaitBeyondMaxRetryWait",
+			status:   http.StatusTooManyRequests,
+			header:   http.Header{headerRetryAfter: {strconv.Itoa(int(2 * maxRetryWait.Seconds()))}},
+			failures: 10,
+			expCalls: 1,
+			err:      true,
+		},
+		{
+			name:     "WaitBeyondContextDeadline",
+			status:   http.StatusTooManyRequests,
+			header:   http.Header{headerRetryAfter: {"30"}},
+			timeout:  time.Second,
+			failures: 10,
+			expCalls: 1,
+			err:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+
+			ts := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					// Each attempt carries the full request body.
+					body, err := io.ReadAll(r.Body)
+					assert.NilError(t, err)
+					assert.Assert(t, len(body) > 0)
+
+					if calls.Add(1) <= tc.failures {
+						for k, v := range testCanonicalHeader(tc.header) {
+							w.Header()[k] = v
+						}
+
+						w.WriteHeader(tc.status)
+
+						return
+					}
+
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte(`{"token":"` + testToken + `"}`))
+				}),
+			)
+			defer ts.Close()
+
+			client, err := NewClient(&Config{
+				AppID:      testAppID1,
+				PrvKey:     testPrvKeyValid,
+				BaseURL:    ts.URL,
+				MaxRetries: tc.maxRetries,
+			})
+			assert.NilError(t, err)
+
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			before := testutil.ToFloat64(retries.WithLabelValues(strconv.Itoa(tc.status)))
+
+			res, err := client.Token(ctx, &tokenRequest{
+				InstallationID: testInsID1,
+				tokenConstraints: tokenConstraints{
+					Permissions: testPerms,
+				},
+			})
+			assert.Equal(t, calls.Load(), tc.expCalls)
+
+			if tc.expCalls > 1 {
+				after := testutil.ToFloat64(retries.WithLabelValues(strconv.Itoa(tc.status)))
+				assert.Assert(t, after-before >= float64(tc.expCalls-1))
+			}
+
+			if tc.err {
+				assert.ErrorContains(t, err, strconv.Itoa(tc.status))
+
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, res.Data["token"], testToken)
+		})
+	}
+}
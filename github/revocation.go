@@ -15,7 +15,28 @@ import (
 func (b *backend) Revoke(
 	ctx context.Context, req *logical.Request, d *framework.FieldData,
 ) (resp *logical.Response, retErr error) {
-	client, done, err := b.Client(ctx, req.Storage)
+	// Tokens are revoked against the app that created them. Leases created
+	// before named apps were supported belong to the default app.
+	var (
+		app            string
+		installationID int
+	)
+
+	if req.Secret != nil {
+		// Cached tokens are shared by other leases, so are left to expire.
+		if cached, _ := req.Secret.InternalData[secretCached].(bool); cached {
+			return nil, nil
+		}
+
+		app, _ = req.Secret.InternalData[keyApp].(string)
+
+		// Only recorded to attribute the rate limit of the installation.
+		if id, ok := req.Secret.InternalData[keyInstallationID].(string); ok {
+			installationID, _ = strconv.Atoi(id)
+		}
+	}
+
+	client, done, err := b.Client(ctx, req.Storage, app)
 	if err != nil {
 		return nil, err
 	}
@@ -46,5 +67,5 @@ func (b *backend) Revoke(
 		}).Observe(duration.Seconds())
 	}(time.Now())
 
-	return client.RevokeToken(ctx, token)
+	return client.revokeToken(ctx, token, installationID)
 }
@@ -0,0 +1,117 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// roleTTL models the TTL and maximum TTL of the leases granted from a role.
+type roleTTL struct {
+	TTL    time.Duration `json:",omitempty"`
+	MaxTTL time.Duration `json:",omitempty"`
+}
+
+// update sets the TTLs given in the field data, and validates them as those of
+// a token request.
+func (r *roleTTL) update(d *framework.FieldData) error {
+	if ttl, ok := d.GetOk(keyTTL); ok {
+		r.TTL = time.Duration(ttl.(int)) * time.Second
+	}
+
+	if maxTTL, ok := d.GetOk(keyMaxTTL); ok {
+		r.MaxTTL = time.Duration(maxTTL.(int)) * time.Second
+	}
+
+	return (&tokenRequest{TTL: r.TTL, MaxTTL: r.MaxTTL}).validateTTL()
+}
+
+// leaseTTLs returns the effective TTL and maximum TTL of leases given those
+// configured, which default to and are capped by those of the mount.
+func (b *backend) leaseTTLs(ttl, maxTTL time.Duration) (time.Duration, time.Duration) {
+	if mountMaxTTL := b.System().MaxLeaseTTL(); maxTTL == 0 || (mountMaxTTL > 0 && mountMaxTTL < maxTTL) {
+		maxTTL = mountMaxTTL
+	}
+
+	if ttl == 0 {
+		ttl = b.System().DefaultLeaseTTL()
+	}
+
+	if maxTTL > 0 && ttl > maxTTL {
+		ttl = maxTTL
+	}
+
+	return ttl, maxTTL
+}
+
+// putRole stores the role with the name under the storage prefix of its kind.
+func putRole(ctx context.Context, s logical.Storage, prefix, name string, role any) error {
+	entry, err := logical.StorageEntryJSON(fmt.Sprintf("%s/%s", prefix, name), role)
+	if err != nil {
+		return err
+	}
+
+	return s.Put(ctx, entry)
+}
+
+// getRole returns the role with the name stored under the storage prefix of
+// its kind, or nil if there is none.
+func getRole[R any](ctx context.Context, s logical.Storage, prefix, name string) (*R, error) {
+	entry, err := s.Get(ctx, fmt.Sprintf("%s/%s", prefix, name))
+	if err != nil {
+		return nil, err
+	}
+
+	if entry == nil {
+		return nil, nil
+	}
+
+	role := new(R)
+
+	if err = entry.DecodeJSON(role); err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// pathRoleDelete returns the handler of DELETE on the roles stored under the
+// prefix, holding the lock of their kind.
+func pathRoleDelete(prefix string, lock *sync.Mutex) framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return nil, req.Storage.Delete(ctx, fmt.Sprintf("%s/%s", prefix, d.Get("name").(string)))
+	}
+}
+
+// pathRoleList returns the handler of LIST on the roles stored under the
+// prefix.
+func pathRoleList(prefix string) framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+		roles, err := req.Storage.List(ctx, prefix+"/")
+		if err != nil {
+			return nil, err
+		}
+
+		return logical.ListResponse(roles), nil
+	}
+}
+
+// pathRoleExistenceCheck returns the existence check of the roles stored
+// under the prefix.
+func pathRoleExistenceCheck[R any](prefix string) framework.ExistenceFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (bool, error) {
+		role, err := getRole[R](ctx, req.Storage, prefix, d.Get("name").(string))
+		if err != nil {
+			return false, err
+		}
+
+		return role != nil, nil
+	}
+}
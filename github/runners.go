@@ -0,0 +1,183 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	errUnableToCreateRunnerToken     = Error("unable to create runner token")
+	errUnableToCreateRunnerJITConfig = Error("unable to create runner JIT configuration")
+	errUnableToGetRunnerGroups       = Error("unable to get runner groups")
+	errRunnerGroupNotFound           = Error("runner group not found")
+)
+
+const (
+	runnerTokenRegistration = "registration"
+	runnerTokenRemoval      = "removal"
+	runnerJITConfig         = "jitconfig"
+)
+
+// defaultRunnerGroupID is the ID of the Default runner group of every
+// organization, which repository runners are registered in.
+const defaultRunnerGroupID = 1
+
+// runnerGroupsPageSize is the number of runner groups listed per page.
+const runnerGroupsPageSize = 100
+
+// runnerTokenDuration records useful metric data about runner token requests.
+var runnerTokenDuration = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+	Name:       fmt.Sprintf("%s_runner_request_duration_seconds", prefixMetrics),
+	Help:       "Total duration of Vault GitHub runner token requests in seconds.",
+	Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+}, []string{"success", "kind", keyOrgName, keyRepository})
+
+// Model a runner registration or removal token.
+type runnerToken struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	Token     string    `json:"token"`
+}
+
+// Model the parts of a runner group that we care about.
+type runnerGroup struct {
+	Name string `json:"name"`
+	ID   int64  `json:"id"`
+}
+
+// Model a just-in-time runner configuration request.
+type runnerJITConfigRequest struct {
+	Name          string   `json:"name"`
+	WorkFolder    string   `json:"work_folder,omitempty"`
+	Labels        []string `json:"labels"`
+	RunnerGroupID int64    `json:"runner_group_id"`
+}
+
+// Model a just-in-time runner configuration.
+type runnerJITConfigResponse struct {
+	EncodedJITConfig string `json:"encoded_jit_config"`
+	Runner           struct {
+		Name string `json:"name"`
+		ID   int64  `json:"id"`
+	} `json:"runner"`
+}
+
+// runnersTokenRequest returns the token request of the internal tokens used
+// to create runner tokens for the repository of an installation, or its
+// organization if no repository is given.
+func runnersTokenRequest(installationID int, repo string) *tokenRequest {
+	if repo == "" {
+		return &tokenRequest{
+			InstallationID: installationID,
+			tokenConstraints: tokenConstraints{
+				Permissions: map[string]string{"organization_self_hosted_runners": "write"},
+			},
+		}
+	}
+
+	return &tokenRequest{
+		InstallationID: installationID,
+		tokenConstraints: tokenConstraints{
+			Repositories: []string{repo},
+			Permissions:  map[string]string{"administration": "write"},
+		},
+	}
+}
+
+// RunnerToken creates a self-hosted runner token of the given kind
+// (registration or removal) for the repository of the owner, or the owner
+// organization if no repository is given, as the installation.
+func (c *Client) RunnerToken(
+	ctx context.Context,
+	installationID int,
+	owner, repo, kind string,
+) (*runnerToken, error) {
+	path := fmt.Sprintf("orgs/%s/actions/runners/%s-token", owner, kind)
+	if repo != "" {
+		path = fmt.Sprintf("repos/%s/%s/actions/runners/%s-token", owner, repo, kind)
+	}
+
+	var token runnerToken
+
+	if err := c.installationRequest(ctx, runnersTokenRequest(installationID, repo),
+		http.MethodPost, path, nil, &token,
+	); err != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToCreateRunnerToken, err)
+	}
+
+	return &token, nil
+}
+
+// RunnerJITConfig creates a just-in-time configuration of a runner of the
+// repository of the owner, or of the named runner group of the owner
+// organization if no repository is given, as the installation. Runners
+// configured with it are registered in the runner group for a single job.
+func (c *Client) RunnerJITConfig(
+	ctx context.Context,
+	installationID int,
+	owner, repo, group string,
+	jitReq *runnerJITConfigRequest,
+) (*runnerJITConfigResponse, error) {
+	path := fmt.Sprintf("orgs/%s/actions/runners/generate-jitconfig", owner)
+	if repo != "" {
+		path = fmt.Sprintf("repos/%s/%s/actions/runners/generate-jitconfig", owner, repo)
+	}
+
+	var config runnerJITConfigResponse
+
+	if err := c.withInstallation(ctx, runnersTokenRequest(installationID, repo),
+		func(s *installationSession) error {
+			withGroup := *jitReq
+			withGroup.RunnerGroupID = defaultRunnerGroupID
+
+			if repo == "" && group != "" {
+				id, err := s.runnerGroupID(ctx, owner, group)
+				if err != nil {
+					return err
+				}
+
+				withGroup.RunnerGroupID = id
+			}
+
+			return s.request(ctx, http.MethodPost, path, &withGroup, &config)
+		},
+	); err != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToCreateRunnerJITConfig, err)
+	}
+
+	return &config, nil
+}
+
+// runnerGroupID returns the ID of the named runner group of the organization.
+func (s *installationSession) runnerGroupID(ctx context.Context, org, name string) (int64, error) {
+	for page := 1; ; page++ {
+		var groups struct {
+			RunnerGroups []runnerGroup `json:"runner_groups"`
+		}
+
+		path := fmt.Sprintf("orgs/%s/actions/runner-groups?%s", org, url.Values{
+			"per_page": {strconv.Itoa(runnerGroupsPageSize)},
+			"page":     {strconv.Itoa(page)},
+		}.Encode())
+
+		if err := s.request(ctx, http.MethodGet, path, nil, &groups); err != nil {
+			return 0, fmt.Errorf("%s: %w", errUnableToGetRunnerGroups, err)
+		}
+
+		for _, group := range groups.RunnerGroups {
+			if strings.EqualFold(group.Name, name) {
+				return group.ID, nil
+			}
+		}
+
+		if len(groups.RunnerGroups) < runnerGroupsPageSize {
+			return 0, fmt.Errorf("%w: %s", errRunnerGroupNotFound, name)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package github
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestRunnersTokenRequest(t *testing.T) {
+	t.Parallel()
+
+	assert.DeepEqual(t, runnersTokenRequest(testInsID1, "").tokenConstraints, tokenConstraints{
+		Permissions: map[string]string{"organization_self_hosted_runners": "write"},
+	})
+	assert.DeepEqual(t, runnersTokenRequest(testInsID1, "website").tokenConstraints, tokenConstraints{
+		Repositories: []string{"website"},
+		Permissions:  map[string]string{"administration": "write"},
+	})
+}
+
+func TestRunnerRole_RunnerGroup(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		requested string
+		exp       string
+		err       string
+		groups    []string
+	}{
+		{
+			name: "NoGroups",
+		},
+		{
+			name:      "NoGroupsRequested",
+			requested: "gpu",
+			err:       `runner_group "gpu" is not allowed by the role`,
+		},
+		{
+			name:   "Default",
+			groups: []string{"ephemeral", "gpu"},
+			exp:    "ephemeral",
+		},
+		{
+			name:      "Requested",
+			groups:    []string{"ephemeral", "gpu"},
+			requested: "GPU",
+			exp:       "GPU",
+		},
+		{
+			name:      "NotAllowed",
+			groups:    []string{"ephemeral", "gpu"},
+			requested: "default",
+			err:       `runner_group "default" is not allowed by the role, allowed: ephemeral, gpu`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			group, err := (&runnerRole{RunnerGroups: tc.groups}).runnerGroup(tc.requested)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, group, tc.exp)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/hashicorp/vault/api"
+)
+
+// defaultTransitMount is the mount path of the Transit secrets engine used
+// when none is configured.
+const defaultTransitMount = "transit"
+
+const (
+	errUnableToSignJWT         = Error("unable to sign JWT")
+	errUnableToCreateVaultAPI  = Error("unable to create Vault API client")
+	errTransitSignatureMissing = Error("transit sign response did not contain a signature")
+	errTransitSignatureFormat  = Error("transit signature was not in the expected format")
+)
+
+// Signer produces RSASSA-PKCS1-v1_5 SHA-256 signatures for the JWTs that
+// authenticate requests as the GitHub App. Implementations are free to keep
+// the private key out of the plugin entirely, for example in a KMS.
+type Signer interface {
+	// Sign returns the signature of the given message, which is not hashed.
+	// The context is that of the request being authenticated.
+	Sign(ctx context.Context, message []byte) ([]byte, error)
+}
+
+// newSigner returns the Signer for the given config: a Transit signer when a
+// Transit key is configured, otherwise one backed by the private key.
+func newSigner(config *Config, timeout time.Duration) (Signer, error) {
+	if config.TransitKey != "" {
+		return newTransitSigner(config, timeout)
+	}
+
+	return newRSASigner(config.PrvKey)
+}
+
+// rsaSigner is a Signer backed by an in-memory RSA private key.
+type rsaSigner struct {
+	key *rsa.PrivateKey
+}
+
+// newRSASigner returns a Signer for the given PEM encoded RSA private key.
+func newRSASigner(prvKey string) (*rsaSigner, error) {
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(prvKey))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToParsePrvKey, err)
+	}
+
+	return &rsaSigner{key: key}, nil
+}
+
+// Sign implements Signer.
+func (s *rsaSigner) Sign(_ context.Context, message []byte) ([]byte, error) {
+	digest := sha256.Sum256(message)
+
+	return rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+}
+
+// transitSigner is a Signer backed by a Vault Transit secrets engine key. The
+// private key never leaves Vault.
+type transitSigner struct {
+	client *api.Client
+	path   string
+}
+
+// newTransitSigner returns a Signer for the Transit key in the given config.
+func newTransitSigner(config *Config, timeout time.Duration) (*transitSigner, error) {
+	apiConfig := api.DefaultConfig()
+	if apiConfig.Error != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToCreateVaultAPI, apiConfig.Error)
+	}
+
+	if config.VaultAddr != "" {
+		apiConfig.Address = config.VaultAddr
+	}
+
+	apiConfig.Timeout = timeout
+
+	client, err := api.NewClient(apiConfig)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToCreateVaultAPI, err)
+	}
+
+	client.SetToken(config.VaultToken)
+
+	if config.VaultNamespace != "" {
+		client.SetNamespace(config.VaultNamespace)
+	}
+
+	mount := config.TransitMount
+	if mount == "" {
+		mount = defaultTransitMount
+	}
+
+	return &transitSigner{
+		client: client,
+		path: fmt.Sprintf("%s/sign/%s/sha2-256",
+			strings.Trim(mount, "/"), config.TransitKey),
+	}, nil
+}
+
+// Sign implements Signer.
+func (s *transitSigner) Sign(ctx context.Context, message []byte) ([]byte, error) {
+	secret, err := s.client.Logical().WriteWithContext(ctx, s.path, map[string]any{
+		"input":               base64.StdEncoding.EncodeToString(message),
+		"signature_algorithm": "pkcs1v15",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if secret == nil || secret.Data == nil {
+		return nil, errTransitSignatureMissing
+	}
+
+	signature, ok := secret.Data["signature"].(string)
+	if !ok {
+		return nil, errTransitSignatureMissing
+	}
+
+	// Transit signatures are formatted as "vault:v<key version>:<base64>".
+	parts := strings.SplitN(signature, ":", 3)
+	if len(parts) != 3 || parts[0] != "vault" {
+		return nil, errTransitSignatureFormat
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errTransitSignatureFormat, err)
+	}
+
+	return sig, nil
+}
+
+// appsTransport is an http.RoundTripper that authenticates requests as the
+// GitHub App with a JWT signed by its Signer, in the context of each request.
+type appsTransport struct {
+	tr     http.RoundTripper
+	signer Signer
+	appID  int64
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *appsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// GitHub rejects timestamps that are not integers and allows for clock
+	// drift when the JWT is issued in the past.
+	iss := time.Now().Add(-30 * time.Second).Truncate(time.Second)
+
+	signed, err := signJWT(req.Context(), t.signer, &jwt.RegisteredClaims{
+		IssuedAt:  jwt.NewNumericDate(iss),
+		ExpiresAt: jwt.NewNumericDate(iss.Add(2 * time.Minute)),
+		Issuer:    strconv.FormatInt(t.appID, 10),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// RoundTrippers must not modify the request.
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+signed)
+	req.Header.Add("Accept", "application/vnd.github.v3+json")
+
+	return t.tr.RoundTrip(req)
+}
+
+// signJWT signs the given claims as an RS256 JWT with the given Signer.
+func signJWT(ctx context.Context, signer Signer, claims jwt.Claims) (string, error) {
+	signingString, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SigningString()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", errUnableToSignJWT, err)
+	}
+
+	sig, err := signer.Sign(ctx, []byte(signingString))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", errUnableToSignJWT, err)
+	}
+
+	return signingString + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
@@ -0,0 +1,194 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// testSigner is an in-process stand-in for an external Signer such as a KMS.
+type testSigner struct {
+	ctx   context.Context
+	key   *rsa.PrivateKey
+	calls int
+}
+
+// testSignerCtxKey is the key of a context value seen by testSigner.
+type testSignerCtxKey struct{}
+
+func (s *testSigner) Sign(ctx context.Context, message []byte) ([]byte, error) {
+	s.ctx = ctx
+	s.calls++
+
+	return (&rsaSigner{key: s.key}).Sign(ctx, message)
+}
+
+// testTransitServer stubs the Vault Transit sign endpoint of the given key,
+// responding with the given signature format.
+func testTransitServer(t *testing.T, transitKey string, key *rsa.PrivateKey, format string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			t.Helper()
+
+			if r.Method != http.MethodPut && r.Method != http.MethodPost ||
+				r.URL.Path != "/v1/transit/sign/"+transitKey+"/sha2-256" ||
+				r.Header.Get("X-Vault-Token") != testToken {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"errors":["permission denied"]}`))
+
+				return
+			}
+
+			var req struct {
+				Input              string `json:"input"`
+				SignatureAlgorithm string `json:"signature_algorithm"`
+			}
+			assert.NilError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, req.SignatureAlgorithm, "pkcs1v15")
+
+			input, err := base64.StdEncoding.DecodeString(req.Input)
+			assert.NilError(t, err)
+
+			sig, err := (&rsaSigner{key: key}).Sign(r.Context(), input)
+			assert.NilError(t, err)
+
+			body, _ := json.Marshal(map[string]any{
+				"data": map[string]any{
+					"signature": format + base64.StdEncoding.EncodeToString(sig),
+				},
+			})
+			w.Write(body)
+		}),
+	)
+}
+
+func TestRSASigner(t *testing.T) {
+	t.Parallel()
+
+	_, key := testGeneratePrvKey(t, 2048)
+
+	sig, err := (&rsaSigner{key: key}).Sign(context.Background(), []byte("message"))
+	assert.NilError(t, err)
+
+	digest := sha256.Sum256([]byte("message"))
+	assert.NilError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig))
+
+	_, err = newRSASigner("not a private key")
+	assert.ErrorContains(t, err, errUnableToParsePrvKey.Error())
+}
+
+func TestClient_TokenWithSigner(t *testing.T) {
+	t.Parallel()
+
+	_, key := testGeneratePrvKey(t, 2048)
+
+	ts := testAppServer(t, &key.PublicKey)
+	defer ts.Close()
+
+	signer := &testSigner{key: key}
+
+	// No private key is configured; the signer holds it.
+	client, err := NewClient(&Config{
+		AppID:   testAppID1,
+		BaseURL: ts.URL,
+	}, WithSigner(signer))
+	assert.NilError(t, err)
+
+	ctx := context.WithValue(context.Background(), testSignerCtxKey{}, "request")
+
+	res, err := client.Token(ctx, &tokenRequest{
+		InstallationID: testInsID1,
+		tokenConstraints: tokenConstraints{
+			Permissions: testPerms,
+		},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, res.Data["token"], testToken)
+	assert.Assert(t, signer.calls > 0)
+
+	// JWTs are signed in the context of the request they authenticate.
+	assert.Equal(t, signer.ctx.Value(testSignerCtxKey{}), "request")
+}
+
+func TestClient_TokenWithTransitSigner(t *testing.T) {
+	t.Parallel()
+
+	_, key := testGeneratePrvKey(t, 2048)
+
+	cases := []struct {
+		err    error
+		name   string
+		format string
+		token  string
+	}{
+		{
+			name:   "Signed",
+			format: "vault:v1:",
+			token:  testToken,
+		},
+		{
+			name:   "PermissionDenied",
+			format: "vault:v1:",
+			token:  "wrong",
+			err:    errUnableToSignJWT,
+		},
+		{
+			name:   "UnexpectedFormat",
+			format: "",
+			token:  testToken,
+			err:    errTransitSignatureFormat,
+		},
+		{
+			name:   "BadEncoding",
+			format: "vault:v1:!",
+			token:  testToken,
+			err:    errTransitSignatureFormat,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			vault := testTransitServer(t, "github", key, tc.format)
+			defer vault.Close()
+
+			ts := testAppServer(t, &key.PublicKey)
+			defer ts.Close()
+
+			client, err := NewClient(&Config{
+				AppID:      testAppID1,
+				BaseURL:    ts.URL,
+				TransitKey: "github",
+				VaultAddr:  vault.URL,
+				VaultToken: tc.token,
+			})
+			assert.NilError(t, err)
+
+			res, err := client.Token(context.Background(), &tokenRequest{
+				InstallationID: testInsID1,
+				tokenConstraints: tokenConstraints{
+					Permissions: testPerms,
+				},
+			})
+			if tc.err != nil {
+				assert.ErrorContains(t, err, tc.err.Error())
+
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, res.Data["token"], testToken)
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package github
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	errSyncNameEmpty               = Error("sync entry name empty")
+	errUnableToGetSyncEntry        = Error("unable to get sync entry")
+	errSyncValueFromEntryNotFound  = Error("referenced sync entry does not exist")
+	errUnableToSyncSecret          = Error("unable to sync actions secret")
+	errUnableToUnsyncSecret        = Error("unable to delete synced actions secret")
+	errSyncEntryIsReferenced       = Error("sync entry is referenced by other entries")
+	errSyncValueFromEntryReference = Error("referenced sync entry must have its own value")
+)
+
+// Triggers of Actions secret syncs.
+const (
+	syncTriggerWrite     = "write"
+	syncTriggerReconcile = "reconcile"
+)
+
+// syncReconcileInterval is the minimum interval between reconciliations of a
+// sync entry whose last sync succeeded. Failed syncs are retried on every run
+// of the periodic function.
+const syncReconcileInterval = 5 * time.Minute
+
+// syncPushes counts the Actions secrets pushed to GitHub by sync entries.
+var syncPushes = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: fmt.Sprintf("%s_sync_pushes_total", prefixMetrics),
+	Help: "Total Actions secrets pushed to GitHub by sync entries, by trigger.",
+}, []string{"success", "trigger"})
+
+// syncEntry models a stored Vault managed value that is synced to a GitHub
+// Actions secret, together with the state of its last sync.
+type syncEntry struct {
+	Name string
+	actionsSecretTarget
+	Value                 string `json:",omitempty"`
+	ValueFromEntry        string `json:",omitempty"`
+	Visibility            string `json:",omitempty"`
+	SelectedRepositoryIDs []int  `json:",omitempty"`
+	App                   string `json:",omitempty"`
+	InstallationID        int    `json:",omitempty"`
+
+	// ValueHash is the SHA-256 of the value last synced, and UpdatedAt the
+	// time GitHub last recorded an update of the secret, as of that sync. Both
+	// are used to detect changes of the value and drift of the secret.
+	ValueHash    string    `json:",omitempty"`
+	UpdatedAt    time.Time `json:",omitempty"`
+	SyncedAt     time.Time `json:",omitempty"`
+	ReconciledAt time.Time `json:",omitempty"`
+	LastError    string    `json:",omitempty"`
+}
+
+func (e *syncEntry) save(ctx context.Context, s logical.Storage) error {
+	if e.Name == "" {
+		return errSyncNameEmpty
+	}
+
+	entry, err := logical.StorageEntryJSON(fmt.Sprintf("%s/%s", pathPatternSync, e.Name), e)
+	if err != nil {
+		return err
+	}
+
+	return s.Put(ctx, entry)
+}
+
+func getSyncEntry(ctx context.Context, name string, s logical.Storage) (*syncEntry, error) {
+	entry, err := s.Get(ctx, fmt.Sprintf("%s/%s", pathPatternSync, name))
+	if err != nil {
+		return nil, err
+	}
+
+	if entry == nil {
+		return nil, nil
+	}
+
+	e := &syncEntry{}
+
+	if err = entry.DecodeJSON(e); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// value returns the value of the entry, which is that of the referenced entry
+// if any.
+func (e *syncEntry) value(ctx context.Context, s logical.Storage) (string, error) {
+	if e.ValueFromEntry == "" {
+		return e.Value, nil
+	}
+
+	ref, err := getSyncEntry(ctx, e.ValueFromEntry, s)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", errUnableToGetSyncEntry, e.ValueFromEntry, err)
+	}
+
+	if ref == nil {
+		return "", fmt.Errorf("%w: %s", errSyncValueFromEntryNotFound, e.ValueFromEntry)
+	}
+
+	return ref.Value, nil
+}
+
+// hashSyncValue returns the hex encoded SHA-256 of the value.
+func hashSyncValue(value string) string {
+	sum := sha256.Sum256([]byte(value))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// pushSyncEntry syncs the value of the entry to its Actions secret, updating
+// the state of the entry, but not saving it. Unless forced, the secret is
+// only pushed if the value changed or the secret drifted, i.e. it was deleted
+// or updated out of band, since the last sync.
+func (b *backend) pushSyncEntry(
+	ctx context.Context,
+	s logical.Storage,
+	e *syncEntry,
+	force bool,
+	trigger string,
+) error {
+	client, done, err := b.Client(ctx, s, e.App)
+	if err != nil {
+		return err
+	}
+
+	defer done()
+
+	tokReq := &tokenRequest{OrgName: e.OrgName, InstallationID: e.InstallationID, App: e.App}
+	if err = client.CheckInstallationAllowed(ctx, tokReq); err != nil {
+		return err
+	}
+
+	if tokReq.InstallationID == 0 {
+		if tokReq.InstallationID, err = client.installationID(ctx, tokReq.OrgName); err != nil {
+			return err
+		}
+	}
+
+	value, err := e.value(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	hash := hashSyncValue(value)
+
+	secret, pushed, err := client.PushActionsSecret(ctx, tokReq.InstallationID, &e.actionsSecretTarget,
+		value, e.Visibility, e.SelectedRepositoryIDs, force || hash != e.ValueHash, e.UpdatedAt,
+	)
+	if pushed {
+		syncPushes.WithLabelValues(strconv.FormatBool(err == nil), trigger).Inc()
+	}
+
+	if err != nil || !pushed {
+		return err
+	}
+
+	if secret != nil {
+		e.UpdatedAt = secret.UpdatedAt
+	}
+
+	e.ValueHash = hash
+	e.SyncedAt = time.Now()
+
+	b.Logger().Info("synced actions secret", "sync", e.Name, "target", e.actionsSecretTarget.String())
+
+	return nil
+}
+
+// deleteSyncedSecret deletes the Actions secret synced by the entry, as the
+// App installation it was synced with.
+func (b *backend) deleteSyncedSecret(ctx context.Context, s logical.Storage, e *syncEntry) error {
+	client, done, err := b.Client(ctx, s, e.App)
+	if err != nil {
+		return err
+	}
+
+	defer done()
+
+	installationID := e.InstallationID
+	if installationID == 0 {
+		if installationID, err = client.installationID(ctx, e.OrgName); err != nil {
+			return err
+		}
+	}
+
+	if err = client.DeleteActionsSecret(ctx, installationID, &e.actionsSecretTarget); err != nil {
+		return err
+	}
+
+	b.Logger().Info("deleted synced actions secret", "sync", e.Name, "target", e.actionsSecretTarget.String())
+
+	return nil
+}
+
+// reconcileSyncEntries re-syncs the Actions secrets of the sync entries whose
+// value changed or whose secret drifted. Entries are reconciled at most every
+// syncReconcileInterval unless their last sync failed. Failures are recorded
+// on the entries, logged and retried on the next run.
+func (b *backend) reconcileSyncEntries(ctx context.Context, s logical.Storage) error {
+	names, err := s.List(ctx, pathPatternSync+"/")
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err = b.reconcileSyncEntry(ctx, s, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reconcileSyncEntry reconciles the named sync entry, only returning errors of
+// the storage.
+func (b *backend) reconcileSyncEntry(ctx context.Context, s logical.Storage, name string) error {
+	b.syncLock.Lock()
+	defer b.syncLock.Unlock()
+
+	e, err := getSyncEntry(ctx, name, s)
+	if err != nil {
+		return err
+	}
+
+	if e == nil || (e.LastError == "" && time.Since(e.ReconciledAt) < syncReconcileInterval) {
+		return nil
+	}
+
+	e.LastError = ""
+
+	if err = b.pushSyncEntry(ctx, s, e, false, syncTriggerReconcile); err != nil {
+		e.LastError = err.Error()
+
+		b.Logger().Warn("unable to reconcile actions secret",
+			"sync", e.Name,
+			"target", e.actionsSecretTarget.String(),
+			"err", err,
+		)
+	}
+
+	e.ReconciledAt = time.Now()
+
+	return e.save(ctx, s)
+}
@@ -0,0 +1,157 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// testAgeSyncEntry makes the named sync entry due for reconciliation.
+func testAgeSyncEntry(t *testing.T, s logical.Storage, name string) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	e, err := getSyncEntry(ctx, name, s)
+	assert.NilError(t, err)
+
+	e.ReconciledAt = time.Now().Add(-syncReconcileInterval)
+	assert.NilError(t, e.save(ctx, s))
+}
+
+func TestHashSyncValue(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, hashSyncValue("s3cr3t"), hashSyncValue("s3cr3t"))
+	assert.Assert(t, hashSyncValue("s3cr3t") != hashSyncValue("s3cr3T"))
+}
+
+func TestBackend_ReconcileSyncEntries(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := newTestGitHubServer(t)
+	b, storage := testGitHubBackend(t, ts)
+
+	actions := newTestActionsSecrets(t, ts)
+
+	orgSecret := fmt.Sprintf("orgs/%s/actions/secrets/NPM_TOKEN", testOrgName1)
+	repoSecret := fmt.Sprintf("repos/%s/website/actions/secrets/NPM_TOKEN", testOrgName1)
+
+	pushed := testutil.ToFloat64(syncPushes.WithLabelValues("true", syncTriggerReconcile))
+
+	for _, entry := range []struct {
+		data map[string]any
+		name string
+	}{
+		{name: "NPM_TOKEN", data: map[string]any{keyOrgName: testOrgName1, keyValue: "s3cr3t"}},
+		{name: "website-npm", data: map[string]any{
+			keyOrgName:        testOrgName1,
+			keyRepository:     "website",
+			keySecretName:     "NPM_TOKEN",
+			keyValueFromEntry: "NPM_TOKEN",
+		}},
+	} {
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.CreateOperation,
+			Path:      pathPatternSync + "/" + entry.name,
+			Data:      entry.data,
+		})
+		assert.NilError(t, err)
+	}
+
+	assert.Equal(t, actions.pushes(), 2)
+
+	// Each sync uses a single internal token.
+	assert.Equal(t, len(ts.tokenRequests()), 2)
+
+	// Entries are not reconciled before their interval.
+	ts.mu.Lock()
+	delete(actions.secrets, orgSecret)
+	ts.mu.Unlock()
+
+	assert.NilError(t, b.periodicFunc(ctx, &logical.Request{Storage: storage}))
+	assert.Equal(t, actions.pushes(), 2)
+
+	// Deleted secrets are pushed again, whereas unchanged secrets are not.
+	testAgeSyncEntry(t, storage, "NPM_TOKEN")
+	testAgeSyncEntry(t, storage, "website-npm")
+
+	assert.NilError(t, b.periodicFunc(ctx, &logical.Request{Storage: storage}))
+	assert.Equal(t, actions.pushes(), 3)
+	assert.Equal(t, len(ts.tokenRequests()), 4)
+
+	secret, ok := actions.secret(orgSecret)
+	assert.Assert(t, ok)
+	assert.Equal(t, secret.Value, "s3cr3t")
+	assert.Assert(t, testutil.ToFloat64(syncPushes.WithLabelValues("true", syncTriggerReconcile)) >= pushed+1)
+
+	// Secrets updated out of band are pushed again.
+	testAgeSyncEntry(t, storage, "website-npm")
+
+	ts.mu.Lock()
+	actions.secrets[repoSecret] = testSyncedSecret{UpdatedAt: time.Now().UTC(), Value: "tampered"}
+	ts.mu.Unlock()
+
+	assert.NilError(t, b.periodicFunc(ctx, &logical.Request{Storage: storage}))
+
+	secret, _ = actions.secret(repoSecret)
+	assert.Equal(t, secret.Value, "s3cr3t")
+
+	// Changes of referenced values are pushed to the referring secrets.
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternSync + "/NPM_TOKEN",
+		Data:      map[string]any{keyValue: "n3w"},
+	})
+	assert.NilError(t, err)
+
+	testAgeSyncEntry(t, storage, "website-npm")
+	assert.NilError(t, b.periodicFunc(ctx, &logical.Request{Storage: storage}))
+
+	secret, _ = actions.secret(repoSecret)
+	assert.Equal(t, secret.Value, "n3w")
+
+	// Failures are recorded and retried on the next run.
+	ts.mu.Lock()
+	actions.failing = true
+	delete(actions.secrets, repoSecret)
+	ts.mu.Unlock()
+
+	testAgeSyncEntry(t, storage, "website-npm")
+	assert.NilError(t, b.periodicFunc(ctx, &logical.Request{Storage: storage}))
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternSync + "/website-npm",
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, is.Contains(r.Data["last_error"].(string), errUnableToPutActionsSecret.Error()))
+
+	ts.mu.Lock()
+	actions.failing = false
+	ts.mu.Unlock()
+
+	assert.NilError(t, b.periodicFunc(ctx, &logical.Request{Storage: storage}))
+
+	secret, ok = actions.secret(repoSecret)
+	assert.Assert(t, ok)
+	assert.Equal(t, secret.Value, "n3w")
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternSync + "/website-npm",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, r.Data["last_error"], "")
+}
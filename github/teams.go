@@ -0,0 +1,146 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	errUnableToGetOrgMembership     = Error("unable to get organization membership")
+	errUnableToGetTeamMembership    = Error("unable to get team membership")
+	errUnableToAddTeamMembership    = Error("unable to add team membership")
+	errUnableToRemoveTeamMembership = Error("unable to remove team membership")
+)
+
+// orgMembershipStateActive is the state of the memberships of users that have
+// joined the organization, rather than been invited to it.
+const orgMembershipStateActive = "active"
+
+// Team membership roles.
+const (
+	teamRoleMember     = "member"
+	teamRoleMaintainer = "maintainer"
+)
+
+// teamsTokenPermissions are the permissions of the internal tokens used to
+// manage team memberships.
+var teamsTokenPermissions = map[string]string{"members": "write"}
+
+// Model the parts of a team membership that we care about.
+type teamMembership struct {
+	Role  string `json:"role"`
+	State string `json:"state,omitempty"`
+}
+
+// teamsTokenRequest returns the token request of the internal tokens used to
+// manage the team memberships of an installation.
+func teamsTokenRequest(installationID int) *tokenRequest {
+	return &tokenRequest{
+		InstallationID:   installationID,
+		tokenConstraints: tokenConstraints{Permissions: teamsTokenPermissions},
+	}
+}
+
+// teamMembershipPath returns the API path of the membership of the user in
+// the team of the organization.
+func teamMembershipPath(org, team, username string) string {
+	return fmt.Sprintf("orgs/%s/teams/%s/memberships/%s",
+		url.PathEscape(org), url.PathEscape(team), url.PathEscape(username))
+}
+
+// OrgMember reports whether the user is an active member of the organization,
+// as the installation. Users with a pending invitation are not members yet.
+func (c *Client) OrgMember(
+	ctx context.Context,
+	installationID int,
+	org, username string,
+) (bool, error) {
+	var membership struct {
+		State string `json:"state"`
+	}
+
+	err := c.installationRequest(ctx, teamsTokenRequest(installationID),
+		http.MethodGet, fmt.Sprintf("orgs/%s/memberships/%s", url.PathEscape(org), url.PathEscape(username)), nil, &membership,
+	)
+
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", errUnableToGetOrgMembership, err)
+	}
+
+	return membership.State == orgMembershipStateActive, nil
+}
+
+// TeamMembership returns the membership of the user in the team of the
+// organization, as the installation, or nil if the user is not a member.
+func (c *Client) TeamMembership(
+	ctx context.Context,
+	installationID int,
+	org, team, username string,
+) (*teamMembership, error) {
+	var membership teamMembership
+
+	err := c.installationRequest(ctx, teamsTokenRequest(installationID),
+		http.MethodGet, teamMembershipPath(org, team, username), nil, &membership,
+	)
+
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToGetTeamMembership, err)
+	}
+
+	return &membership, nil
+}
+
+// AddTeamMembership adds the user to the team of the organization with the
+// given role, as the installation. Users that are not members of the
+// organization would be invited to it, so callers check OrgMember first.
+func (c *Client) AddTeamMembership(
+	ctx context.Context,
+	installationID int,
+	org, team, username, role string,
+) (*teamMembership, error) {
+	var membership teamMembership
+
+	if err := c.installationRequest(ctx, teamsTokenRequest(installationID),
+		http.MethodPut, teamMembershipPath(org, team, username), &teamMembership{Role: role}, &membership,
+	); err != nil {
+		return nil, fmt.Errorf("%s: %w", errUnableToAddTeamMembership, err)
+	}
+
+	return &membership, nil
+}
+
+// RemoveTeamMembership removes the user from the team of the organization, as
+// the installation. Users that are not members are considered removed.
+func (c *Client) RemoveTeamMembership(
+	ctx context.Context,
+	installationID int,
+	org, team, username string,
+) error {
+	err := c.installationRequest(ctx, teamsTokenRequest(installationID),
+		http.MethodDelete, teamMembershipPath(org, team, username), nil, nil,
+	)
+
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", errUnableToRemoveTeamMembership, err)
+	}
+
+	return nil
+}
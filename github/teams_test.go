@@ -0,0 +1,26 @@
+package github
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestTeamsTokenRequest(t *testing.T) {
+	t.Parallel()
+
+	tokReq := teamsTokenRequest(testInsID1)
+	assert.Equal(t, tokReq.InstallationID, testInsID1)
+	assert.DeepEqual(t, tokReq.tokenConstraints, tokenConstraints{
+		Permissions: map[string]string{"members": "write"},
+	})
+}
+
+func TestTeamMembershipPath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t,
+		teamMembershipPath(testOrgName1, "admins", "octocat"),
+		"orgs/test-1/teams/admins/memberships/octocat",
+	)
+}
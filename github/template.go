@@ -0,0 +1,167 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/helper/identitytpl"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	errInvalidTemplate    = Error("invalid template")
+	errUnresolvedTemplate = Error("unable to resolve template")
+)
+
+// isTemplate reports whether the string contains identity template directives.
+func isTemplate(s string) bool {
+	return strings.Contains(s, "{{")
+}
+
+// templated reports whether any of the organization name, repositories or
+// permissions of the token request are identity templates.
+func (tokReq *tokenRequest) templated() bool {
+	return isTemplate(tokReq.OrgName) ||
+		slices.ContainsFunc(tokReq.Repositories, isTemplate) ||
+		slices.ContainsFunc(slices.Collect(maps.Keys(tokReq.Permissions)), isTemplate) ||
+		slices.ContainsFunc(slices.Collect(maps.Values(tokReq.Permissions)), isTemplate)
+}
+
+// validateTemplates checks that the identity templates of the token request
+// are well formed.
+func (tokReq *tokenRequest) validateTemplates() error {
+	for field, values := range map[string][]string{
+		keyOrgName: {tokReq.OrgName},
+		keyRepos:   tokReq.Repositories,
+		keyPerms:   slices.Concat(slices.Collect(maps.Keys(tokReq.Permissions)), slices.Collect(maps.Values(tokReq.Permissions))),
+	} {
+		for _, v := range values {
+			if _, _, err := identitytpl.PopulateString(identitytpl.PopulateStringInput{
+				String:            v,
+				ValidityCheckOnly: true,
+			}); err != nil {
+				return fmt.Errorf("%w: %s %q: %w", errInvalidTemplate, field, v, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// untemplatedPermissions returns those of the permissions that involve no
+// identity templates.
+func untemplatedPermissions(perms map[string]string) map[string]string {
+	untemplated := maps.Clone(perms)
+	maps.DeleteFunc(untemplated, func(name, level string) bool {
+		return isTemplate(name) || isTemplate(level)
+	})
+
+	return untemplated
+}
+
+// templateRenderer resolves identity templates for a requesting entity.
+type templateRenderer struct {
+	entity *logical.Entity
+	groups []*logical.Group
+}
+
+// render resolves the identity templates in the string.
+func (r *templateRenderer) render(field, s string) (string, error) {
+	_, rendered, err := identitytpl.PopulateString(identitytpl.PopulateStringInput{
+		String: s,
+		Entity: r.entity,
+		Groups: r.groups,
+		Mode:   identitytpl.ACLTemplating,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: %s %q: %w", errUnresolvedTemplate, field, s, err)
+	}
+
+	return rendered, nil
+}
+
+// renderList resolves the identity templates in the string, which expands to
+// several values if it consists of a single directive of a list, e.g.
+// {{identity.entity.groups.names}}.
+func (r *templateRenderer) renderList(field, s string) ([]string, error) {
+	directive := strings.TrimSpace(s)
+	if !strings.HasPrefix(directive, "{{") || !strings.HasSuffix(directive, "}}") ||
+		strings.Count(directive, "{{") != 1 {
+		rendered, err := r.render(field, s)
+
+		return []string{rendered}, err
+	}
+
+	_, rendered, err := identitytpl.PopulateString(identitytpl.PopulateStringInput{
+		String: directive,
+		Entity: r.entity,
+		Groups: r.groups,
+		Mode:   identitytpl.JSONTemplating,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %q: %w", errUnresolvedTemplate, field, s, err)
+	}
+
+	var values []string
+	if err = json.Unmarshal([]byte(rendered), &values); err != nil {
+		var value string
+		if err = json.Unmarshal([]byte(rendered), &value); err != nil {
+			return nil, fmt.Errorf("%w: %s %q: %w", errUnresolvedTemplate, field, s, err)
+		}
+
+		values = []string{value}
+	}
+
+	// Unlike ACL templating, JSON templating renders missing values empty.
+	if len(values) == 0 || slices.Contains(values, "") {
+		return nil, fmt.Errorf("%w: %s %q: %w",
+			errUnresolvedTemplate, field, s, identitytpl.ErrTemplateValueNotFound)
+	}
+
+	return values, nil
+}
+
+// renderTemplates returns a copy of the token request with its identity
+// templates resolved for the given entity and its groups.
+func (tokReq *tokenRequest) renderTemplates(entity *logical.Entity, groups []*logical.Group) (*tokenRequest, error) {
+	r := &templateRenderer{entity: entity, groups: groups}
+
+	rendered := *tokReq
+
+	var err error
+	if rendered.OrgName, err = r.render(keyOrgName, tokReq.OrgName); err != nil {
+		return nil, err
+	}
+
+	if tokReq.Repositories != nil {
+		rendered.Repositories = make([]string, 0, len(tokReq.Repositories))
+
+		for _, repo := range tokReq.Repositories {
+			repos, err := r.renderList(keyRepos, repo)
+			if err != nil {
+				return nil, err
+			}
+
+			rendered.Repositories = append(rendered.Repositories, repos...)
+		}
+	}
+
+	if tokReq.Permissions != nil {
+		rendered.Permissions = make(map[string]string, len(tokReq.Permissions))
+
+		for name, level := range tokReq.Permissions {
+			if name, err = r.render(keyPerms, name); err != nil {
+				return nil, err
+			}
+
+			if rendered.Permissions[name], err = r.render(keyPerms, level); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &rendered, nil
+}
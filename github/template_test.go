@@ -0,0 +1,204 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/assert"
+)
+
+// testEntity is an identity entity with metadata and testGroups its groups.
+var (
+	testEntity = &logical.Entity{
+		ID:   "entity-1",
+		Name: "alice",
+		Metadata: map[string]string{
+			"github_org":  testOrgName1,
+			"github_repo": "public",
+			"level":       "read",
+		},
+	}
+	testGroups = []*logical.Group{
+		{ID: "group-1", Name: "team-a"},
+		{ID: "group-2", Name: "team-b"},
+	}
+)
+
+func TestTokenRequest_ValidateTemplates(t *testing.T) {
+	t.Parallel()
+
+	assert.NilError(t, (&tokenRequest{
+		OrgName: "{{identity.entity.metadata.github_org}}",
+		tokenConstraints: tokenConstraints{
+			Repositories: []string{"svc-{{identity.entity.name}}"},
+			Permissions:  map[string]string{"contents": "{{identity.entity.metadata.level}}"},
+		},
+	}).validateTemplates())
+
+	assert.ErrorContains(t, (&tokenRequest{
+		tokenConstraints: tokenConstraints{Repositories: []string{"{{identity.entity.name"}},
+	}).validateTemplates(), errInvalidTemplate.Error()+`: repositories "{{identity.entity.name"`)
+}
+
+func TestTokenRequest_RenderTemplates(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		entity *logical.Entity
+		tokReq *tokenRequest
+		exp    *tokenRequest
+		name   string
+		err    string
+	}{
+		{
+			name:   "NotTemplated",
+			entity: testEntity,
+			tokReq: &tokenRequest{OrgName: testOrgName1},
+			exp:    &tokenRequest{OrgName: testOrgName1},
+		},
+		{
+			name:   "Templated",
+			entity: testEntity,
+			tokReq: &tokenRequest{
+				OrgName:        "{{identity.entity.metadata.github_org}}",
+				InstallationID: testInsID1,
+				tokenConstraints: tokenConstraints{
+					Repositories: []string{
+						"{{identity.entity.metadata.github_repo}}",
+						"svc-{{identity.entity.name}}",
+						"{{identity.entity.groups.names}}",
+					},
+					Permissions: map[string]string{
+						"contents": "{{identity.entity.metadata.level}}",
+						"issues":   "write",
+					},
+				},
+			},
+			exp: &tokenRequest{
+				OrgName:        testOrgName1,
+				InstallationID: testInsID1,
+				tokenConstraints: tokenConstraints{
+					Repositories: []string{"public", "svc-alice", "team-a", "team-b"},
+					Permissions:  map[string]string{"contents": "read", "issues": "write"},
+				},
+			},
+		},
+		{
+			name:   "MissingMetadata",
+			entity: testEntity,
+			tokReq: &tokenRequest{OrgName: "{{identity.entity.metadata.team}}"},
+			err:    `org_name "{{identity.entity.metadata.team}}": no value could be found`,
+		},
+		{
+			name:   "MissingListMetadata",
+			entity: testEntity,
+			tokReq: &tokenRequest{
+				tokenConstraints: tokenConstraints{Repositories: []string{"{{identity.entity.metadata.team}}"}},
+			},
+			err: `repositories "{{identity.entity.metadata.team}}": no value could be found`,
+		},
+		{
+			name:   "NoEntity",
+			tokReq: &tokenRequest{OrgName: "{{identity.entity.metadata.github_org}}"},
+			err:    "no entity was provided",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rendered, err := tc.tokReq.renderTemplates(tc.entity, testGroups)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, errUnresolvedTemplate.Error())
+				assert.ErrorContains(t, err, tc.err)
+
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, rendered.OrgName, tc.exp.OrgName)
+			assert.Equal(t, rendered.InstallationID, tc.exp.InstallationID)
+			assert.DeepEqual(t, rendered.tokenConstraints, tc.exp.tokenConstraints)
+		})
+	}
+}
+
+func TestBackend_PathTokenPermissionSetTemplated(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := testBackend(t)
+
+	system := b.System().(*logical.StaticSystemView)
+	system.EntityVal = testEntity
+	system.GroupsVal = testGroups
+
+	rec := &testRepositoriesRecorder{}
+
+	ts := testRepositoriesServer(t, rec, http.StatusOK)
+	defer ts.Close()
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			keyAppID:   testAppID1,
+			keyPrvKey:  testPrvKeyValid,
+			keyBaseURL: ts.URL,
+		},
+	})
+	assert.NilError(t, err)
+
+	// Templated permissions are not validated until resolved.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternPermissionSet + "/team",
+		Data: map[string]any{
+			keyOrgName: "{{identity.entity.metadata.github_org}}",
+			keyRepos:   "{{identity.entity.metadata.github_repo}}",
+			keyPerms:   map[string]any{"contents": "{{identity.entity.metadata.level}}"},
+		},
+	})
+	assert.NilError(t, err)
+
+	// Resolved for the requesting entity.
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternToken + "/team",
+		EntityID:  testEntity.ID,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, r.Data["token"], testToken)
+	assert.DeepEqual(t, rec.minted(), []tokenConstraints{{
+		Repositories: []string{"public"},
+		Permissions:  map[string]string{"contents": "read"},
+	}})
+
+	// Refused for requests without an entity.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternToken + "/team",
+	})
+	assert.ErrorContains(t, err, errUnresolvedTemplate.Error())
+
+	var coded logical.HTTPCodedError
+	assert.Assert(t, errors.As(err, &coded))
+	assert.Equal(t, coded.Code(), http.StatusBadRequest)
+
+	// Malformed templates are refused up front.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternPermissionSet + "/malformed",
+		Data:      map[string]any{keyOrgName: "{{identity.entity.name"},
+	})
+	assert.ErrorContains(t, err, errInvalidTemplate.Error())
+}
@@ -0,0 +1,85 @@
+package github
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	errUnableToParseCACert     = Error("unable to parse CA certificate")
+	errUnableToParseClientCert = Error("unable to parse client certificate and key")
+	errClientCertKeyPair       = Error("client certificate and key must be set together")
+	errInvalidTLSMinVersion    = Error("invalid minimum TLS version")
+)
+
+// tlsVersions maps the accepted minimum TLS version names, which follow those
+// used throughout Vault's own configuration, to their protocol versions.
+var tlsVersions = map[string]uint16{
+	"tls10": tls.VersionTLS10,
+	"tls11": tls.VersionTLS11,
+	"tls12": tls.VersionTLS12,
+	"tls13": tls.VersionTLS13,
+}
+
+// tlsVersionNames returns the sorted accepted minimum TLS version names.
+func tlsVersionNames() []string {
+	names := make([]string, 0, len(tlsVersions))
+	for name := range tlsVersions {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// tlsConfig returns the TLS configuration for connections to GitHub, or nil
+// when none of the TLS settings are configured and Go's defaults apply.
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	if c.CACertificate == "" && c.ClientCertificate == "" && c.ClientKey == "" &&
+		c.TLSMinVersion == "" && c.TLSServerName == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.TLSServerName,
+	}
+
+	if c.TLSMinVersion != "" {
+		version, ok := tlsVersions[c.TLSMinVersion]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q is not one of %s",
+				errInvalidTLSMinVersion, c.TLSMinVersion, strings.Join(tlsVersionNames(), ", "))
+		}
+
+		tlsConfig.MinVersion = version
+	}
+
+	if c.CACertificate != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(c.CACertificate)) {
+			return nil, errUnableToParseCACert
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if (c.ClientCertificate == "") != (c.ClientKey == "") {
+		return nil, errClientCertKeyPair
+	}
+
+	if c.ClientCertificate != "" {
+		cert, err := tls.X509KeyPair([]byte(c.ClientCertificate), []byte(c.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errUnableToParseClientCert, err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
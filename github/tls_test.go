@@ -0,0 +1,265 @@
+package github
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	is "gotest.tools/assert/cmp"
+)
+
+// testCertificate returns a freshly generated self-signed PEM certificate and
+// key for the given common name.
+func testCertificate(t *testing.T, cn string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NilError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NilError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+}
+
+// testServerCA returns the PEM encoded certificate of the given TLS server.
+func testServerCA(ts *httptest.Server) string {
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: ts.Certificate().Raw,
+	}))
+}
+
+func TestConfig_TLSConfig(t *testing.T) {
+	t.Parallel()
+
+	cert, key := testCertificate(t, "vault")
+	_, otherKey := testCertificate(t, "other")
+
+	cases := []struct {
+		err  error
+		conf *Config
+		exp  func(*testing.T, *tls.Config)
+		name string
+	}{
+		{
+			name: "Unset",
+			conf: &Config{},
+			exp: func(t *testing.T, c *tls.Config) {
+				t.Helper()
+				assert.Assert(t, is.Nil(c))
+			},
+		},
+		{
+			name: "Defaults",
+			conf: &Config{TLSServerName: "github.example.com"},
+			exp: func(t *testing.T, c *tls.Config) {
+				t.Helper()
+				assert.Equal(t, c.MinVersion, uint16(tls.VersionTLS12))
+				assert.Equal(t, c.ServerName, "github.example.com")
+				assert.Assert(t, is.Nil(c.RootCAs))
+			},
+		},
+		{
+			name: "Full",
+			conf: &Config{
+				CACertificate:     cert,
+				ClientCertificate: cert,
+				ClientKey:         key,
+				TLSMinVersion:     "tls13",
+			},
+			exp: func(t *testing.T, c *tls.Config) {
+				t.Helper()
+				assert.Equal(t, c.MinVersion, uint16(tls.VersionTLS13))
+				assert.Assert(t, c.RootCAs != nil)
+				assert.Equal(t, len(c.Certificates), 1)
+			},
+		},
+		{
+			name: "InvalidMinVersion",
+			conf: &Config{TLSMinVersion: "1.2"},
+			err:  errInvalidTLSMinVersion,
+		},
+		{
+			name: "InvalidCACertificate",
+			conf: &Config{CACertificate: "not a certificate"},
+			err:  errUnableToParseCACert,
+		},
+		{
+			name: "ClientCertificateWithoutKey",
+			conf: &Config{ClientCertificate: cert},
+			err:  errClientCertKeyPair,
+		},
+		{
+			name: "ClientKeyWithoutCertificate",
+			conf: &Config{ClientKey: key},
+			err:  errClientCertKeyPair,
+		},
+		{
+			name: "ClientKeyMismatch",
+			conf: &Config{ClientCertificate: cert, ClientKey: otherKey},
+			err:  errUnableToParseClientCert,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := tc.conf.tlsConfig()
+			if tc.err != nil {
+				assert.ErrorContains(t, err, tc.err.Error())
+
+				return
+			}
+
+			assert.NilError(t, err)
+			tc.exp(t, c)
+		})
+	}
+}
+
+func TestClient_TLS(t *testing.T) {
+	t.Parallel()
+
+	prvKey, key := testGeneratePrvKey(t, 2048)
+	clientCert, clientKey := testCertificate(t, "vault")
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM([]byte(clientCert))
+
+	cases := []struct {
+		conf func(ts *httptest.Server) *Config
+		name string
+		mTLS bool
+		err  bool
+	}{
+		{
+			name: "UntrustedCA",
+			conf: func(_ *httptest.Server) *Config {
+				return &Config{}
+			},
+			err: true,
+		},
+		{
+			name: "TrustedCA",
+			conf: func(ts *httptest.Server) *Config {
+				return &Config{CACertificate: testServerCA(ts)}
+			},
+		},
+		{
+			name: "ServerNameOverride",
+			conf: func(ts *httptest.Server) *Config {
+				// The httptest certificate is valid for example.com.
+				return &Config{
+					CACertificate: testServerCA(ts),
+					TLSServerName: "example.com",
+				}
+			},
+		},
+		{
+			name: "ServerNameMismatch",
+			conf: func(ts *httptest.Server) *Config {
+				return &Config{
+					CACertificate: testServerCA(ts),
+					TLSServerName: "github.internal",
+				}
+			},
+			err: true,
+		},
+		{
+			name: "ClientCertificateMissing",
+			mTLS: true,
+			conf: func(ts *httptest.Server) *Config {
+				return &Config{CACertificate: testServerCA(ts)}
+			},
+			err: true,
+		},
+		{
+			name: "ClientCertificate",
+			mTLS: true,
+			conf: func(ts *httptest.Server) *Config {
+				return &Config{
+					CACertificate:     testServerCA(ts),
+					ClientCertificate: clientCert,
+					ClientKey:         clientKey,
+				}
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := httptest.NewUnstartedServer(testAppHandler(t, &key.PublicKey))
+			if tc.mTLS {
+				ts.TLS = &tls.Config{
+					ClientAuth: tls.RequireAndVerifyClientCert,
+					ClientCAs:  clientCAs,
+				}
+			}
+
+			ts.StartTLS()
+			defer ts.Close()
+
+			conf := tc.conf(ts)
+			conf.AppID = testAppID1
+			conf.PrvKey = prvKey
+			conf.BaseURL = ts.URL
+
+			client, err := NewClient(conf)
+			assert.NilError(t, err)
+
+			// Both the authenticated and unauthenticated transports apply
+			// the TLS settings.
+			_, appErr := client.App(context.Background())
+			_, revokeErr := client.RevokeToken(context.Background(), testToken)
+
+			if tc.err {
+				assert.ErrorContains(t, appErr, "tls")
+				assert.Assert(t, revokeErr != nil)
+
+				return
+			}
+
+			assert.NilError(t, appErr)
+			assert.NilError(t, revokeErr)
+		})
+	}
+}
+
+func TestNewClient_InvalidTLS(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewClient(&Config{
+		AppID:         testAppID1,
+		PrvKey:        testPrvKeyValid,
+		BaseURL:       testBaseURLValid,
+		CACertificate: "not a certificate",
+	})
+	assert.ErrorContains(t, err, errUnableToParseCACert.Error())
+}
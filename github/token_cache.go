@@ -0,0 +1,206 @@
+package github
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultTokenCacheMinRemaining is the lifetime a cached token must have left
+// to be returned when not configured.
+const defaultTokenCacheMinRemaining = 30 * time.Minute
+
+// secretCached is the internal data key marking the leases of cached tokens,
+// which are shared and so not revoked on GitHub when the lease ends.
+const secretCached = "cached"
+
+// tokenCacheRequests counts cacheable token requests by whether they were
+// answered by the cache.
+var tokenCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: fmt.Sprintf("%s_token_cache_requests_total", prefixMetrics),
+	Help: "Total cacheable token requests, by cache result.",
+}, []string{"result"})
+
+// tokenCacheMinRemaining returns the effective lifetime a cached token must
+// have left to be returned.
+func (c *Config) tokenCacheMinRemaining() time.Duration {
+	if c.TokenCacheMinRemaining != nil {
+		return *c.TokenCacheMinRemaining
+	}
+
+	return defaultTokenCacheMinRemaining
+}
+
+// tokenCache caches GitHub tokens in memory by the token request they were
+// created for. Tokens are encrypted with a key that only lives in memory.
+type tokenCache struct {
+	aead    cipher.AEAD
+	entries map[[sha256.Size]byte]*cachedToken
+	mu      sync.Mutex
+}
+
+// cachedToken is an encrypted GitHub access token response.
+type cachedToken struct {
+	expiresAt      time.Time
+	nonce          []byte
+	sealed         []byte
+	installationID int
+}
+
+// newTokenCache returns an empty token cache with a random encryption key.
+func newTokenCache() (*tokenCache, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenCache{aead: aead, entries: make(map[[sha256.Size]byte]*cachedToken)}, nil
+}
+
+// tokenCacheKey returns the cache key of the token request, covering all of
+// its fields.
+func tokenCacheKey(tokReq *tokenRequest) ([sha256.Size]byte, error) {
+	b, err := json.Marshal(tokReq)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+
+	return sha256.Sum256(b), nil
+}
+
+// get returns the response data and installation ID of the cached token with
+// the key, if it has at least the given lifetime left.
+func (tc *tokenCache) get(key [sha256.Size]byte, minRemaining time.Duration) (map[string]any, int, bool) {
+	tc.mu.Lock()
+	entry, ok := tc.entries[key]
+	tc.mu.Unlock()
+
+	if !ok || time.Until(entry.expiresAt) < minRemaining {
+		return nil, 0, false
+	}
+
+	b, err := tc.aead.Open(nil, entry.nonce, entry.sealed, key[:])
+	if err != nil {
+		return nil, 0, false
+	}
+
+	var resData map[string]any
+	if err = json.Unmarshal(b, &resData); err != nil {
+		return nil, 0, false
+	}
+
+	return resData, entry.installationID, true
+}
+
+// put caches the token response data with the key, evicting expired tokens.
+func (tc *tokenCache) put(key [sha256.Size]byte, resData map[string]any, installationID int, expiresAt time.Time) error {
+	b, err := json.Marshal(resData)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, tc.aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return err
+	}
+
+	entry := &cachedToken{
+		expiresAt:      expiresAt,
+		nonce:          nonce,
+		sealed:         tc.aead.Seal(nil, nonce, b, key[:]),
+		installationID: installationID,
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	for k, v := range tc.entries {
+		if time.Now().After(v.expiresAt) {
+			delete(tc.entries, k)
+		}
+	}
+
+	tc.entries[key] = entry
+
+	return nil
+}
+
+// CachedToken returns a token for the token request as Token does, but
+// returns a previously created token for an identical request while it has at
+// least the given lifetime left. Leases of cached tokens do not revoke them on
+// GitHub since they are shared, so requests with a lease TTL are not cached.
+func (c *Client) CachedToken(
+	ctx context.Context,
+	tokReq *tokenRequest,
+	minRemaining time.Duration,
+) (*logical.Response, error) {
+	if tokReq.TTL > 0 || tokReq.MaxTTL > 0 {
+		return c.Token(ctx, tokReq)
+	}
+
+	key, err := tokenCacheKey(tokReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resData, installationID, ok := c.tokens.get(key, minRemaining); ok {
+		tokenCacheRequests.With(prometheus.Labels{"result": "hit"}).Inc()
+
+		cached := *tokReq
+		cached.InstallationID = installationID
+
+		return markCached(newTokenResponse(&cached, resData)), nil
+	}
+
+	tokenCacheRequests.With(prometheus.Labels{"result": "miss"}).Inc()
+
+	tokRes, err := c.Token(ctx, tokReq)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only tokens with a known expiry are cached.
+	expiresAtStr, _ := tokRes.Data["expires_at"].(string)
+
+	expiresAt, err := time.Parse(time.RFC3339, expiresAtStr)
+	if err != nil {
+		return tokRes, nil
+	}
+
+	if err = c.tokens.put(key, tokRes.Data, tokReq.InstallationID, expiresAt); err != nil {
+		c.logger.Warn("unable to cache token", "err", err)
+
+		return tokRes, nil
+	}
+
+	return markCached(tokRes), nil
+}
+
+// markCached marks the lease of the token response, if any, as that of a
+// cached token.
+func markCached(tokRes *logical.Response) *logical.Response {
+	if tokRes.Secret != nil {
+		tokRes.Secret.InternalData[secretCached] = true
+	}
+
+	return tokRes
+}
@@ -0,0 +1,295 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/assert"
+)
+
+// testTokenCacheServer returns a GitHub API server creating a distinct token
+// for each request that expires after the given lifetime, counting tokens
+// created and revoked.
+func testTokenCacheServer(t *testing.T, lifetime time.Duration, created, revoked *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			t.Helper()
+
+			switch r.URL.Path {
+			case "/app/installations":
+				fmt.Fprintf(w, `[{"id":%d,"account":{"login":%q}}]`, testInsID1, testOrgName1)
+			case fmt.Sprintf("/app/installations/%d/access_tokens", testInsID1):
+				n := created.Add(1)
+
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprintf(w, `{"token":"tok-%d","expires_at":%q}`,
+					n, time.Now().Add(lifetime).UTC().Format(time.RFC3339))
+			case "/installation/token":
+				revoked.Add(1)
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}),
+	)
+}
+
+func TestTokenCache(t *testing.T) {
+	t.Parallel()
+
+	tc, err := newTokenCache()
+	assert.NilError(t, err)
+
+	key, err := tokenCacheKey(&tokenRequest{InstallationID: testInsID1})
+	assert.NilError(t, err)
+
+	otherKey, err := tokenCacheKey(&tokenRequest{
+		InstallationID:   testInsID1,
+		tokenConstraints: tokenConstraints{Permissions: map[string]string{"contents": "read"}},
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, key != otherKey)
+
+	assert.NilError(t, tc.put(key, map[string]any{"token": testToken}, testInsID1, time.Now().Add(time.Hour)))
+
+	// Tokens are only held encrypted.
+	assert.Assert(t, !bytes.Contains(tc.entries[key].sealed, []byte(testToken)))
+
+	resData, installationID, ok := tc.get(key, 30*time.Minute)
+	assert.Assert(t, ok)
+	assert.Equal(t, resData["token"], testToken)
+	assert.Equal(t, installationID, testInsID1)
+
+	// Not returned without enough lifetime left.
+	_, _, ok = tc.get(key, 2*time.Hour)
+	assert.Assert(t, !ok)
+
+	// Not returned for different requests.
+	_, _, ok = tc.get(otherKey, 0)
+	assert.Assert(t, !ok)
+
+	// Expired tokens are evicted.
+	assert.NilError(t, tc.put(otherKey, map[string]any{"token": testToken}, testInsID1, time.Now().Add(-time.Minute)))
+	assert.NilError(t, tc.put(key, map[string]any{"token": testToken}, testInsID1, time.Now().Add(time.Hour)))
+	assert.Equal(t, len(tc.entries), 1)
+}
+
+func TestBackend_PathTokenPermissionSetCached(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := testBackend(t)
+
+	var created, revoked atomic.Int32
+
+	ts := testTokenCacheServer(t, time.Hour, &created, &revoked)
+	defer ts.Close()
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			keyAppID:   testAppID1,
+			keyPrvKey:  testPrvKeyValid,
+			keyBaseURL: ts.URL,
+		},
+	})
+	assert.NilError(t, err)
+
+	for name, data := range map[string]map[string]any{
+		"cached":    {keyInstallationID: testInsID1, keyCacheTokens: true},
+		"uncached":  {keyInstallationID: testInsID1},
+		"stringent": {keyInstallationID: testInsID1, keyCacheTokens: true, keyCacheMinRemaining: "2h", keyRepoIDs: "1"},
+	} {
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.CreateOperation,
+			Path:      pathPatternPermissionSet + "/" + name,
+			Data:      data,
+		})
+		assert.NilError(t, err)
+	}
+
+	// Cached tokens outlive their leases, so cannot be given lease TTLs.
+	for _, key := range []string{keyTTL, keyMaxTTL} {
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.CreateOperation,
+			Path:      pathPatternPermissionSet + "/refused",
+			Data:      map[string]any{keyInstallationID: testInsID1, keyCacheTokens: true, key: "30m"},
+		})
+		assert.ErrorContains(t, err, errPermissionSetCacheTokensTTL.Error())
+
+		var coded logical.HTTPCodedError
+		assert.Assert(t, errors.As(err, &coded))
+		assert.Equal(t, coded.Code(), http.StatusBadRequest)
+	}
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternPermissionSet + "/refused",
+		Data:      map[string]any{keyInstallationID: testInsID1, keyCacheTokens: true, keyCacheMinRemaining: "-1m"},
+	})
+	assert.NilError(t, err)
+	assert.ErrorContains(t, r.Error(), "negative")
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternPermissionSet + "/stringent",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, r.Data[keyCacheTokens], true)
+	assert.Equal(t, r.Data[keyCacheMinRemaining], int64(7200))
+
+	token := func(name string) *logical.Response {
+		t.Helper()
+
+		r, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternToken + "/" + name,
+		})
+		assert.NilError(t, err)
+
+		return r
+	}
+
+	hits := testutil.ToFloat64(tokenCacheRequests.WithLabelValues("hit"))
+	misses := testutil.ToFloat64(tokenCacheRequests.WithLabelValues("miss"))
+
+	// Identical requests share a token.
+	first := token("cached")
+	second := token("cached")
+	assert.Equal(t, first.Data["token"], "tok-1")
+	assert.Equal(t, second.Data["token"], "tok-1")
+	assert.Equal(t, second.Secret.InternalData[keyInstallationID], strconv.Itoa(testInsID1))
+	assert.Equal(t, created.Load(), int32(1))
+
+	assert.Assert(t, testutil.ToFloat64(tokenCacheRequests.WithLabelValues("hit")) >= hits+1)
+	assert.Assert(t, testutil.ToFloat64(tokenCacheRequests.WithLabelValues("miss")) >= misses+1)
+
+	// Permission sets without caching create a token every time.
+	assert.Equal(t, token("uncached").Data["token"], "tok-2")
+	assert.Equal(t, token("uncached").Data["token"], "tok-3")
+
+	// Requests with a lease TTL bypass the cache.
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternToken + "/cached",
+		Data:      map[string]any{keyTTL: "10m"},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, r.Data["token"], "tok-4")
+	assert.Equal(t, r.Secret.TTL, 10*time.Minute)
+	assert.Equal(t, r.Secret.InternalData[secretCached], nil)
+
+	// Cached tokens without enough lifetime left are replaced.
+	assert.Equal(t, token("stringent").Data["token"], "tok-5")
+	assert.Equal(t, token("stringent").Data["token"], "tok-6")
+
+	// Leases of cached tokens leave them to expire on GitHub.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.RevokeOperation,
+		Secret:    first.Secret,
+		Data:      map[string]any{"token": first.Data["token"]},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, revoked.Load(), int32(0))
+	assert.Equal(t, token("cached").Data["token"], "tok-1")
+}
+
+func TestBackend_PathTokenWriteCached(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		config map[string]any
+		data   map[string]any
+		name   string
+		exp    []string
+	}{
+		{
+			name: "Default",
+			exp:  []string{"tok-1", "tok-2"},
+		},
+		{
+			name:   "CacheAdhocTokens",
+			config: map[string]any{keyCacheAdhocTokens: true},
+			exp:    []string{"tok-1", "tok-1"},
+		},
+		{
+			name:   "MinRemaining",
+			config: map[string]any{keyCacheAdhocTokens: true, keyTokenCacheMinRemaining: "2h"},
+			exp:    []string{"tok-1", "tok-2"},
+		},
+		{
+			name:   "LeaseTTL",
+			config: map[string]any{keyCacheAdhocTokens: true},
+			data:   map[string]any{keyTTL: "10m"},
+			exp:    []string{"tok-1", "tok-2"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b, storage := testBackend(t)
+
+			var created, revoked atomic.Int32
+
+			ts := testTokenCacheServer(t, time.Hour, &created, &revoked)
+			defer ts.Close()
+
+			config := map[string]any{
+				keyAppID:   testAppID1,
+				keyPrvKey:  testPrvKeyValid,
+				keyBaseURL: ts.URL,
+			}
+			for k, v := range tc.config {
+				config[k] = v
+			}
+
+			_, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      pathPatternConfig,
+				Data:      config,
+			})
+			assert.NilError(t, err)
+
+			data := map[string]any{keyInstallationID: testInsID1}
+			for k, v := range tc.data {
+				data[k] = v
+			}
+
+			for _, exp := range tc.exp {
+				r, err := b.HandleRequest(ctx, &logical.Request{
+					Storage:   storage,
+					Operation: logical.UpdateOperation,
+					Path:      pathPatternToken,
+					Data:      data,
+				})
+				assert.NilError(t, err)
+				assert.Equal(t, r.Data["token"], exp)
+			}
+		})
+	}
+}
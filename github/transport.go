@@ -0,0 +1,156 @@
+package github
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+const (
+	// defaultRequestTimeout is the timeout of requests to GitHub when none is
+	// configured.
+	defaultRequestTimeout = 10 * time.Second
+
+	// defaultConnTimeoutDivisor is the fraction of the request timeout used for
+	// the dial and TLS handshake timeouts when those are not configured.
+	defaultConnTimeoutDivisor = 4
+)
+
+const (
+	errUnableToParseProxyURL = Error("unable to parse proxy URL")
+	errNegativeValue         = Error("value must not be negative")
+)
+
+// requestTimeout returns the effective timeout of requests to GitHub.
+func (c *Config) requestTimeout() time.Duration {
+	if c.RequestTimeout > 0 {
+		return c.RequestTimeout
+	}
+
+	return defaultRequestTimeout
+}
+
+// dialTimeout returns the effective timeout of connecting to GitHub.
+func (c *Config) dialTimeout() time.Duration {
+	if c.DialTimeout > 0 {
+		return c.DialTimeout
+	}
+
+	return c.requestTimeout() / defaultConnTimeoutDivisor
+}
+
+// tlsHandshakeTimeout returns the effective timeout of TLS handshakes with
+// GitHub.
+func (c *Config) tlsHandshakeTimeout() time.Duration {
+	if c.TLSHandshakeTimeout > 0 {
+		return c.TLSHandshakeTimeout
+	}
+
+	return c.requestTimeout() / defaultConnTimeoutDivisor
+}
+
+// maxIdleConnsPerHost returns the effective limit of idle connections kept
+// open to GitHub.
+func (c *Config) maxIdleConnsPerHost() int {
+	if c.MaxIdleConnsPerHost > 0 {
+		return c.MaxIdleConnsPerHost
+	}
+
+	return http.DefaultMaxIdleConnsPerHost
+}
+
+// proxyConfig returns the effective proxy configuration. The proxy settings of
+// the plugin's environment apply unless overridden in the config.
+func (c *Config) proxyConfig() *httpproxy.Config {
+	proxy := httpproxy.FromEnvironment()
+
+	if c.ProxyURL != "" {
+		proxy.HTTPProxy = c.ProxyURL
+		proxy.HTTPSProxy = c.ProxyURL
+	}
+
+	if len(c.NoProxy) > 0 {
+		proxy.NoProxy = strings.Join(c.NoProxy, ",")
+	}
+
+	return proxy
+}
+
+// proxy returns the proxy function of the transport to GitHub.
+func (c *Config) proxy() func(*http.Request) (*url.URL, error) {
+	// Keep Go's cached environment lookup when there is nothing to override.
+	if c.ProxyURL == "" && len(c.NoProxy) == 0 {
+		return http.ProxyFromEnvironment
+	}
+
+	proxyFunc := c.proxyConfig().ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
+// transport returns a new transport to GitHub with the configured timeouts,
+// connection limits, proxy and TLS settings.
+func (c *Config) transport() (*http.Transport, error) {
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialise a new transport instead of using Go's default. Dial and TLS
+	// handshake timeouts default to a fraction of the request timeout. Idle
+	// connections are unlimited in total and kept open indefinitely unless
+	// configured otherwise, since the plugin typically only deals with one
+	// host, whereas Go's default of idle connections per host applies.
+	return &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: c.dialTimeout(),
+		}).DialContext,
+		TLSHandshakeTimeout: c.tlsHandshakeTimeout(),
+		TLSClientConfig:     tlsConfig,
+		MaxIdleConns:        c.MaxIdleConns,
+		MaxIdleConnsPerHost: c.maxIdleConnsPerHost(),
+		IdleConnTimeout:     c.IdleConnTimeout,
+		Proxy:               c.proxy(),
+	}, nil
+}
+
+// validateProxyURL validates a proxy URL as accepted by the transport.
+func validateProxyURL(proxyURL string) (string, error) {
+	if proxyURL == "" {
+		return "", nil
+	}
+
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", errUnableToParseProxyURL, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return "", fmt.Errorf("%s: unsupported scheme %q", errUnableToParseProxyURL, u.Scheme)
+	}
+
+	if u.Host == "" {
+		return "", fmt.Errorf("%s: missing host", errUnableToParseProxyURL)
+	}
+
+	return u.String(), nil
+}
+
+// redactProxyURL returns the proxy URL with any password redacted.
+func redactProxyURL(proxyURL string) string {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return ""
+	}
+
+	return u.Redacted()
+}
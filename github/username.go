@@ -0,0 +1,114 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// defaultUsernameMetadataKey is the entity metadata key holding GitHub user
+// names unless configured otherwise.
+const defaultUsernameMetadataKey = "github_username"
+
+const (
+	keyUsername              = "username"
+	keyUsernameMetadataKey   = "username_metadata_key"
+	descUsernameMetadataKey  = "The entity (or alias) metadata key holding the GitHub user name of the requesting entity."
+	keyUsernameAliasAccessor = "username_alias_mount_accessor"
+	descUsernameAliasAccess  = "The accessor of an auth mount whose alias name of the requesting entity is its GitHub user name, e.g. that of a GitHub or OIDC auth mount. Takes precedence over the metadata key."
+)
+
+const (
+	errUnableToMapUsername = Error("unable to map the entity to a GitHub user")
+	errInvalidUsername     = Error("invalid GitHub user name")
+)
+
+// maxUsernameLength is the maximum length of GitHub user names.
+const maxUsernameLength = 39
+
+// usernameRegex matches GitHub user names, which are alphanumeric with single
+// hyphens between the alphanumeric characters.
+var usernameRegex = regexp.MustCompile(`^[A-Za-z0-9]+(-[A-Za-z0-9]+)*$`)
+
+// validUsername reports whether the name is a valid GitHub user name, which
+// names taken from entity metadata or alias names might not be.
+func validUsername(name string) bool {
+	return len(name) <= maxUsernameLength && usernameRegex.MatchString(name)
+}
+
+// usernameMapping models how roles map requesting Vault entities to GitHub
+// user names.
+type usernameMapping struct {
+	UsernameMetadataKey   string `json:",omitempty"`
+	UsernameAliasAccessor string `json:",omitempty"`
+}
+
+// usernameMetadataKey returns the effective metadata key holding GitHub user
+// names.
+func (m *usernameMapping) usernameMetadataKey() string {
+	if m.UsernameMetadataKey != "" {
+		return m.UsernameMetadataKey
+	}
+
+	return defaultUsernameMetadataKey
+}
+
+// username maps the entity to its GitHub user name.
+func (m *usernameMapping) username(entity *logical.Entity) (string, error) {
+	if entity == nil {
+		return "", fmt.Errorf("%w: no entity was provided", errUnableToMapUsername)
+	}
+
+	if m.UsernameAliasAccessor != "" {
+		for _, alias := range entity.Aliases {
+			if alias.MountAccessor == m.UsernameAliasAccessor && alias.Name != "" {
+				return alias.Name, nil
+			}
+		}
+
+		return "", fmt.Errorf("%w: entity %q has no alias on mount %q",
+			errUnableToMapUsername, entity.ID, m.UsernameAliasAccessor)
+	}
+
+	key := m.usernameMetadataKey()
+
+	if username := entity.Metadata[key]; username != "" {
+		return username, nil
+	}
+
+	for _, alias := range entity.Aliases {
+		if username := alias.Metadata[key]; username != "" {
+			return username, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: entity %q has no %q metadata", errUnableToMapUsername, entity.ID, key)
+}
+
+// requestUsername maps the entity of the request to its GitHub user name.
+// Failures to do so are client errors.
+func (b *backend) requestUsername(req *logical.Request, m *usernameMapping) (string, error) {
+	var entity *logical.Entity
+
+	if req.EntityID != "" {
+		var err error
+
+		if entity, err = b.System().EntityInfo(req.EntityID); err != nil {
+			return "", err
+		}
+	}
+
+	username, err := m.username(entity)
+	if err != nil {
+		return "", logical.CodedError(http.StatusBadRequest, err.Error())
+	}
+
+	// User names are used in API paths, so must not be able to alter them.
+	if !validUsername(username) {
+		return "", logical.CodedError(http.StatusBadRequest, fmt.Sprintf("%s: %q", errInvalidUsername, username))
+	}
+
+	return username, nil
+}
@@ -0,0 +1,106 @@
+package github
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/assert"
+)
+
+func TestUsernameMapping(t *testing.T) {
+	t.Parallel()
+
+	entity := &logical.Entity{
+		ID:       "entity-1",
+		Metadata: map[string]string{"github_username": "octocat"},
+		Aliases: []*logical.Alias{
+			{MountAccessor: "auth_github_1", Name: "hubot"},
+			{MountAccessor: "auth_oidc_1", Name: "alice", Metadata: map[string]string{"gh": "alice-gh"}},
+		},
+	}
+
+	cases := []struct {
+		entity  *logical.Entity
+		mapping *usernameMapping
+		name    string
+		exp     string
+		err     string
+	}{
+		{
+			name:    "DefaultMetadataKey",
+			entity:  entity,
+			mapping: &usernameMapping{},
+			exp:     "octocat",
+		},
+		{
+			name:    "AliasMetadataKey",
+			entity:  entity,
+			mapping: &usernameMapping{UsernameMetadataKey: "gh"},
+			exp:     "alice-gh",
+		},
+		{
+			name:    "AliasName",
+			entity:  entity,
+			mapping: &usernameMapping{UsernameAliasAccessor: "auth_github_1"},
+			exp:     "hubot",
+		},
+		{
+			name:    "NoAlias",
+			entity:  entity,
+			mapping: &usernameMapping{UsernameAliasAccessor: "auth_github_2"},
+			err:     `entity "entity-1" has no alias on mount "auth_github_2"`,
+		},
+		{
+			name:    "NoMetadata",
+			entity:  entity,
+			mapping: &usernameMapping{UsernameMetadataKey: "missing"},
+			err:     `entity "entity-1" has no "missing" metadata`,
+		},
+		{
+			name:    "NoEntity",
+			mapping: &usernameMapping{},
+			err:     "no entity was provided",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			username, err := tc.mapping.username(tc.entity)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, errUnableToMapUsername.Error())
+				assert.ErrorContains(t, err, tc.err)
+
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, username, tc.exp)
+		})
+	}
+}
+
+func TestValidUsername(t *testing.T) {
+	t.Parallel()
+
+	for name, exp := range map[string]bool{
+		"octocat":                   true,
+		"Octo-Cat-1":                true,
+		"a":                         true,
+		strings.Repeat("a", 39):     true,
+		strings.Repeat("a", 40):     false,
+		"":                          false,
+		"-octocat":                  false,
+		"octocat-":                  false,
+		"octo--cat":                 false,
+		"octo_cat":                  false,
+		"octo.cat":                  false,
+		"../../memberships/victim":  false,
+		"octocat/../../memberships": false,
+		"octocat%2F..":              false,
+	} {
+		assert.Equal(t, validUsername(name), exp, name)
+	}
+}